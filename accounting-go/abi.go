@@ -0,0 +1,229 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// ErrActionNotSupported is matched by errors.Is when the contract ABI has no
+// action compatible with the one a wrapper pushes
+var ErrActionNotSupported = errors.New("action not supported by the contract")
+
+// ActionNotSupportedError tells which action the contract ABI lacks and why
+type ActionNotSupportedError struct {
+	Action string
+	Reason string
+}
+
+func (e *ActionNotSupportedError) Error() string {
+	return fmt.Sprintf("action %v not supported by the contract: %v", e.Action, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrActionNotSupported) true
+func (e *ActionNotSupportedError) Is(target error) bool {
+	return target == ErrActionNotSupported
+}
+
+// actionTypes maps every action pushed by Client to the struct it encodes
+var actionTypes = map[string]interface{}{
+	"createroot":   createRoot{},
+	"addledger":    createLedger{},
+	"createacc":    createAccount{},
+	"updateacc":    updateAccount{},
+	"deleteacc":    deleteAccount{},
+	"upserttrx":    upsertTrx{},
+	"crryconvtrx":  upsertTrx{},
+	"deletetrx":    deleteTrx{},
+	"setsetting":   setSetting{},
+	"remsetting":   remSetting{},
+	"addtrustacnt": trustAccount{},
+	"remtrustacnt": trustAccount{},
+	"addcurrency":  addCurrency{},
+	"addcoinid":    addCoinid{},
+	"remcurrency":  remCurrency{},
+	"newevent":     transact{},
+	"bindevent":    bindEvent{},
+	"unbindevent":  bindEvent{},
+	"clearevent":   clearEvent{},
+	"clean":        clean{},
+	"reset":        reset{},
+}
+
+// LoadABI reads an ABI file, i.e. build/accounting/accounting.abi
+func LoadABI(path string) (*eos.ABI, error) {
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("load abi: %v", err)
+	}
+
+	defer f.Close()
+
+	abi, err := eos.NewABI(f)
+
+	if err != nil {
+		return nil, fmt.Errorf("load abi %v: %v", path, err)
+	}
+
+	return abi, nil
+}
+
+// WithABI makes the client check its actions against abi when it is
+// created, the actions that don't match fail with ActionNotSupportedError
+// instead of being pushed
+func WithABI(abi *eos.ABI) ClientOption {
+	return func(c *Client) {
+		c.abi = abi
+	}
+}
+
+// CheckABI verifies every action pushed by Client against abi, it returns
+// an ActionNotSupportedError per missing or mismatching action
+func CheckABI(abi *eos.ABI) []error {
+
+	names := make([]string, 0, len(actionTypes))
+	for name := range actionTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+
+	for _, name := range names {
+		if err := checkAction(abi, name, reflect.TypeOf(actionTypes[name])); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func checkAction(abi *eos.ABI, name string, goType reflect.Type) error {
+
+	action := abi.ActionForName(eos.ActN(name))
+
+	if action == nil {
+		return &ActionNotSupportedError{Action: name, Reason: "missing in the abi"}
+	}
+
+	fields, err := abiFields(abi, action.Type)
+
+	if err != nil {
+		return &ActionNotSupportedError{Action: name, Reason: err.Error()}
+	}
+
+	if len(fields) != goType.NumField() {
+		return &ActionNotSupportedError{
+			Action: name,
+			Reason: fmt.Sprintf("the abi has %v fields, expected %v", len(fields), goType.NumField()),
+		}
+	}
+
+	for i, field := range fields {
+
+		goField := goType.Field(i)
+		goName := strings.Split(goField.Tag.Get("json"), ",")[0]
+
+		if field.Name != goName {
+			return &ActionNotSupportedError{
+				Action: name,
+				Reason: fmt.Sprintf("field %v is %v in the abi, expected %v", i, field.Name, goName),
+			}
+		}
+
+		if !abiTypeMatches(resolveABIType(abi, field.Type), goField.Type) {
+			return &ActionNotSupportedError{
+				Action: name,
+				Reason: fmt.Sprintf("field %v has type %v in the abi, expected %v", field.Name, field.Type, goField.Type),
+			}
+		}
+	}
+
+	return nil
+}
+
+// abiFields returns the fields of structName including the ones of its bases
+func abiFields(abi *eos.ABI, structName string) ([]eos.FieldDef, error) {
+
+	def := abi.StructForName(resolveABIType(abi, structName))
+
+	if def == nil {
+		return nil, fmt.Errorf("struct %v missing in the abi", structName)
+	}
+
+	if def.Base == "" {
+		return def.Fields, nil
+	}
+
+	base, err := abiFields(abi, def.Base)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(base, def.Fields...), nil
+}
+
+// resolveABIType follows the type aliases of abi
+func resolveABIType(abi *eos.ABI, typeName string) string {
+	for i := 0; i < len(abi.Types); i++ {
+		for _, alias := range abi.Types {
+			if alias.NewTypeName == typeName {
+				typeName = alias.Type
+				break
+			}
+		}
+	}
+	return typeName
+}
+
+var (
+	nameTypes = map[reflect.Type]bool{
+		reflect.TypeOf(eos.Name("")):           true,
+		reflect.TypeOf(eos.AccountName("")):    true,
+		reflect.TypeOf(eos.PermissionName("")): true,
+		reflect.TypeOf(eos.ActionName("")):     true,
+	}
+	checksumType      = reflect.TypeOf(eos.Checksum256{})
+	symbolType        = reflect.TypeOf(eos.Symbol{})
+	contentGroupsType = reflect.TypeOf([]docgraph.ContentGroup{})
+	flexValueType     = reflect.TypeOf(docgraph.FlexValue{})
+)
+
+// abiTypeMatches tells if values of goType are serialized as abiType
+func abiTypeMatches(abiType string, goType reflect.Type) bool {
+	switch {
+	case nameTypes[goType]:
+		return abiType == "name"
+	case goType == checksumType:
+		return abiType == "checksum256"
+	case goType == symbolType:
+		return abiType == "symbol"
+	case goType == contentGroupsType:
+		return strings.HasSuffix(abiType, "[]")
+	case goType == flexValueType:
+		// the variant is named by the abi generator
+		return !strings.HasSuffix(abiType, "[]")
+	}
+
+	switch goType.Kind() {
+	case reflect.Bool:
+		return abiType == "bool"
+	case reflect.Int64:
+		return abiType == "int64"
+	case reflect.Uint64:
+		return abiType == "uint64"
+	case reflect.String:
+		return abiType == "string"
+	}
+
+	return false
+}
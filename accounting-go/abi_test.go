@@ -0,0 +1,66 @@
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+const partial_abi = `{
+	"version": "eosio::abi/1.1",
+	"types": [
+		{ "new_type_name": "ContentGroup", "type": "content[]" }
+	],
+	"structs": [
+		{ "name": "addledger", "base": "", "fields": [
+			{ "name": "creator", "type": "name" },
+			{ "name": "ledger_info", "type": "ContentGroup[]" }
+		]},
+		{ "name": "deleteacc", "base": "", "fields": [
+			{ "name": "deleter", "type": "name" },
+			{ "name": "account_hash", "type": "checksum256" }
+		]},
+		{ "name": "deletetrx", "base": "", "fields": [
+			{ "name": "deleter", "type": "name" },
+			{ "name": "trx_hash", "type": "uint64" }
+		]}
+	],
+	"actions": [
+		{ "name": "addledger", "type": "addledger", "ricardian_contract": "" },
+		{ "name": "deleteacc", "type": "deleteacc", "ricardian_contract": "" },
+		{ "name": "deletetrx", "type": "deletetrx", "ricardian_contract": "" }
+	]
+}`
+
+func TestCheckABI(t *testing.T) {
+
+	abi, err := eos.NewABI(strings.NewReader(partial_abi))
+	assert.NilError(t, err)
+
+	unsupported := make(map[string]string)
+	for _, err := range accounting.CheckABI(abi) {
+		var notSupported *accounting.ActionNotSupportedError
+		assert.Assert(t, errors.As(err, &notSupported))
+		unsupported[notSupported.Action] = notSupported.Reason
+	}
+
+	_, ok := unsupported["addledger"]
+	assert.Assert(t, !ok)
+	_, ok = unsupported["deleteacc"]
+	assert.Assert(t, !ok)
+	assert.Equal(t, unsupported["deletetrx"], "field trx_hash has type uint64 in the abi, expected eos.Checksum256")
+	assert.Equal(t, unsupported["upserttrx"], "missing in the abi")
+
+	client, err := accounting.NewClient(eos.New("http://localhost:8888"), eos.AN("accounting"), accounting.WithABI(abi))
+	assert.NilError(t, err)
+
+	assert.NilError(t, client.Supports("addledger"))
+
+	_, err = client.Deletetrx(context.Background(), eos.Checksum256(make([]byte, 32)))
+	assert.Assert(t, errors.Is(err, accounting.ErrActionNotSupported))
+}
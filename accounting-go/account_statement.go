@@ -0,0 +1,303 @@
+package accounting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexeyco/simpletable"
+	eos "github.com/eoscanada/eos-go"
+)
+
+// AccountStatementEntry is an approved component of the account joined with
+// its transaction
+type AccountStatementEntry struct {
+	Component Component       `json:"component"`
+	TrxHash   eos.Checksum256 `json:"trx_hash"`
+	TrxID     int64           `json:"trx_id"`
+	TrxDate   eos.TimePoint   `json:"trx_date"`
+	TrxName   string          `json:"trx_name,omitempty"`
+	TrxMemo   string          `json:"trx_memo"`
+	// Balance is the running balance of the currency of the component after
+	// the entry, DEBIT components add and CREDIT components subtract
+	Balance eos.Asset `json:"balance"`
+}
+
+// AccountStatement lists the approved components of an account sorted by
+// transaction date
+type AccountStatement struct {
+	Account Account `json:"account"`
+	// From and To bound the transaction dates of the entries, From is
+	// inclusive and To exclusive, zero values leave the range open
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Opening holds the balances before From and Closing the balances after
+	// the last entry, keyed by currency
+	Opening map[string]eos.Asset    `json:"opening"`
+	Entries []AccountStatementEntry `json:"entries"`
+	Closing map[string]eos.Asset    `json:"closing"`
+}
+
+// GetAccountStatement reads the statement of account for the transactions
+// dated in [from, to)
+func (c *Client) GetAccountStatement(ctx context.Context, account eos.Checksum256, from, to time.Time) (*AccountStatement, error) {
+	return ReadAccountStatement(ctx, c, account, from, to)
+}
+
+// ReadAccountStatement reads the statement of account from g, only the
+// approved components are linked to the account by acctcmp edges
+func ReadAccountStatement(ctx context.Context, g Graph, account eos.Checksum256, from, to time.Time) (*AccountStatement, error) {
+
+	decoded, err := LoadAccount(ctx, g, account)
+
+	if err != nil {
+		return nil, fmt.Errorf("account statement: %v", err)
+	}
+
+	entries, err := approvedEntries(ctx, g, account, make(map[string]Transaction))
+
+	if err != nil {
+		return nil, fmt.Errorf("account statement: %v", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].TrxDate != entries[j].TrxDate {
+			return entries[i].TrxDate < entries[j].TrxDate
+		}
+		if entries[i].TrxID != entries[j].TrxID {
+			return entries[i].TrxID < entries[j].TrxID
+		}
+		return entries[i].Component.Hash.String() < entries[j].Component.Hash.String()
+	})
+
+	statement := &AccountStatement{
+		Account: decoded,
+		From:    from,
+		To:      to,
+		Opening: make(map[string]eos.Asset),
+		Entries: []AccountStatementEntry{},
+	}
+
+	balances := make(assetSums)
+
+	for _, entry := range entries {
+
+		date := TimeOf(entry.TrxDate)
+
+		if !to.IsZero() && !date.Before(to) {
+			break
+		}
+
+		balances.add(signedAmount(entry.Component))
+
+		if !from.IsZero() && date.Before(from) {
+			statement.Opening[entry.Component.Amount.Symbol.Symbol] = balances[entry.Component.Amount.Symbol.Symbol]
+			continue
+		}
+
+		entry.Balance = balances[entry.Component.Amount.Symbol.Symbol]
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	statement.Closing = balances
+
+	return statement, nil
+}
+
+// approvedEntries returns the approved components of account joined with
+// their transactions, transactions caches the transactions by hash
+func approvedEntries(ctx context.Context, g Graph, account eos.Checksum256, transactions map[string]Transaction) ([]AccountStatementEntry, error) {
+
+	edges, err := g.EdgesFrom(ctx, account, accountComponentEdge)
+
+	if err != nil {
+		return nil, fmt.Errorf("components of %v: %v", account, err)
+	}
+
+	entries := make([]AccountStatementEntry, 0, len(edges))
+
+	for _, edge := range edges {
+
+		document, err := g.Document(ctx, edge.ToNode)
+
+		if err != nil {
+			return nil, fmt.Errorf("load component %v: %v", edge.ToNode, err)
+		}
+
+		component, err := ComponentFromDocument(document)
+
+		if err != nil {
+			return nil, err
+		}
+
+		trxHash, err := singleEdgeFrom(ctx, g, component.Hash, transactionEdge)
+
+		if err != nil {
+			return nil, err
+		}
+
+		trx, ok := transactions[trxHash.String()]
+
+		if !ok {
+
+			trxDocument, err := g.Document(ctx, trxHash)
+
+			if err != nil {
+				return nil, fmt.Errorf("load transaction %v: %v", trxHash, err)
+			}
+
+			trx, err = TransactionFromDocument(trxDocument)
+
+			if err != nil {
+				return nil, err
+			}
+
+			transactions[trxHash.String()] = trx
+		}
+
+		entries = append(entries, AccountStatementEntry{
+			Component: component,
+			TrxHash:   trx.Hash,
+			TrxID:     trx.ID,
+			TrxDate:   trx.Date,
+			TrxName:   trx.Name,
+			TrxMemo:   trx.Memo,
+		})
+	}
+
+	return entries, nil
+}
+
+// WriteText writes the statement as a table
+func (s *AccountStatement) WriteText(w io.Writer) error {
+
+	table := simpletable.New()
+
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Date"},
+			{Align: simpletable.AlignCenter, Text: "Trx"},
+			{Align: simpletable.AlignCenter, Text: "Memo"},
+			{Align: simpletable.AlignCenter, Text: "Debit"},
+			{Align: simpletable.AlignCenter, Text: "Credit"},
+			{Align: simpletable.AlignCenter, Text: "Balance"},
+		},
+	}
+
+	for _, currency := range sortedCurrencies(s.Opening) {
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: s.From.Format("2006-01-02")},
+			{},
+			{Align: simpletable.AlignLeft, Text: "Opening balance"},
+			{},
+			{},
+			{Align: simpletable.AlignRight, Text: s.Opening[currency].String()},
+		})
+	}
+
+	for _, entry := range s.Entries {
+
+		debit, credit := entry.Component.Amount.String(), ""
+		if entry.Component.Type == Credit {
+			debit, credit = "", debit
+		}
+
+		memo := entry.TrxMemo
+		if entry.Component.Memo != "" && entry.Component.Memo != memo {
+			memo += " / " + entry.Component.Memo
+		}
+
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: TimeOf(entry.TrxDate).Format("2006-01-02")},
+			{Align: simpletable.AlignRight, Text: strconv.FormatInt(entry.TrxID, 10)},
+			{Align: simpletable.AlignLeft, Text: memo},
+			{Align: simpletable.AlignRight, Text: debit},
+			{Align: simpletable.AlignRight, Text: credit},
+			{Align: simpletable.AlignRight, Text: entry.Balance.String()},
+		})
+	}
+
+	closing := make([]string, 0, len(s.Closing))
+	for _, currency := range sortedCurrencies(s.Closing) {
+		closing = append(closing, s.Closing[currency].String())
+	}
+
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{},
+			{},
+			{Align: simpletable.AlignRight, Text: "Closing balance"},
+			{},
+			{},
+			{Align: simpletable.AlignRight, Text: strings.Join(closing, ", ")},
+		},
+	}
+
+	if _, err := fmt.Fprintf(w, "%v %v\n%v\n", s.Account.Code, s.Account.Name, table.String()); err != nil {
+		return fmt.Errorf("write account statement: %v", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes a row per entry
+func (s *AccountStatement) WriteCSV(w io.Writer) error {
+
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{{"trx_date", "trx_id", "trx_name", "trx_memo", "memo", "type", "amount", "balance", "trx_hash", "component_hash"}}
+
+	for _, entry := range s.Entries {
+		rows = append(rows, []string{
+			TimeOf(entry.TrxDate).Format(time.RFC3339),
+			strconv.FormatInt(entry.TrxID, 10),
+			entry.TrxName,
+			entry.TrxMemo,
+			entry.Component.Memo,
+			entry.Component.Type,
+			entry.Component.Amount.String(),
+			entry.Balance.String(),
+			entry.TrxHash.String(),
+			entry.Component.Hash.String(),
+		})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("write account statement: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the statement as indented JSON
+func (s *AccountStatement) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("write account statement: %v", err)
+	}
+
+	return nil
+}
+
+// sortedCurrencies returns the keys of balances sorted
+func sortedCurrencies(balances map[string]eos.Asset) []string {
+
+	currencies := make([]string, 0, len(balances))
+
+	for currency := range balances {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Strings(currencies)
+
+	return currencies
+}
@@ -0,0 +1,111 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func (g *memoryGraph) addTransaction(t *testing.T, ledger eos.Checksum256, id int64, date, memo string) eos.Checksum256 {
+	return g.add(t, fmt.Sprintf("trx/%v", id), fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "id", "value": [ "int64", %v ] },
+		{ "label": "trx_date", "value": [ "time_point", "%v" ] },
+		{ "label": "trx_ledger", "value": [ "checksum256", "%v" ] },
+		{ "label": "trx_memo", "value": [ "string", "%v" ] },
+		{ "label": "trx_name", "value": [ "string", "Trx %v" ] }
+	]]
+}`, id, date, ledger, memo, id))
+}
+
+func (g *memoryGraph) addComponent(t *testing.T, trx, account eos.Checksum256, key, amount, componentType string, approved bool) eos.Checksum256 {
+
+	component := g.add(t, "component/"+key, fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "account", "value": [ "checksum256", "%v" ] },
+		{ "label": "amount", "value": [ "asset", "%v" ] },
+		{ "label": "memo", "value": [ "string", "%v" ] },
+		{ "label": "type", "value": [ "string", "%v" ] }
+	]]
+}`, account, amount, key, componentType))
+
+	g.link(trx, component, "component")
+	g.link(component, trx, "transaction")
+	g.link(component, account, "cmpacct")
+
+	if approved {
+		g.link(account, component, "acctcmp")
+	}
+
+	return component
+}
+
+func TestAccountStatement(t *testing.T) {
+
+	g := newMemoryGraph()
+	ledger := g.addLedger(t, "Main")
+	cash := g.addAccount(t, ledger, "1100", "Cash", accounting.Debit, true)
+	sales := g.addAccount(t, ledger, "4100", "Sales", accounting.Credit, true)
+
+	march := g.addTransaction(t, ledger, 3, "2021-03-01T00:00:00.000", "March sales")
+	january := g.addTransaction(t, ledger, 1, "2021-01-10T00:00:00.000", "January sales")
+	february := g.addTransaction(t, ledger, 2, "2021-02-01T00:00:00.000", "Refund")
+	draft := g.addTransaction(t, ledger, 4, "2021-02-15T00:00:00.000", "Draft")
+
+	g.addComponent(t, march, cash, "c3", "300.00 USD", accounting.Debit, true)
+	g.addComponent(t, march, sales, "s3", "300.00 USD", accounting.Credit, true)
+	g.addComponent(t, january, cash, "c1", "1000.00 USD", accounting.Debit, true)
+	g.addComponent(t, january, cash, "c1b", "2.0000 TLOS", accounting.Debit, true)
+	g.addComponent(t, february, cash, "c2", "100.00 USD", accounting.Credit, true)
+	g.addComponent(t, draft, cash, "c4", "50.00 USD", accounting.Debit, false)
+
+	ctx := context.Background()
+
+	t.Run("All", func(t *testing.T) {
+
+		statement, err := accounting.ReadAccountStatement(ctx, g, cash, time.Time{}, time.Time{})
+		assert.NilError(t, err)
+
+		assert.Equal(t, statement.Account.Name, "Cash")
+		assert.Equal(t, len(statement.Entries), 4)
+		assert.Equal(t, statement.Entries[0].TrxMemo, "January sales")
+		assert.Equal(t, statement.Entries[2].TrxID, int64(2))
+		assert.Equal(t, statement.Entries[2].Balance.String(), "900.00 USD")
+		assert.Equal(t, statement.Entries[3].Balance.String(), "1200.00 USD")
+		assert.Equal(t, statement.Closing["TLOS"].String(), "2.0000 TLOS")
+		assert.Equal(t, len(statement.Opening), 0)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+
+		from := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		statement, err := accounting.ReadAccountStatement(ctx, g, cash, from, to)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(statement.Entries), 1)
+		assert.Equal(t, statement.Entries[0].Component.Memo, "c2")
+		assert.Equal(t, statement.Opening["USD"].String(), "1000.00 USD")
+		assert.Equal(t, statement.Closing["USD"].String(), "900.00 USD")
+
+		var out bytes.Buffer
+		assert.NilError(t, statement.WriteText(&out))
+		assert.Assert(t, strings.Contains(out.String(), "Opening balance"))
+
+		out.Reset()
+		assert.NilError(t, statement.WriteCSV(&out))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, len(lines), 2)
+		assert.Assert(t, strings.HasPrefix(lines[1], "2021-02-01T00:00:00Z,2,Trx 2,Refund,c2,CREDIT,100.00 USD,900.00 USD,"))
+	})
+}
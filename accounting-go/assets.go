@@ -0,0 +1,56 @@
+package accounting
+
+import (
+	eos "github.com/eoscanada/eos-go"
+)
+
+func integerPow(x, p int64) int64 {
+	result := int64(1)
+	for ; p > 0; p-- {
+		result *= x
+	}
+	return result
+}
+
+// addAssetsAdjustingPrecision adds two assets with the same symbol code and
+// possibly different precision, the result has the highest precision.
+// It mirrors util::addAssetsAdjustingPrecision in include/math_utils.hpp
+func addAssetsAdjustingPrecision(a, b eos.Asset) eos.Asset {
+
+	if a.Symbol.Precision < b.Symbol.Precision {
+		a, b = b, a
+	}
+
+	scaled := int64(b.Amount) * integerPow(10, int64(a.Symbol.Precision-b.Symbol.Precision))
+
+	return eos.Asset{
+		Amount: a.Amount + eos.Int64(scaled),
+		Symbol: a.Symbol,
+	}
+}
+
+// negateAsset returns -a
+func negateAsset(a eos.Asset) eos.Asset {
+	return eos.Asset{Amount: -a.Amount, Symbol: a.Symbol}
+}
+
+// signedAmount returns the amount of a component as it affects the balances,
+// DEBIT components add and CREDIT components subtract
+func signedAmount(component Component) eos.Asset {
+	if component.Type == Credit {
+		return negateAsset(component.Amount)
+	}
+	return component.Amount
+}
+
+// assetSums accumulates assets by symbol code
+type assetSums map[string]eos.Asset
+
+func (s assetSums) add(a eos.Asset) {
+	code := a.Symbol.Symbol
+	if current, ok := s[code]; ok {
+		s[code] = addAssetsAdjustingPrecision(current, a)
+	} else {
+		s[code] = a
+	}
+}
@@ -0,0 +1,233 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	eos "github.com/eoscanada/eos-go"
+)
+
+// BalanceMismatch is a value of a balances document that differs from the
+// one recomputed from the approved components. Stored or Expected are
+// empty when the value is missing on that side.
+type BalanceMismatch struct {
+	Account eos.Checksum256 `json:"account"`
+	Code    string          `json:"code"`
+	Name    string          `json:"name"`
+	// Label is account_<SYM>, global_<SYM> or number_of_updates
+	Label    string `json:"label"`
+	Stored   string `json:"stored"`
+	Expected string `json:"expected"`
+}
+
+func (m BalanceMismatch) String() string {
+	stored, expected := m.Stored, m.Expected
+	if stored == "" {
+		stored = "missing"
+	}
+	if expected == "" {
+		expected = "missing"
+	}
+	return fmt.Sprintf("%v %v: %v is %v, expected %v", m.Code, m.Name, m.Label, stored, expected)
+}
+
+// BalanceAudit compares the balances documents of a ledger with the
+// balances recomputed from its approved components
+type BalanceAudit struct {
+	Ledger     eos.Checksum256   `json:"ledger"`
+	Accounts   int               `json:"accounts"`
+	Components int               `json:"components"`
+	Mismatches []BalanceMismatch `json:"mismatches"`
+}
+
+// OK returns true when every stored balance matches
+func (a *BalanceAudit) OK() bool {
+	return len(a.Mismatches) == 0
+}
+
+// AuditBalances recomputes the balances of the accounts of ledger
+func (c *Client) AuditBalances(ctx context.Context, ledger eos.Checksum256) (*BalanceAudit, error) {
+	return ReadBalanceAudit(ctx, c, ledger)
+}
+
+// ReadBalanceAudit recomputes the balances of the accounts of ledger from g
+// like changeAcctBalanceRecursively does: every approved component adds its
+// signed amount to the account_<SYM> balance of its account and to the
+// global_<SYM> balances of the account and of the accounts above it
+// following the ownedby edges. Only the components of leaf accounts are
+// rolled up, CheckGraph reports the accounts with children and components.
+func ReadBalanceAudit(ctx context.Context, g Graph, ledger eos.Checksum256) (*BalanceAudit, error) {
+
+	tree, err := ReadLedgerTree(ctx, g, ledger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	audit := &BalanceAudit{Ledger: ledger}
+
+	expected, err := rollUpBalances(ctx, g, ledger, func(entry AccountStatementEntry) bool {
+		audit.Components++
+		return true
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("balance audit: %v", err)
+	}
+
+	tree.Walk(func(node *LedgerNode) bool {
+		audit.Accounts++
+		audit.Mismatches = append(audit.Mismatches, compareBalances(node, expected[node.Account.Hash.String()])...)
+		return true
+	})
+
+	return audit, nil
+}
+
+// compareBalances returns the differences between the stored balances of
+// node and the expected ones, expected is nil when no component reached
+// the account
+func compareBalances(node *LedgerNode, expected *Balances) []BalanceMismatch {
+
+	// every component reaching the account updates its balances document
+	updated := expected != nil
+
+	if expected == nil {
+		expected = &Balances{}
+	}
+
+	stored := node.Balances
+
+	if stored == nil {
+		stored = &Balances{}
+	}
+
+	var mismatches []BalanceMismatch
+
+	mismatch := func(label, storedValue, expectedValue string) {
+		mismatches = append(mismatches, BalanceMismatch{
+			Account:  node.Account.Hash,
+			Code:     node.Account.Code,
+			Name:     node.Account.Name,
+			Label:    label,
+			Stored:   storedValue,
+			Expected: expectedValue,
+		})
+	}
+
+	compare := func(prefix string, stored, expected map[string]eos.Asset) {
+		for _, code := range unionAssetKeys(stored, expected) {
+			s, hasStored := stored[code]
+			e, hasExpected := expected[code]
+			switch {
+			case !hasStored:
+				mismatch(prefix+code, "", e.String())
+			case !hasExpected:
+				mismatch(prefix+code, s.String(), "")
+			case s.Amount != e.Amount || s.Symbol != e.Symbol:
+				mismatch(prefix+code, s.String(), e.String())
+			}
+		}
+	}
+
+	compare(accountBalancePrefix, stored.Account, expected.Account)
+	compare(globalBalancePrefix, stored.Global, expected.Global)
+
+	// The contract reads the number of updates from the system group, which
+	// is never updated, so every updated balances document stores 1
+	expectedUpdates := int64(0)
+	if updated {
+		expectedUpdates = 1
+	}
+
+	if stored.NumberOfUpdates != expectedUpdates {
+		mismatch(numberOfUpdatesLabel, fmt.Sprint(stored.NumberOfUpdates), fmt.Sprint(expectedUpdates))
+	}
+
+	return mismatches
+}
+
+// unionAssetKeys returns the symbol codes of both maps sorted
+func unionAssetKeys(a, b map[string]eos.Asset) []string {
+
+	keys := make(map[string]bool)
+
+	for code := range a {
+		keys[code] = true
+	}
+
+	for code := range b {
+		keys[code] = true
+	}
+
+	codes := make([]string, 0, len(keys))
+
+	for code := range keys {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	return codes
+}
+
+// WriteText writes the mismatches as a table
+func (a *BalanceAudit) WriteText(w io.Writer) error {
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "Ledger %v: %v accounts, %v approved components, %v mismatches\n",
+		a.Ledger, a.Accounts, a.Components, len(a.Mismatches))
+
+	if len(a.Mismatches) > 0 {
+
+		table := simpletable.New()
+
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Code"},
+				{Align: simpletable.AlignCenter, Text: "Account"},
+				{Align: simpletable.AlignCenter, Text: "Label"},
+				{Align: simpletable.AlignCenter, Text: "Stored"},
+				{Align: simpletable.AlignCenter, Text: "Expected"},
+			},
+		}
+
+		for _, m := range a.Mismatches {
+			table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+				{Align: simpletable.AlignLeft, Text: m.Code},
+				{Align: simpletable.AlignLeft, Text: m.Name},
+				{Align: simpletable.AlignLeft, Text: m.Label},
+				{Align: simpletable.AlignRight, Text: m.Stored},
+				{Align: simpletable.AlignRight, Text: m.Expected},
+			})
+		}
+
+		out.WriteString(table.String())
+		out.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write balance audit: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the audit as indented JSON
+func (a *BalanceAudit) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(a); err != nil {
+		return fmt.Errorf("write balance audit: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+// corruptBalances returns a snapshot of chain where update changed the
+// balances document of account
+func corruptBalances(t *testing.T, chain *accounting.FakeChain, account eos.Checksum256, update func(b *accounting.Balances)) *accounting.Snapshot {
+
+	ctx := context.Background()
+
+	edges, err := chain.EdgesFrom(ctx, account, "balances")
+	assert.NilError(t, err)
+	assert.Equal(t, len(edges), 1)
+
+	var documents []docgraph.Document
+
+	for _, document := range chain.Snapshot().Documents() {
+		if document.Hash.String() == edges[0].ToNode.String() {
+			balances, err := accounting.BalancesFromDocument(document)
+			assert.NilError(t, err)
+			update(&balances)
+			document.ContentGroups = balances.ContentGroups()
+		}
+		documents = append(documents, document)
+	}
+
+	return accounting.NewSnapshot(documents, chain.Snapshot().Edges())
+}
+
+func TestReadBalanceAudit(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := newFakeLedger(t)
+
+	assert.NilError(t, l.upsert(t, nil, l.sale(t, "April sales", "50.00 USD", "50.00 USD"), true))
+	assert.NilError(t, l.upsert(t, nil, l.sale(t, "May sales", "25.00 USD", "25.00 USD"), true))
+	assert.NilError(t, l.upsert(t, nil, l.sale(t, "June sales", "10.00 USD", "10.00 USD"), false))
+
+	t.Run("matches the balances of the contract", func(t *testing.T) {
+
+		audit, err := accounting.ReadBalanceAudit(ctx, l.chain, l.ledger)
+		assert.NilError(t, err)
+		assert.Assert(t, audit.OK(), audit.Mismatches)
+		assert.Equal(t, audit.Accounts, 4)
+		assert.Equal(t, audit.Components, 4)
+	})
+
+	t.Run("reports drifted balances", func(t *testing.T) {
+
+		snapshot := corruptBalances(t, l.chain, l.cash, func(b *accounting.Balances) {
+			b.Account["USD"], _ = eos.NewAssetFromString("70.00 USD")
+			b.Global["TLOS"], _ = eos.NewAssetFromString("1.0000 TLOS")
+			b.NumberOfUpdates = 0
+		})
+
+		audit, err := accounting.ReadBalanceAudit(ctx, snapshot, l.ledger)
+		assert.NilError(t, err)
+		assert.Assert(t, !audit.OK())
+
+		var mismatches []string
+		for _, m := range audit.Mismatches {
+			mismatches = append(mismatches, m.String())
+		}
+
+		assert.DeepEqual(t, mismatches, []string{
+			"1100 Cash: account_USD is 70.00 USD, expected 75.00 USD",
+			"1100 Cash: global_TLOS is 1.0000 TLOS, expected missing",
+			"1100 Cash: number_of_updates is 0, expected 1",
+		})
+
+		var text bytes.Buffer
+		assert.NilError(t, audit.WriteText(&text))
+		assert.Assert(t, bytes.Contains(text.Bytes(), []byte("3 mismatches")))
+	})
+
+	t.Run("reports missing balances", func(t *testing.T) {
+
+		snapshot := corruptBalances(t, l.chain, l.revenue, func(b *accounting.Balances) {
+			delete(b.Global, "USD")
+		})
+
+		audit, err := accounting.ReadBalanceAudit(ctx, snapshot, l.ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, len(audit.Mismatches), 1)
+		assert.Equal(t, audit.Mismatches[0].String(), "4000 Revenue: global_USD is missing, expected -75.00 USD")
+	})
+}
@@ -0,0 +1,141 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+)
+
+// ReadBalancesAsOf computes the balances of the accounts of ledger from
+// the approved components whose transaction is dated on or before date.
+// The account balances of each leaf are rolled up into the global balances
+// of the leaf and its ancestors, found through the ownedby edges. The
+// result is keyed by account hash, accounts without components are missing.
+func ReadBalancesAsOf(ctx context.Context, g Graph, ledger eos.Checksum256, date time.Time) (map[string]*Balances, error) {
+
+	asOf := TimePointOf(date)
+
+	balances, err := rollUpBalances(ctx, g, ledger, func(entry AccountStatementEntry) bool {
+		return entry.TrxDate <= asOf
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("balances as of %v: %v", date.Format(time.RFC3339), err)
+	}
+
+	return balances, nil
+}
+
+// rollUpBalances computes the balances of the accounts of ledger from the
+// approved components of its leaves for which include returns true
+func rollUpBalances(ctx context.Context, g Graph, ledger eos.Checksum256, include func(entry AccountStatementEntry) bool) (map[string]*Balances, error) {
+
+	transactions := make(map[string]Transaction)
+	// parents caches the ownedby edges of the visited ancestors
+	parents := make(map[string]eos.Checksum256)
+	balances := make(map[string]*Balances)
+
+	balancesOf := func(account string) *Balances {
+		if _, ok := balances[account]; !ok {
+			balances[account] = &Balances{
+				Account: make(map[string]eos.Asset),
+				Global:  make(map[string]eos.Asset),
+			}
+		}
+		return balances[account]
+	}
+
+	err := WalkAccounts(ctx, g, ledger, func(account Account, _ []string) error {
+
+		if !account.IsLeaf {
+			return nil
+		}
+
+		entries, err := approvedEntries(ctx, g, account.Hash, transactions)
+
+		if err != nil {
+			return err
+		}
+
+		sums := make(assetSums)
+
+		for _, entry := range entries {
+			if include(entry) {
+				sums.add(signedAmount(entry.Component))
+			}
+		}
+
+		if len(sums) == 0 {
+			return nil
+		}
+
+		for _, amount := range sums {
+			assetSums(balancesOf(account.Hash.String()).Account).add(amount)
+		}
+
+		ancestor := account.Hash
+
+		for ancestor.String() != ledger.String() {
+
+			for _, amount := range sums {
+				assetSums(balancesOf(ancestor.String()).Global).add(amount)
+			}
+
+			parent, ok := parents[ancestor.String()]
+
+			if !ok {
+				if parent, err = singleEdgeFrom(ctx, g, ancestor, ownedByEdge); err != nil {
+					return err
+				}
+				parents[ancestor.String()] = parent
+			}
+
+			ancestor = parent
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// ReadLedgerTreeAsOf reads the accounts of ledger with their balances as
+// of date, the tree can be passed to the reports of this package to get
+// them at the end of a past period
+func ReadLedgerTreeAsOf(ctx context.Context, g Graph, ledger eos.Checksum256, date time.Time) (*LedgerTree, error) {
+
+	tree, err := ReadLedgerTree(ctx, g, ledger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := ReadBalancesAsOf(ctx, g, ledger, date)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tree.Walk(func(node *LedgerNode) bool {
+		node.Balances = balances[node.Account.Hash.String()]
+		return true
+	})
+
+	return tree, nil
+}
+
+// GetBalancesAsOf computes the balances of the accounts of ledger as of date
+func (c *Client) GetBalancesAsOf(ctx context.Context, ledger eos.Checksum256, date time.Time) (map[string]*Balances, error) {
+	return ReadBalancesAsOf(ctx, c, ledger, date)
+}
+
+// GetLedgerTreeAsOf reads the accounts of ledger with their balances as of date
+func (c *Client) GetLedgerTreeAsOf(ctx context.Context, ledger eos.Checksum256, date time.Time) (*LedgerTree, error) {
+	return ReadLedgerTreeAsOf(ctx, c, ledger, date)
+}
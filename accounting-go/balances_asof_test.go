@@ -0,0 +1,48 @@
+package accounting_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func TestBalancesAsOf(t *testing.T) {
+
+	g := newMemoryGraph()
+	ledger := g.addLedger(t, "Main")
+	assets := g.addAccount(t, ledger, "1000", "Assets", accounting.Debit, false)
+	cash := g.addAccount(t, assets, "1100", "Cash", accounting.Debit, true)
+	bank := g.addAccount(t, assets, "1200", "Bank", accounting.Debit, true)
+	sales := g.addAccount(t, ledger, "4000", "Sales", accounting.Credit, true)
+
+	january := g.addTransaction(t, ledger, 1, "2021-01-31T23:59:59.000", "January")
+	february := g.addTransaction(t, ledger, 2, "2021-02-10T00:00:00.000", "February")
+
+	g.addComponent(t, january, cash, "c1", "1000.00 USD", accounting.Debit, true)
+	g.addComponent(t, january, sales, "s1", "1000.00 USD", accounting.Credit, true)
+	g.addComponent(t, february, bank, "b2", "400.00 USD", accounting.Debit, true)
+	g.addComponent(t, february, cash, "c2", "400.00 USD", accounting.Credit, true)
+
+	ctx := context.Background()
+	endOfJanuary := time.Date(2021, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	balances, err := accounting.ReadBalancesAsOf(ctx, g, ledger, endOfJanuary)
+	assert.NilError(t, err)
+
+	assert.Equal(t, balances[cash.String()].Account["USD"].String(), "1000.00 USD")
+	assert.Equal(t, balances[assets.String()].Global["USD"].String(), "1000.00 USD")
+	assert.Equal(t, len(balances[assets.String()].Account), 0)
+	assert.Equal(t, balances[sales.String()].Global["USD"].String(), "-1000.00 USD")
+	assert.Assert(t, balances[bank.String()] == nil)
+
+	tree, err := accounting.ReadLedgerTreeAsOf(ctx, g, ledger, endOfJanuary.AddDate(0, 1, 0))
+	assert.NilError(t, err)
+
+	assert.Equal(t, tree.Find("Cash").Balances.Account["USD"].String(), "600.00 USD")
+	assert.Equal(t, tree.Find("Bank").Balances.Account["USD"].String(), "400.00 USD")
+	assert.Equal(t, tree.Find("Assets").Balances.Global["USD"].String(), "1000.00 USD")
+	assert.Assert(t, accounting.NewTrialBalance(tree).Balanced())
+}
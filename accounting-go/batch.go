@@ -0,0 +1,302 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// Default bounds of the transactions pushed by a Batch
+const (
+	DefaultBatchMaxActions = 50
+	DefaultBatchMaxBytes   = 128 * 1024
+)
+
+// BatchOption configures a Batch
+type BatchOption func(*Batch)
+
+// WithMaxActions bounds the number of actions per transaction
+func WithMaxActions(maxActions int) BatchOption {
+	return func(b *Batch) {
+		b.maxActions = maxActions
+	}
+}
+
+// WithMaxBytes bounds the serialized size of the actions per transaction,
+// an action bigger than maxBytes is pushed alone
+func WithMaxBytes(maxBytes int) BatchOption {
+	return func(b *Batch) {
+		b.maxBytes = maxBytes
+	}
+}
+
+// WithCPULimit sets the max_cpu_usage_ms of the transactions, overriding
+// the push options of the client
+func WithCPULimit(ms uint8) BatchOption {
+	return func(b *Batch) {
+		b.pushOpts.MaxCPUUsageMS = ms
+	}
+}
+
+// WithNETLimit sets the max_net_usage_words of the transactions, overriding
+// the push options of the client
+func WithNETLimit(words uint32) BatchOption {
+	return func(b *Batch) {
+		b.pushOpts.MaxNetUsageWords = words
+	}
+}
+
+// Batch accumulates actions and pushes them in as few transactions as the
+// bounds allow. The methods adding actions return the index used to find
+// the action in the results of Flush.
+//
+// The actions of a transaction are atomic, if one of them fails the whole
+// transaction is reverted and every action in it reports the error.
+type Batch struct {
+	client     *Client
+	maxActions int
+	maxBytes   int
+	pushOpts   PushOptions
+
+	pending []batchAction
+	next    int
+}
+
+type batchAction struct {
+	index  int
+	action *eos.Action
+	size   int
+}
+
+// BatchResult is the outcome of one of the actions of a Batch. Tx and Err
+// are those of the transaction that carried the action, so the results of
+// the actions pushed together share the same *TxResult.
+type BatchResult struct {
+	Index  int
+	Action eos.ActionName
+	Tx     *TxResult
+	Err    error
+}
+
+// NewBatch creates an empty batch that pushes its actions through c
+func (c *Client) NewBatch(opts ...BatchOption) *Batch {
+
+	b := &Batch{
+		client:     c,
+		maxActions: DefaultBatchMaxActions,
+		maxBytes:   DefaultBatchMaxBytes,
+		pushOpts:   c.pushOpts,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Len returns the number of actions waiting to be pushed
+func (b *Batch) Len() int {
+	return len(b.pending)
+}
+
+// Add queues any action, it is used by the typed methods below. The action
+// is serialized to bound the size of the transactions, an action that can
+// not be serialized is not queued.
+func (b *Batch) Add(action *eos.Action) (int, error) {
+
+	data, err := eos.MarshalBinary(action)
+
+	if err != nil {
+		return -1, fmt.Errorf("batch %v: %v", action.Name, err)
+	}
+
+	size := len(data)
+
+	index := b.next
+	b.next++
+
+	b.pending = append(b.pending, batchAction{
+		index:  index,
+		action: action,
+		size:   size,
+	})
+
+	return index, nil
+}
+
+// AddLedger queues an addledger action
+func (b *Batch) AddLedger(ledger []docgraph.ContentGroup) (int, error) {
+	return b.Add(b.client.addLedgerAction(ledger))
+}
+
+// CreateAcct queues a createacc action
+func (b *Batch) CreateAcct(account []docgraph.ContentGroup) (int, error) {
+	return b.Add(b.client.createAcctAction(account))
+}
+
+// Updateacc queues an updateacc action
+func (b *Batch) Updateacc(accountHash eos.Checksum256, accountInfo []docgraph.ContentGroup) (int, error) {
+	return b.Add(b.client.updateaccAction(accountHash, accountInfo))
+}
+
+// Deleteacc queues a deleteacc action
+func (b *Batch) Deleteacc(accountHash eos.Checksum256) (int, error) {
+	return b.Add(b.client.deleteaccAction(accountHash))
+}
+
+// Upserttrx queues an upserttrx action
+func (b *Batch) Upserttrx(trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (int, error) {
+	return b.Add(b.client.upsertAction("upserttrx", trxHash, trxInfo, approve))
+}
+
+// Crryconvtrx queues a crryconvtrx action
+func (b *Batch) Crryconvtrx(trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (int, error) {
+	return b.Add(b.client.upsertAction("crryconvtrx", trxHash, trxInfo, approve))
+}
+
+// Deletetrx queues a deletetrx action
+func (b *Batch) Deletetrx(trxHash eos.Checksum256) (int, error) {
+	return b.Add(b.client.deletetrxAction(trxHash))
+}
+
+// AddCurrency queues an addcurrency action
+func (b *Batch) AddCurrency(currency string) (int, error) {
+
+	action, err := b.client.addCurrencyAction(currency)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return b.Add(action)
+}
+
+// AddCoinId queues an addcoinid action
+func (b *Batch) AddCoinId(currency, id string) (int, error) {
+
+	action, err := b.client.addCoinIdAction(currency, id)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return b.Add(action)
+}
+
+// RemoveCurrency queues a remcurrency action
+func (b *Batch) RemoveCurrency(currency string) (int, error) {
+
+	action, err := b.client.removeCurrencyAction(currency)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return b.Add(action)
+}
+
+// NewEvent queues a newevent action
+func (b *Batch) NewEvent(event []docgraph.ContentGroup) (int, error) {
+	return b.Add(b.client.newEventAction(event))
+}
+
+// BindEvent queues a bindevent action
+func (b *Batch) BindEvent(eventHash, componentHash eos.Checksum256) (int, error) {
+	return b.Add(b.client.bindEventAction("bindevent", eventHash, componentHash))
+}
+
+// UnbindEvent queues an unbindevent action
+func (b *Batch) UnbindEvent(eventHash, componentHash eos.Checksum256) (int, error) {
+	return b.Add(b.client.bindEventAction("unbindevent", eventHash, componentHash))
+}
+
+// Plan returns the indexes of the pending actions grouped in the
+// transactions Flush would push
+func (b *Batch) Plan() [][]int {
+
+	var plan [][]int
+
+	for _, chunk := range b.chunks() {
+		indexes := make([]int, len(chunk))
+		for i, pending := range chunk {
+			indexes[i] = pending.index
+		}
+		plan = append(plan, indexes)
+	}
+
+	return plan
+}
+
+func (b *Batch) chunks() [][]batchAction {
+
+	var chunks [][]batchAction
+	var current []batchAction
+	currentSize := 0
+
+	for _, pending := range b.pending {
+
+		full := b.maxActions > 0 && len(current) >= b.maxActions
+		tooBig := b.maxBytes > 0 && currentSize+pending.size > b.maxBytes
+
+		if len(current) > 0 && (full || tooBig) {
+			chunks = append(chunks, current)
+			current = nil
+			currentSize = 0
+		}
+
+		current = append(current, pending)
+		currentSize += pending.size
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+// Flush pushes the pending actions in order and returns the result of
+// every action it tried. It stops at the first failing transaction, the
+// actions of that transaction and the following ones stay pending so the
+// batch can be fixed and flushed again.
+func (b *Batch) Flush(ctx context.Context) ([]BatchResult, error) {
+
+	for _, pending := range b.pending {
+		if err := b.client.Supports(string(pending.action.Name)); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []BatchResult
+
+	for _, chunk := range b.chunks() {
+
+		actions := make([]*eos.Action, len(chunk))
+		for i, pending := range chunk {
+			actions[i] = pending.action
+		}
+
+		tx, err := b.client.push(ctx, actions, b.pushOpts)
+
+		for _, pending := range chunk {
+			results = append(results, BatchResult{
+				Index:  pending.index,
+				Action: pending.action.Name,
+				Tx:     tx,
+				Err:    err,
+			})
+		}
+
+		if err != nil {
+			return results, fmt.Errorf("flush batch: actions %v to %v: %w",
+				chunk[0].index, chunk[len(chunk)-1].index, err)
+		}
+
+		b.pending = b.pending[len(chunk):]
+	}
+
+	return results, nil
+}
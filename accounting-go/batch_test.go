@@ -0,0 +1,248 @@
+package accounting_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	eostest "github.com/digital-scarcity/eos-go-test"
+	"github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+const testBlockID = "0000000c5cf4ae4a6f3a2d1c7ef0d5a6e2b3c4d5e6f708192a3b4c5d6e7f8091"
+
+// pushReply is the answer of the push server to a push_transaction
+type pushReply struct {
+	status int
+	body   string
+}
+
+var (
+	pushOK         = pushTrx(strings.Repeat("ab", 32))
+	pushNotTrusted = pushAPIError(3050003, "eosio_assert_message_exception", "eosio_assert_message assertion failure",
+		"assertion failure with message: Only trusted accounts can perform this action")
+)
+
+// pushTrx is the reply to a transaction executed with id
+func pushTrx(id string) pushReply {
+	return pushReply{http.StatusAccepted, `{
+		"transaction_id": "` + id + `",
+		"processed": {"id": "` + id + `", "action_traces": [
+			{"receiver": "accounting", "block_num": 12, "console": "root created"}
+		]}
+	}`}
+}
+
+func pushAPIError(code int, name, what, message string) pushReply {
+	return pushReply{http.StatusInternalServerError, fmt.Sprintf(`{
+		"code": 500,
+		"message": "Internal Service Error",
+		"error": {"code": %v, "name": %q, "what": %q, "details": [{"message": %q}]}
+	}`, code, name, what, message)}
+}
+
+// pushServer is a fake node answering get_info and get_required_keys, and
+// the push_transaction requests with replies in order, repeating the last
+// one. onPush is called with the number of the push before replying.
+type pushServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	replies []pushReply
+	infos   int
+	pushes  int
+	onPush  func(n int)
+}
+
+func newPushServer(t *testing.T, replies ...pushReply) *pushServer {
+
+	s := &pushServer{replies: replies}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch r.URL.Path {
+
+		case "/v1/chain/get_info":
+			s.infos++
+			fmt.Fprintf(w, `{"chain_id": %q, "head_block_num": 12, "head_block_id": %q,
+				"last_irreversible_block_num": 12, "last_irreversible_block_id": %q}`,
+				strings.Repeat("00", 32), testBlockID, testBlockID)
+
+		case "/v1/chain/get_required_keys":
+			var request struct {
+				AvailableKeys []string `json:"available_keys"`
+			}
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.NilError(t, json.NewEncoder(w).Encode(map[string][]string{"required_keys": request.AvailableKeys}))
+
+		case "/v1/chain/push_transaction":
+			s.pushes++
+			if s.onPush != nil {
+				s.onPush(s.pushes)
+			}
+			reply := s.replies[len(s.replies)-1]
+			if s.pushes <= len(s.replies) {
+				reply = s.replies[s.pushes-1]
+			}
+			w.WriteHeader(reply.status)
+			fmt.Fprint(w, reply.body)
+
+		default:
+			t.Errorf("unexpected request to %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return s
+}
+
+func (s *pushServer) counts() (infos, pushes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.infos, s.pushes
+}
+
+// serverAPI returns an API of s signing with the default test key
+func serverAPI(t *testing.T, s *pushServer) *eos.API {
+
+	api := eos.New(s.URL)
+
+	keyBag := &eos.KeyBag{}
+	assert.NilError(t, keyBag.ImportPrivateKey(context.Background(), eostest.DefaultKey()))
+	api.SetSigner(keyBag)
+
+	return api
+}
+
+func TestBatchPlan(t *testing.T) {
+
+	client, err := accounting.NewClient(eos.New("http://localhost:8888"), eos.AN("accounting"))
+	assert.NilError(t, err)
+
+	account := accounting.Account{
+		Name:   "Expenses",
+		Code:   "5000",
+		Type:   accounting.AccountTypeExpense,
+		Parent: testHash(1),
+		Ledger: testHash(1),
+	}
+
+	t.Run("Bounds the actions per transaction", func(t *testing.T) {
+
+		batch := client.NewBatch(accounting.WithMaxActions(2))
+
+		for i := 0; i < 5; i++ {
+			index, err := batch.CreateAcct(account.ContentGroups())
+			assert.NilError(t, err)
+			assert.Equal(t, index, i)
+		}
+
+		assert.Equal(t, batch.Len(), 5)
+		assert.DeepEqual(t, batch.Plan(), [][]int{{0, 1}, {2, 3}, {4}})
+	})
+
+	t.Run("Bounds the bytes per transaction", func(t *testing.T) {
+
+		batch := client.NewBatch(accounting.WithMaxBytes(1))
+
+		_, err := batch.CreateAcct(account.ContentGroups())
+		assert.NilError(t, err)
+		_, err = batch.Deleteacc(testHash(2))
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, batch.Plan(), [][]int{{0}, {1}})
+	})
+
+	t.Run("Rejects invalid currencies", func(t *testing.T) {
+
+		batch := client.NewBatch()
+
+		_, err := batch.AddCurrency("USD")
+		assert.ErrorContains(t, err, "error adding currency")
+		assert.Equal(t, batch.Len(), 0)
+	})
+
+	t.Run("Rejects actions that can not be serialized", func(t *testing.T) {
+
+		batch := client.NewBatch()
+
+		index, err := batch.Add(&eos.Action{
+			Account:    "accounting",
+			Name:       eos.ActN("broken"),
+			ActionData: eos.NewActionData(make(chan int)),
+		})
+		assert.ErrorContains(t, err, "batch broken")
+		assert.Equal(t, index, -1)
+		assert.Equal(t, batch.Len(), 0)
+	})
+}
+
+func TestBatchFlush(t *testing.T) {
+
+	ctx := context.Background()
+
+	first, second, third := strings.Repeat("01", 32), strings.Repeat("02", 32), strings.Repeat("03", 32)
+
+	server := newPushServer(t, pushTrx(first), pushNotTrusted, pushTrx(second), pushTrx(third))
+	defer server.Close()
+
+	client, err := accounting.NewClient(serverAPI(t, server), eos.AN("accounting"))
+	assert.NilError(t, err)
+
+	batch := client.NewBatch(accounting.WithMaxActions(2))
+
+	for _, currency := range []string{"2,USD", "2,EUR", "2,HUSD", "4,TLOS", "8,BTC"} {
+		_, err := batch.AddCurrency(currency)
+		assert.NilError(t, err)
+	}
+
+	// the second transaction fails, the third one is not pushed
+	results, err := batch.Flush(ctx)
+	assert.Assert(t, errors.Is(err, accounting.ErrNotTrusted), err)
+	assert.ErrorContains(t, err, "flush batch: actions 2 to 3")
+
+	assert.Equal(t, len(results), 4)
+
+	for i, result := range results {
+		assert.Equal(t, result.Index, i)
+		assert.Equal(t, result.Action, eos.ActN("addcurrency"))
+	}
+
+	assert.Equal(t, results[0].Tx.TrxID, first)
+	assert.Equal(t, results[1].Tx, results[0].Tx)
+	assert.NilError(t, results[1].Err)
+	assert.Assert(t, errors.Is(results[2].Err, accounting.ErrNotTrusted))
+	assert.Assert(t, errors.Is(results[3].Err, accounting.ErrNotTrusted))
+
+	_, pushes := server.counts()
+	assert.Equal(t, pushes, 2)
+
+	// the failed transaction and the following ones stay pending
+	assert.Equal(t, batch.Len(), 3)
+	assert.DeepEqual(t, batch.Plan(), [][]int{{2, 3}, {4}})
+
+	results, err = batch.Flush(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, batch.Len(), 0)
+
+	var indexes []int
+	var trxIDs []string
+	for _, result := range results {
+		indexes = append(indexes, result.Index)
+		trxIDs = append(trxIDs, result.Tx.TrxID)
+	}
+
+	assert.DeepEqual(t, indexes, []int{2, 3, 4})
+	assert.DeepEqual(t, trxIDs, []string{second, second, third})
+}
@@ -0,0 +1,159 @@
+package accounting
+
+import (
+	"fmt"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// ComponentOption sets the optional fields of a transaction component
+type ComponentOption func(*Component)
+
+// WithFrom sets the origin of the component
+func WithFrom(from string) ComponentOption {
+	return func(c *Component) {
+		c.From = from
+	}
+}
+
+// WithTo sets the destination of the component
+func WithTo(to string) ComponentOption {
+	return func(c *Component) {
+		c.To = to
+	}
+}
+
+// WithEvent binds the component to an event
+func WithEvent(event eos.Checksum256) ComponentOption {
+	return func(c *Component) {
+		c.Event = event
+	}
+}
+
+// TransactionBuilder builds the trx_info of the upserttrx and crryconvtrx
+// actions, i.e.
+//
+//	trxInfo, err := accounting.NewTransactionBuilder(ledger.Hash).
+//		Date(time.Now()).
+//		Memo("Monthly fee").
+//		Debit(expenses, amount, "fee").
+//		Credit(treasury, amount, "fee", accounting.WithEvent(event)).
+//		Build()
+type TransactionBuilder struct {
+	trx Transaction
+}
+
+// NewTransactionBuilder starts a transaction for ledger
+func NewTransactionBuilder(ledger eos.Checksum256) *TransactionBuilder {
+	return &TransactionBuilder{
+		trx: Transaction{Ledger: ledger},
+	}
+}
+
+// Ledger sets the ledger of the transaction
+func (b *TransactionBuilder) Ledger(ledger eos.Checksum256) *TransactionBuilder {
+	b.trx.Ledger = ledger
+	return b
+}
+
+// Date sets the date of the transaction
+func (b *TransactionBuilder) Date(date time.Time) *TransactionBuilder {
+	b.trx.Date = TimePointOf(date)
+	return b
+}
+
+// Memo sets the memo of the transaction
+func (b *TransactionBuilder) Memo(memo string) *TransactionBuilder {
+	b.trx.Memo = memo
+	return b
+}
+
+// Name sets the name of the transaction
+func (b *TransactionBuilder) Name(name string) *TransactionBuilder {
+	b.trx.Name = name
+	return b
+}
+
+// Notes sets the notes of the transaction
+func (b *TransactionBuilder) Notes(notes string) *TransactionBuilder {
+	b.trx.Notes = notes
+	return b
+}
+
+// Debit adds a DEBIT component to account
+func (b *TransactionBuilder) Debit(account eos.Checksum256, amount eos.Asset, memo string, opts ...ComponentOption) *TransactionBuilder {
+	return b.component(Debit, account, amount, memo, opts)
+}
+
+// Credit adds a CREDIT component to account
+func (b *TransactionBuilder) Credit(account eos.Checksum256, amount eos.Asset, memo string, opts ...ComponentOption) *TransactionBuilder {
+	return b.component(Credit, account, amount, memo, opts)
+}
+
+// Component adds an already built component
+func (b *TransactionBuilder) Component(component Component) *TransactionBuilder {
+	b.trx.Components = append(b.trx.Components, component)
+	return b
+}
+
+func (b *TransactionBuilder) component(tagType string, account eos.Checksum256, amount eos.Asset, memo string, opts []ComponentOption) *TransactionBuilder {
+
+	component := Component{
+		Account: account,
+		Amount:  amount,
+		Memo:    memo,
+		Type:    tagType,
+	}
+
+	for _, opt := range opts {
+		opt(&component)
+	}
+
+	return b.Component(component)
+}
+
+// Transaction returns the transaction built so far, failing if any of the
+// contents required by the contract is missing
+func (b *TransactionBuilder) Transaction() (Transaction, error) {
+
+	if len(b.trx.Ledger) == 0 {
+		return Transaction{}, fmt.Errorf("build transaction: missing %v", trxLedgerLabel)
+	}
+
+	if b.trx.Date == 0 {
+		return Transaction{}, fmt.Errorf("build transaction: missing %v", trxDateLabel)
+	}
+
+	if len(b.trx.Components) == 0 {
+		return Transaction{}, fmt.Errorf("build transaction: transaction must contain at least 1 component")
+	}
+
+	for i, component := range b.trx.Components {
+		if len(component.Account) == 0 {
+			return Transaction{}, fmt.Errorf("build transaction: missing account on component %v", i)
+		}
+		if component.Type != Debit && component.Type != Credit {
+			return Transaction{}, fmt.Errorf("build transaction: invalid type %v on component %v, expected [%v or %v]",
+				component.Type, i, Debit, Credit)
+		}
+	}
+
+	trx := b.trx
+	trx.Components = append([]Component(nil), b.trx.Components...)
+
+	return trx, nil
+}
+
+// Build returns the details and component content groups of the transaction
+func (b *TransactionBuilder) Build() ([]docgraph.ContentGroup, error) {
+
+	trx, err := b.Transaction()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trx.ContentGroups(), nil
+}
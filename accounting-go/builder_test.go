@@ -0,0 +1,95 @@
+package accounting_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+func testHash(b byte) eos.Checksum256 {
+	hash := eos.Checksum256(make([]byte, 32))
+	hash[31] = b
+	return hash
+}
+
+func contentLabels(group docgraph.ContentGroup) []string {
+	var labels []string
+	for _, item := range group {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestTransactionBuilder(t *testing.T) {
+
+	usd, _ := eos.StringToSymbol("2,USD")
+	amount := eos.Asset{Amount: 100000, Symbol: usd}
+
+	t.Run("Builds details and component groups", func(t *testing.T) {
+
+		trxInfo, err := accounting.NewTransactionBuilder(testHash(1)).
+			Date(time.Date(2020, 12, 17, 21, 45, 11, 0, time.UTC)).
+			Memo("Test transaction").
+			Name("transaction name").
+			Notes("notes").
+			Debit(testHash(2), amount, "debit", accounting.WithTo("test_to"), accounting.WithEvent(testHash(4))).
+			Credit(testHash(3), amount, "credit", accounting.WithFrom("test_from"), accounting.WithTo("test_to")).
+			Build()
+
+		assert.NilError(t, err)
+		assert.Equal(t, len(trxInfo), 3)
+
+		assert.DeepEqual(t, contentLabels(trxInfo[0]), []string{
+			"content_group_label", "trx_date", "trx_ledger", "trx_memo", "trx_name", "trx_notes",
+		})
+
+		assert.DeepEqual(t, contentLabels(trxInfo[1]), []string{
+			"content_group_label", "memo", "account", "amount", "from", "to", "type", "event",
+		})
+
+		assert.DeepEqual(t, contentLabels(trxInfo[2]), []string{
+			"content_group_label", "memo", "account", "amount", "from", "to", "type",
+		})
+
+		trx, err := accounting.TransactionFromContentGroups(trxInfo)
+		assert.NilError(t, err)
+		assert.Equal(t, trx.Components[0].Type, accounting.Debit)
+		assert.Equal(t, trx.Components[0].Event.String(), testHash(4).String())
+		assert.Equal(t, trx.Components[1].Type, accounting.Credit)
+		assert.Equal(t, trx.Components[1].From, "test_from")
+	})
+
+	t.Run("Fails without components", func(t *testing.T) {
+
+		_, err := accounting.NewTransactionBuilder(testHash(1)).
+			Date(time.Now()).
+			Memo("Test transaction").
+			Build()
+
+		assert.ErrorContains(t, err, "at least 1 component")
+	})
+
+	t.Run("Fails without date", func(t *testing.T) {
+
+		_, err := accounting.NewTransactionBuilder(testHash(1)).
+			Debit(testHash(2), amount, "debit").
+			Credit(testHash(3), amount, "credit").
+			Build()
+
+		assert.ErrorContains(t, err, "trx_date")
+	})
+
+	t.Run("Fails with an invalid component type", func(t *testing.T) {
+
+		_, err := accounting.NewTransactionBuilder(testHash(1)).
+			Date(time.Now()).
+			Component(accounting.Component{Account: testHash(2), Amount: amount, Type: "TRANSFER"}).
+			Build()
+
+		assert.ErrorContains(t, err, "invalid type TRANSFER")
+	})
+}
@@ -0,0 +1,47 @@
+package accounting
+
+import (
+	"context"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// Chain is the accounting contract as seen by its users. Client pushes the
+// actions to a node and FakeChain runs them in memory, so code written
+// against Chain can be tested without nodeos.
+type Chain interface {
+	Graph
+
+	Contract() eos.AccountName
+	Actor() eos.AccountName
+
+	CreateRoot(ctx context.Context, notes string) (*TxResult, error)
+	AddLedger(ctx context.Context, ledger []docgraph.ContentGroup) (*TxResult, error)
+	CreateAcct(ctx context.Context, account []docgraph.ContentGroup) (*TxResult, error)
+	Updateacc(ctx context.Context, accountHash eos.Checksum256, accountInfo []docgraph.ContentGroup) (*TxResult, error)
+	Deleteacc(ctx context.Context, accountHash eos.Checksum256) (*TxResult, error)
+	Upserttrx(ctx context.Context, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (*TxResult, error)
+	Crryconvtrx(ctx context.Context, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (*TxResult, error)
+	Deletetrx(ctx context.Context, trxHash eos.Checksum256) (*TxResult, error)
+	NewEvent(ctx context.Context, event []docgraph.ContentGroup) (*TxResult, error)
+	BindEvent(ctx context.Context, eventHash, componentHash eos.Checksum256) (*TxResult, error)
+	UnbindEvent(ctx context.Context, eventHash, componentHash eos.Checksum256) (*TxResult, error)
+
+	SetSetting(ctx context.Context, setting string, value docgraph.FlexValue) (*TxResult, error)
+	RemSetting(ctx context.Context, setting string) (*TxResult, error)
+	AddTrustedAccount(ctx context.Context, account eos.AccountName) (*TxResult, error)
+	RemTrustedAccount(ctx context.Context, account eos.AccountName) (*TxResult, error)
+	AddCurrency(ctx context.Context, currency string) (*TxResult, error)
+	AddCoinId(ctx context.Context, currency, id string) (*TxResult, error)
+	RemoveCurrency(ctx context.Context, currency string) (*TxResult, error)
+
+	GetSettings(ctx context.Context) (Settings, error)
+	GetLastCursor(ctx context.Context) (string, error)
+	GetCursorFromSource(ctx context.Context, source string) (string, error)
+}
+
+var (
+	_ Chain = (*Client)(nil)
+	_ Chain = (*FakeChain)(nil)
+)
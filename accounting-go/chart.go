@@ -0,0 +1,536 @@
+package accounting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// ChartAccount is an entry of a chart of accounts, accounts without parent
+// code hang from the ledger
+type ChartAccount struct {
+	Code       string      `json:"code"`
+	Name       string      `json:"name"`
+	ParentCode string      `json:"parent_code,omitempty"`
+	TagType    string      `json:"tag_type"`
+	Type       AccountType `json:"account_type"`
+	// Path and IsLeaf are filled by ExportChart, the path joins the names
+	// from the top level account with chartPathSeparator
+	Path   string `json:"path,omitempty"`
+	IsLeaf bool   `json:"is_leaf,omitempty"`
+	// Children is only used by nested JSON charts
+	Children []ChartAccount `json:"children,omitempty"`
+}
+
+// UnmarshalJSON decodes a missing account_type as AccountTypeUnknown, so
+// the account takes the type of its parent
+func (a *ChartAccount) UnmarshalJSON(data []byte) error {
+
+	type plain ChartAccount
+	account := plain{Type: AccountTypeUnknown}
+
+	if err := json.Unmarshal(data, &account); err != nil {
+		return err
+	}
+
+	*a = ChartAccount(account)
+	return nil
+}
+
+// Chart is a chart of accounts
+type Chart []ChartAccount
+
+var chartColumns = map[string]string{
+	"code":             "code",
+	"account_code":     "code",
+	"name":             "name",
+	"account_name":     "name",
+	"parent_code":      "parent_code",
+	"parent":           "parent_code",
+	"tag_type":         "tag_type",
+	"account_tag_type": "tag_type",
+	"account_type":     "account_type",
+	"type":             "account_type",
+	"path":             "path",
+	"is_leaf":          "is_leaf",
+}
+
+// chartPathSeparator joins the account names of ChartAccount.Path
+const chartPathSeparator = " / "
+
+// ReadChartCSV reads a chart of accounts from CSV. The first row names the
+// columns: code, name, parent_code, tag_type and account_type, the path and
+// is_leaf columns written by WriteCSV are optional.
+func ReadChartCSV(r io.Reader) (Chart, error) {
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+
+	if err != nil {
+		return nil, fmt.Errorf("read chart header: %v", err)
+	}
+
+	columns := make(map[string]int)
+
+	for i, column := range header {
+		if name, ok := chartColumns[strings.ToLower(strings.TrimSpace(column))]; ok {
+			columns[name] = i
+		}
+	}
+
+	for _, required := range []string{"code", "name", "tag_type", "account_type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("read chart: missing column %v", required)
+		}
+	}
+
+	var chart Chart
+
+	for line := 2; ; line++ {
+
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read chart: %v", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		accountType := AccountTypeUnknown
+
+		// exported charts keep the accounts whose type the contract didn't store
+		if value := field("account_type"); !strings.EqualFold(value, AccountTypeUnknown.String()) {
+			accountType, err = ParseAccountType(value)
+			if err != nil {
+				return nil, fmt.Errorf("read chart: line %v: %v", line, err)
+			}
+		}
+
+		chart = append(chart, ChartAccount{
+			Code:       field("code"),
+			Name:       field("name"),
+			ParentCode: field("parent_code"),
+			TagType:    strings.ToUpper(field("tag_type")),
+			Type:       accountType,
+			Path:       field("path"),
+			IsLeaf:     strings.EqualFold(field("is_leaf"), "true"),
+		})
+	}
+
+	return chart, nil
+}
+
+// ReadChartJSON reads a chart of accounts from a JSON array, the accounts
+// either name their parent code or are nested in the children of their parent
+func ReadChartJSON(r io.Reader) (Chart, error) {
+
+	var nested Chart
+
+	if err := json.NewDecoder(r).Decode(&nested); err != nil {
+		return nil, fmt.Errorf("read chart: %v", err)
+	}
+
+	var chart Chart
+
+	var flatten func(accounts []ChartAccount, parentCode string)
+	flatten = func(accounts []ChartAccount, parentCode string) {
+		for _, account := range accounts {
+			if parentCode != "" {
+				account.ParentCode = parentCode
+			}
+			children := account.Children
+			account.Children = nil
+			account.TagType = strings.ToUpper(account.TagType)
+			chart = append(chart, account)
+			flatten(children, account.Code)
+		}
+	}
+
+	flatten(nested, "")
+
+	return chart, nil
+}
+
+// ExportChart walks the accounts of ledger and returns them as a chart,
+// parents come before their children and siblings are sorted by code
+func ExportChart(ctx context.Context, g Graph, ledger eos.Checksum256) (Chart, error) {
+
+	var chart Chart
+	codes := make(map[string]string)
+
+	err := WalkAccounts(ctx, g, ledger, func(account Account, path []string) error {
+
+		codes[account.Hash.String()] = account.Code
+
+		chart = append(chart, ChartAccount{
+			Code:       account.Code,
+			Name:       account.Name,
+			ParentCode: codes[account.Parent.String()],
+			TagType:    account.TagType,
+			Type:       account.Type,
+			Path:       strings.Join(path, chartPathSeparator),
+			IsLeaf:     account.IsLeaf,
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("export chart: %v", err)
+	}
+
+	return chart, nil
+}
+
+// WriteCSV writes the chart with a header row, the output can be read back
+// with ReadChartCSV
+func (c Chart) WriteCSV(w io.Writer) error {
+
+	writer := csv.NewWriter(w)
+
+	header := []string{"code", "name", "parent_code", "path", "tag_type", "account_type", "is_leaf"}
+
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write chart: %v", err)
+	}
+
+	for _, account := range c {
+
+		record := []string{
+			account.Code,
+			account.Name,
+			account.ParentCode,
+			account.Path,
+			account.TagType,
+			account.Type.String(),
+			strconv.FormatBool(account.IsLeaf),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("write chart: %v", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write chart: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the chart as a flat JSON array, the output can be read
+// back with ReadChartJSON
+func (c Chart) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("write chart: %v", err)
+	}
+
+	return nil
+}
+
+// Levels validates the chart and groups its accounts by depth, parents
+// always come in a previous level. The accounts of type Unknown, exported
+// from accounts created before the contract stored the type, take the type
+// of their parent, so only the top level accounts need a type.
+func (c Chart) Levels() ([][]ChartAccount, error) {
+
+	byCode := make(map[string]ChartAccount)
+	siblings := make(map[string]string)
+
+	for i, account := range c {
+
+		if account.Code == "" {
+			return nil, fmt.Errorf("chart account %v: missing code", i)
+		}
+
+		if account.Name == "" {
+			return nil, fmt.Errorf("chart account %v: missing name", account.Code)
+		}
+
+		if account.TagType != Debit && account.TagType != Credit {
+			return nil, fmt.Errorf("chart account %v: invalid tag type %v, expected [%v or %v]",
+				account.Code, account.TagType, Debit, Credit)
+		}
+
+		if account.Type < AccountTypeUnknown || account.Type > AccountTypeLoss {
+			return nil, fmt.Errorf("chart account %v: invalid account type %v", account.Code, int64(account.Type))
+		}
+
+		if _, ok := byCode[account.Code]; ok {
+			return nil, fmt.Errorf("chart account %v: duplicated code", account.Code)
+		}
+
+		// the contract compares the names of siblings ignoring case
+		sibling := account.ParentCode + "/" + strings.ToLower(account.Name)
+		if code, ok := siblings[sibling]; ok {
+			return nil, fmt.Errorf("chart account %v: name %v already used by %v", account.Code, account.Name, code)
+		}
+
+		siblings[sibling] = account.Code
+		byCode[account.Code] = account
+	}
+
+	depths := make(map[string]int)
+
+	var depth func(code string, visiting map[string]bool) (int, error)
+	depth = func(code string, visiting map[string]bool) (int, error) {
+
+		if d, ok := depths[code]; ok {
+			return d, nil
+		}
+
+		if visiting[code] {
+			return 0, fmt.Errorf("chart account %v: cycle in parent codes", code)
+		}
+
+		account := byCode[code]
+
+		if account.ParentCode == "" {
+			depths[code] = 0
+			return 0, nil
+		}
+
+		if _, ok := byCode[account.ParentCode]; !ok {
+			return 0, fmt.Errorf("chart account %v: unknown parent code %v", code, account.ParentCode)
+		}
+
+		visiting[code] = true
+		d, err := depth(account.ParentCode, visiting)
+
+		if err != nil {
+			return 0, err
+		}
+
+		depths[code] = d + 1
+		return d + 1, nil
+	}
+
+	var levels [][]ChartAccount
+
+	for _, account := range c {
+
+		d, err := depth(account.Code, make(map[string]bool))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for len(levels) <= d {
+			levels = append(levels, nil)
+		}
+
+		levels[d] = append(levels[d], account)
+	}
+
+	types := make(map[string]AccountType)
+
+	for _, level := range levels {
+		for i, account := range level {
+			if account.Type == AccountTypeUnknown && account.ParentCode != "" {
+				level[i].Type = types[account.ParentCode]
+			}
+			if level[i].Type == AccountTypeUnknown {
+				return nil, fmt.Errorf("chart account %v: the account type is unknown", account.Code)
+			}
+			types[account.Code] = level[i].Type
+		}
+	}
+
+	return levels, nil
+}
+
+// ImportResult maps the codes of an imported chart to the account hashes
+type ImportResult struct {
+	Hashes map[string]eos.Checksum256
+	// Created and Existing list the codes of the accounts created by the
+	// import and the ones found in the ledger
+	Created  []string
+	Existing []string
+}
+
+// ImportChart runs ImportChart against the contract of the client
+func (c *Client) ImportChart(ctx context.Context, ledger eos.Checksum256, chart Chart, opts ...BatchOption) (*ImportResult, error) {
+	return ImportChart(ctx, c, ledger, chart, opts...)
+}
+
+// ImportChart creates the accounts of chart in ledger level by level. On a
+// Client each level is pushed in batches configured by opts, other chains
+// create the accounts one by one. Accounts whose code already exists under
+// the same parent are reused, so a failed import can be run again.
+func ImportChart(ctx context.Context, chain Chain, ledger eos.Checksum256, chart Chart, opts ...BatchOption) (*ImportResult, error) {
+
+	levels, err := chart.Levels()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		Hashes: make(map[string]eos.Checksum256),
+	}
+
+	for depth, level := range levels {
+
+		parents := make(map[string]eos.Checksum256)
+
+		for _, account := range level {
+			if account.ParentCode == "" {
+				parents[ledger.String()] = ledger
+			} else {
+				parent := result.Hashes[account.ParentCode]
+				parents[parent.String()] = parent
+			}
+		}
+
+		existing, err := childAccountCodes(ctx, chain, parents)
+
+		if err != nil {
+			return result, fmt.Errorf("import chart: level %v: %v", depth, err)
+		}
+
+		var created []string
+		var accounts [][]docgraph.ContentGroup
+
+		for _, account := range level {
+
+			parent := ledger
+			if account.ParentCode != "" {
+				parent = result.Hashes[account.ParentCode]
+			}
+
+			if hash, ok := existing[parent.String()][account.Code]; ok {
+				result.Hashes[account.Code] = hash
+				result.Existing = append(result.Existing, account.Code)
+				continue
+			}
+
+			accounts = append(accounts, Account{
+				Name:    account.Name,
+				Code:    account.Code,
+				TagType: account.TagType,
+				Type:    account.Type,
+				Parent:  parent,
+				Ledger:  ledger,
+			}.ContentGroups())
+
+			created = append(created, account.Code)
+		}
+
+		if len(accounts) == 0 {
+			continue
+		}
+
+		if err := createAccounts(ctx, chain, created, accounts, opts...); err != nil {
+			return result, fmt.Errorf("import chart: level %v: %w", depth, err)
+		}
+
+		existing, err = childAccountCodes(ctx, chain, parents)
+
+		if err != nil {
+			return result, fmt.Errorf("import chart: level %v: %v", depth, err)
+		}
+
+		for _, account := range level {
+
+			if _, ok := result.Hashes[account.Code]; ok {
+				continue
+			}
+
+			parent := ledger
+			if account.ParentCode != "" {
+				parent = result.Hashes[account.ParentCode]
+			}
+
+			hash, ok := existing[parent.String()][account.Code]
+
+			if !ok {
+				return result, fmt.Errorf("import chart: account %v not found after creation", account.Code)
+			}
+
+			result.Hashes[account.Code] = hash
+		}
+
+		result.Created = append(result.Created, created...)
+	}
+
+	sort.Strings(result.Created)
+	sort.Strings(result.Existing)
+
+	return result, nil
+}
+
+// createAccounts pushes the createacc actions of accounts, in batches when
+// chain is a Client, codes are the account codes used in the errors
+func createAccounts(ctx context.Context, chain Chain, codes []string, accounts [][]docgraph.ContentGroup, opts ...BatchOption) error {
+
+	if c, ok := chain.(*Client); ok {
+
+		batch := c.NewBatch(opts...)
+
+		for _, account := range accounts {
+			if _, err := batch.CreateAcct(account); err != nil {
+				return err
+			}
+		}
+
+		_, err := batch.Flush(ctx)
+		return err
+	}
+
+	for i, account := range accounts {
+		if _, err := chain.CreateAcct(ctx, account); err != nil {
+			return fmt.Errorf("account %v: %w", codes[i], err)
+		}
+	}
+
+	return nil
+}
+
+// childAccountCodes returns the code to hash map of the accounts hanging
+// from each of parents, keyed by the parent hash
+func childAccountCodes(ctx context.Context, g Graph, parents map[string]eos.Checksum256) (map[string]map[string]eos.Checksum256, error) {
+
+	codes := make(map[string]map[string]eos.Checksum256)
+
+	for key, hash := range parents {
+
+		children, err := ChildAccounts(ctx, g, hash)
+
+		if err != nil {
+			return nil, fmt.Errorf("children of %v: %v", key, err)
+		}
+
+		codes[key] = make(map[string]eos.Checksum256)
+
+		for _, child := range children {
+			codes[key][child.Code] = child.Hash
+		}
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,273 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+const chart_csv = `code,name,parent_code,tag_type,account_type
+1000,Assets,,DEBIT,Asset
+1100,Cash,1000,debit,Asset
+1110,Petty cash,1100,DEBIT,0
+5000,Expenses,,DEBIT,Expense
+5100,Salaries,5000,DEBIT,expense
+`
+
+const chart_json = `[
+	{ "code": "1000", "name": "Assets", "tag_type": "DEBIT", "account_type": "Asset", "children": [
+		{ "code": "1100", "name": "Cash", "tag_type": "DEBIT", "account_type": "Asset", "children": [
+			{ "code": "1110", "name": "Petty cash", "tag_type": "DEBIT" }
+		]}
+	]},
+	{ "code": "5000", "name": "Expenses", "tag_type": "DEBIT", "account_type": "Expense" },
+	{ "code": "5100", "name": "Salaries", "parent_code": "5000", "tag_type": "DEBIT", "account_type": "Expense" }
+]`
+
+func levelCodes(levels [][]accounting.ChartAccount) [][]string {
+	var codes [][]string
+	for _, level := range levels {
+		var levelCodes []string
+		for _, account := range level {
+			levelCodes = append(levelCodes, account.Code)
+		}
+		codes = append(codes, levelCodes)
+	}
+	return codes
+}
+
+func TestReadChart(t *testing.T) {
+
+	expected := [][]string{{"1000", "5000"}, {"1100", "5100"}, {"1110"}}
+
+	t.Run("CSV", func(t *testing.T) {
+
+		chart, err := accounting.ReadChartCSV(strings.NewReader(chart_csv))
+		assert.NilError(t, err)
+		assert.Equal(t, len(chart), 5)
+		assert.Equal(t, chart[1].TagType, accounting.Debit)
+		assert.Equal(t, chart[4].Type, accounting.AccountTypeExpense)
+
+		levels, err := chart.Levels()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, levelCodes(levels), expected)
+	})
+
+	t.Run("Nested JSON", func(t *testing.T) {
+
+		chart, err := accounting.ReadChartJSON(strings.NewReader(chart_json))
+		assert.NilError(t, err)
+		assert.Equal(t, chart[2].ParentCode, "1100")
+		assert.Equal(t, chart[2].Type, accounting.AccountTypeUnknown)
+
+		levels, err := chart.Levels()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, levelCodes(levels), expected)
+		assert.Equal(t, levels[2][0].Type, accounting.AccountTypeAsset)
+
+		encoded, err := json.Marshal(chart[0].Type)
+		assert.NilError(t, err)
+		assert.Equal(t, string(encoded), `"Asset"`)
+	})
+
+	t.Run("Rejects invalid charts", func(t *testing.T) {
+
+		_, err := accounting.Chart{
+			{Code: "1", Name: "A", TagType: accounting.Debit, ParentCode: "2"},
+			{Code: "2", Name: "B", TagType: accounting.Debit, ParentCode: "1"},
+		}.Levels()
+		assert.ErrorContains(t, err, "cycle")
+
+		_, err = accounting.Chart{
+			{Code: "1", Name: "A", TagType: accounting.Debit, ParentCode: "9"},
+		}.Levels()
+		assert.ErrorContains(t, err, "unknown parent code 9")
+
+		_, err = accounting.Chart{
+			{Code: "1", Name: "Cash", TagType: accounting.Debit},
+			{Code: "2", Name: "CASH", TagType: accounting.Debit},
+		}.Levels()
+		assert.ErrorContains(t, err, "name CASH already used by 1")
+
+		_, err = accounting.Chart{
+			{Code: "1", Name: "A", TagType: "TRANSFER"},
+		}.Levels()
+		assert.ErrorContains(t, err, "invalid tag type")
+	})
+
+	t.Run("Unknown types are inherited", func(t *testing.T) {
+
+		levels, err := accounting.Chart{
+			{Code: "1000", Name: "Assets", TagType: accounting.Debit, Type: accounting.AccountTypeAsset},
+			{Code: "1100", Name: "Cash", TagType: accounting.Debit, Type: accounting.AccountTypeUnknown, ParentCode: "1000"},
+			{Code: "1110", Name: "Petty cash", TagType: accounting.Debit, Type: accounting.AccountTypeUnknown, ParentCode: "1100"},
+		}.Levels()
+		assert.NilError(t, err)
+
+		types := make(map[string]accounting.AccountType)
+		for _, level := range levels {
+			for _, account := range level {
+				types[account.Code] = account.Type
+			}
+		}
+
+		assert.DeepEqual(t, types, map[string]accounting.AccountType{
+			"1000": accounting.AccountTypeAsset,
+			"1100": accounting.AccountTypeAsset,
+			"1110": accounting.AccountTypeAsset,
+		})
+
+		// the chart is rejected before any account is created
+		_, err = accounting.Chart{
+			{Code: "1000", Name: "Assets", TagType: accounting.Debit, Type: accounting.AccountTypeAsset},
+			{Code: "9000", Name: "Legacy", TagType: accounting.Debit, Type: accounting.AccountTypeUnknown},
+			{Code: "9100", Name: "Legacy child", TagType: accounting.Debit, Type: accounting.AccountTypeUnknown, ParentCode: "9000"},
+		}.Levels()
+		assert.ErrorContains(t, err, "chart account 9000: the account type is unknown")
+	})
+}
+
+func TestExportChart(t *testing.T) {
+
+	g := newMemoryGraph()
+	ledger := g.addLedger(t, "Main")
+	assets := g.addAccount(t, ledger, "1000", "Assets", accounting.Debit, false)
+	cash := g.addAccount(t, assets, "1100", "Cash", accounting.Debit, false)
+	g.addAccount(t, cash, "1110", "Petty cash", accounting.Debit, true)
+	g.addAccount(t, ledger, "2000", "Liabilities", accounting.Credit, true)
+
+	chart, err := accounting.ExportChart(context.Background(), g, ledger)
+	assert.NilError(t, err)
+	assert.Equal(t, len(chart), 4)
+	assert.Equal(t, chart[2].Code, "1110")
+	assert.Equal(t, chart[2].ParentCode, "1100")
+	assert.Equal(t, chart[2].Path, "Assets / Cash / Petty cash")
+	assert.Assert(t, chart[2].IsLeaf)
+	assert.Equal(t, chart[3].ParentCode, "")
+	assert.Equal(t, chart[3].TagType, accounting.Credit)
+
+	t.Run("CSV", func(t *testing.T) {
+
+		var out bytes.Buffer
+		assert.NilError(t, chart.WriteCSV(&out))
+		assert.Assert(t, strings.HasPrefix(out.String(), "code,name,parent_code,path,tag_type,account_type,is_leaf\n"))
+		assert.Assert(t, strings.Contains(out.String(), "1110,Petty cash,1100,Assets / Cash / Petty cash,DEBIT,Unknown,true\n"))
+
+		read, err := accounting.ReadChartCSV(&out)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, read, chart)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+
+		var out bytes.Buffer
+		assert.NilError(t, chart.WriteJSON(&out))
+
+		read, err := accounting.ReadChartJSON(&out)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, read, chart)
+	})
+}
+
+func TestImportChart(t *testing.T) {
+
+	ctx := context.Background()
+
+	// children come before their parents, the import creates them level
+	// by level. 1000 and 1100 already exist in the ledger.
+	chart := accounting.Chart{
+		{Code: "5100", Name: "Rent", TagType: accounting.Debit, Type: accounting.AccountTypeUnknown, ParentCode: "5000"},
+		{Code: "1110", Name: "Petty cash", TagType: accounting.Debit, Type: accounting.AccountTypeAsset, ParentCode: "1100"},
+		{Code: "1100", Name: "Cash", TagType: accounting.Debit, Type: accounting.AccountTypeAsset, ParentCode: "1000"},
+		{Code: "1200", Name: "Bank", TagType: accounting.Debit, Type: accounting.AccountTypeAsset, ParentCode: "1000"},
+		{Code: "1000", Name: "Assets", TagType: accounting.Debit, Type: accounting.AccountTypeAsset},
+		{Code: "5000", Name: "Expenses", TagType: accounting.Debit, Type: accounting.AccountTypeExpense},
+	}
+
+	parents := map[string]string{"1110": "1100", "1200": "1000", "5100": "5000"}
+	types := map[string]accounting.AccountType{
+		"1110": accounting.AccountTypeAsset,
+		"1200": accounting.AccountTypeAsset,
+		"5100": accounting.AccountTypeExpense,
+	}
+
+	t.Run("Creates the missing accounts", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+
+		result, err := accounting.ImportChart(ctx, l.chain, l.ledger, chart)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, result.Created, []string{"1110", "1200", "5000", "5100"})
+		assert.DeepEqual(t, result.Existing, []string{"1000", "1100"})
+		assert.Equal(t, len(result.Hashes), 6)
+		assert.Equal(t, result.Hashes["1000"].String(), l.assets.String())
+		assert.Equal(t, result.Hashes["1100"].String(), l.cash.String())
+
+		ledgerAccounts, err := accounting.ChildAccounts(ctx, l.chain, l.ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, len(ledgerAccounts), 3)
+		assert.Equal(t, ledgerAccounts[2].Code, "5000")
+		assert.Equal(t, ledgerAccounts[2].Hash.String(), result.Hashes["5000"].String())
+
+		for code, parentCode := range parents {
+
+			children, err := accounting.ChildAccounts(ctx, l.chain, result.Hashes[parentCode])
+			assert.NilError(t, err)
+
+			found := false
+			for _, child := range children {
+				if child.Code == code {
+					found = true
+					assert.Equal(t, child.Hash.String(), result.Hashes[code].String())
+					assert.Equal(t, child.Type, types[code])
+				}
+			}
+			assert.Assert(t, found, "account %v not under %v", code, parentCode)
+		}
+
+		// running the import again reuses every account
+		again, err := accounting.ImportChart(ctx, l.chain, l.ledger, chart)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(again.Created), 0)
+		assert.DeepEqual(t, again.Existing, []string{"1000", "1100", "1110", "1200", "5000", "5100"})
+		for code, hash := range result.Hashes {
+			assert.Equal(t, again.Hashes[code].String(), hash.String())
+		}
+	})
+
+	t.Run("Wraps the contract errors", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+
+		// 1000 already has a child named Cash
+		_, err := accounting.ImportChart(ctx, l.chain, l.ledger, accounting.Chart{
+			{Code: "1000", Name: "Assets", TagType: accounting.Debit, Type: accounting.AccountTypeAsset},
+			{Code: "1300", Name: "cash", TagType: accounting.Debit, Type: accounting.AccountTypeAsset, ParentCode: "1000"},
+		})
+
+		var duplicate *accounting.ErrDuplicateAccountName
+		assert.Assert(t, errors.As(err, &duplicate), "%v", err)
+		assert.ErrorContains(t, err, "import chart: level 1: account 1300")
+	})
+
+	t.Run("Rejects unknown types before creating accounts", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+		documents := len(l.chain.Snapshot().Documents())
+
+		_, err := accounting.ImportChart(ctx, l.chain, l.ledger, accounting.Chart{
+			{Code: "6000", Name: "Other", TagType: accounting.Debit, Type: accounting.AccountTypeAsset},
+			{Code: "9000", Name: "Legacy", TagType: accounting.Debit, Type: accounting.AccountTypeUnknown},
+		})
+		assert.ErrorContains(t, err, "chart account 9000: the account type is unknown")
+		assert.Equal(t, len(l.chain.Snapshot().Documents()), documents)
+	})
+}
@@ -0,0 +1,672 @@
+package accounting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// Client talks to a deployed accounting contract. It is created once with
+// the API endpoint and contract account and carries the actor and
+// permission used to authorize the actions it pushes.
+type Client struct {
+	api        *eos.API
+	contract   eos.AccountName
+	actor      eos.AccountName
+	permission eos.PermissionName
+
+	pushOpts PushOptions
+
+	abi *eos.ABI
+	// unsupported holds the actions that don't match abi
+	unsupported map[string]error
+}
+
+// ClientOption configures a Client at construction time
+type ClientOption func(*Client)
+
+// WithActor sets the account that signs and issues actions.
+// Defaults to the contract account.
+func WithActor(actor eos.AccountName) ClientOption {
+	return func(c *Client) {
+		c.actor = actor
+	}
+}
+
+// WithPermission sets the permission used in the actions authorization.
+// Defaults to active.
+func WithPermission(permission eos.PermissionName) ClientOption {
+	return func(c *Client) {
+		c.permission = permission
+	}
+}
+
+// NewClient creates a client for the accounting contract deployed at contract
+func NewClient(api *eos.API, contract eos.AccountName, opts ...ClientOption) (*Client, error) {
+
+	if api == nil {
+		return nil, fmt.Errorf("new client: api is required")
+	}
+
+	if contract == "" {
+		return nil, fmt.Errorf("new client: contract account is required")
+	}
+
+	c := &Client{
+		api:        api,
+		contract:   contract,
+		actor:      contract,
+		permission: eos.PN("active"),
+		pushOpts:   DefaultPushOptions(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.actor == "" {
+		return nil, fmt.Errorf("new client: actor can not be empty")
+	}
+
+	if c.abi != nil {
+		c.unsupported = make(map[string]error)
+		for _, err := range CheckABI(c.abi) {
+
+			var notSupported *ActionNotSupportedError
+
+			if !errors.As(err, &notSupported) {
+				return nil, fmt.Errorf("new client: check abi: %v", err)
+			}
+
+			c.unsupported[notSupported.Action] = err
+		}
+	}
+
+	return c, nil
+}
+
+// As returns a copy of the client that issues actions as actor
+func (c *Client) As(actor eos.AccountName) *Client {
+	clone := *c
+	clone.actor = actor
+	return &clone
+}
+
+// API returns the underlying eos API
+func (c *Client) API() *eos.API {
+	return c.api
+}
+
+// Contract returns the accounting contract account
+func (c *Client) Contract() eos.AccountName {
+	return c.contract
+}
+
+// Actor returns the account issuing the actions
+func (c *Client) Actor() eos.AccountName {
+	return c.actor
+}
+
+// Permission returns the permission used to authorize the actions
+func (c *Client) Permission() eos.PermissionName {
+	return c.permission
+}
+
+func (c *Client) newAction(name string, actor eos.AccountName, data interface{}) *eos.Action {
+	return &eos.Action{
+		Account: c.contract,
+		Name:    eos.ActN(name),
+		Authorization: []eos.PermissionLevel{
+			{Actor: actor, Permission: c.permission},
+		},
+		ActionData: eos.NewActionData(data),
+	}
+}
+
+// Supports returns an ActionNotSupportedError if the client was created
+// with an ABI lacking action
+func (c *Client) Supports(action string) error {
+	return c.unsupported[action]
+}
+
+func (c *Client) exec(ctx context.Context, actions ...*eos.Action) (*TxResult, error) {
+
+	for _, action := range actions {
+		if err := c.Supports(string(action.Name)); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.push(ctx, actions, c.pushOpts)
+}
+
+func (c *Client) addLedgerAction(ledger []docgraph.ContentGroup) *eos.Action {
+	return c.newAction("addledger", c.actor, createLedger{
+		Creator:    c.actor,
+		LedgerInfo: ledger,
+	})
+}
+
+// AddLedger creates a new ledger
+func (c *Client) AddLedger(ctx context.Context, ledger []docgraph.ContentGroup) (*TxResult, error) {
+	return c.exec(ctx, c.addLedgerAction(ledger))
+}
+
+func (c *Client) createAcctAction(account []docgraph.ContentGroup) *eos.Action {
+	return c.newAction("createacc", c.actor, createAccount{
+		Creator:     c.actor,
+		AccountInfo: account,
+	})
+}
+
+// CreateAcct creates an account
+func (c *Client) CreateAcct(ctx context.Context, account []docgraph.ContentGroup) (*TxResult, error) {
+	return c.exec(ctx, c.createAcctAction(account))
+}
+
+func (c *Client) updateaccAction(accountHash eos.Checksum256, accountInfo []docgraph.ContentGroup) *eos.Action {
+	return c.newAction("updateacc", c.actor, updateAccount{
+		Updater:     c.actor,
+		AccountHash: accountHash,
+		AccountInfo: accountInfo,
+	})
+}
+
+// Updateacc updates the variable information of an account
+func (c *Client) Updateacc(ctx context.Context, accountHash eos.Checksum256, accountInfo []docgraph.ContentGroup) (*TxResult, error) {
+	return c.exec(ctx, c.updateaccAction(accountHash, accountInfo))
+}
+
+func (c *Client) deleteaccAction(accountHash eos.Checksum256) *eos.Action {
+	return c.newAction("deleteacc", c.actor, deleteAccount{
+		Deleter:     c.actor,
+		AccountHash: accountHash,
+	})
+}
+
+// Deleteacc deletes a leaf account without components nor balances
+func (c *Client) Deleteacc(ctx context.Context, accountHash eos.Checksum256) (*TxResult, error) {
+	return c.exec(ctx, c.deleteaccAction(accountHash))
+}
+
+func (c *Client) upsertAction(name string, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) *eos.Action {
+	return c.newAction(name, c.actor, upsertTrx{
+		Issuer:  c.actor,
+		TrxHash: trxHash,
+		TrxInfo: trxInfo,
+		Approve: approve,
+	})
+}
+
+// Upserttrx creates a transaction when trxHash is empty or replaces
+// the unapproved transaction trxHash otherwise
+func (c *Client) Upserttrx(ctx context.Context, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (*TxResult, error) {
+	return c.exec(ctx, c.upsertAction("upserttrx", trxHash, trxInfo, approve))
+}
+
+// Crryconvtrx upserts a currency conversion transaction
+func (c *Client) Crryconvtrx(ctx context.Context, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (*TxResult, error) {
+	return c.exec(ctx, c.upsertAction("crryconvtrx", trxHash, trxInfo, approve))
+}
+
+func (c *Client) deletetrxAction(trxHash eos.Checksum256) *eos.Action {
+	return c.newAction("deletetrx", c.actor, deleteTrx{
+		Deleter: c.actor,
+		TrxHash: trxHash,
+	})
+}
+
+// Deletetrx deletes an unapproved transaction
+func (c *Client) Deletetrx(ctx context.Context, trxHash eos.Checksum256) (*TxResult, error) {
+	return c.exec(ctx, c.deletetrxAction(trxHash))
+}
+
+// SetSetting adds or replaces a setting, requires the contract authority
+func (c *Client) SetSetting(ctx context.Context, setting string, value docgraph.FlexValue) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("setsetting", c.contract, setSetting{
+		Setting: setting,
+		Value:   value,
+	}))
+}
+
+// RemSetting removes a setting, requires the contract authority
+func (c *Client) RemSetting(ctx context.Context, setting string) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("remsetting", c.contract, remSetting{
+		Setting: setting,
+	}))
+}
+
+// AddTrustedAccount allows account to modify the ledgers, requires the contract authority
+func (c *Client) AddTrustedAccount(ctx context.Context, account eos.AccountName) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("addtrustacnt", c.contract, trustAccount{
+		Account: account,
+	}))
+}
+
+// RemTrustedAccount revokes the trust of account, requires the contract authority
+func (c *Client) RemTrustedAccount(ctx context.Context, account eos.AccountName) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("remtrustacnt", c.contract, trustAccount{
+		Account: account,
+	}))
+}
+
+func (c *Client) addCurrencyAction(currency string) (*eos.Action, error) {
+
+	symbol, err := eos.StringToSymbol(currency)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding currency: %s", err)
+	}
+
+	return c.newAction("addcurrency", c.actor, addCurrency{
+		Issuer:   c.actor,
+		Currency: symbol,
+	}), nil
+}
+
+// AddCurrency allows currency (i.e. "2,USD") to be used in transactions
+func (c *Client) AddCurrency(ctx context.Context, currency string) (*TxResult, error) {
+
+	action, err := c.addCurrencyAction(currency)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.exec(ctx, action)
+}
+
+func (c *Client) addCoinIdAction(currency, id string) (*eos.Action, error) {
+
+	symbol, err := eos.StringToSymbol(currency)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding coin id: invalid currency %v: %s", currency, err)
+	}
+
+	return c.newAction("addcoinid", c.actor, addCoinid{
+		Issuer:   c.actor,
+		Currency: symbol,
+		Id:       id,
+	}), nil
+}
+
+// AddCoinId attaches an external coin id to an allowed currency
+func (c *Client) AddCoinId(ctx context.Context, currency, id string) (*TxResult, error) {
+
+	action, err := c.addCoinIdAction(currency, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.exec(ctx, action)
+}
+
+func (c *Client) removeCurrencyAction(currency string) (*eos.Action, error) {
+
+	symbol, err := eos.StringToSymbol(currency)
+
+	if err != nil {
+		return nil, fmt.Errorf("error removing currency: invalid currency %v: %s", currency, err)
+	}
+
+	return c.newAction("remcurrency", c.actor, remCurrency{
+		Authorizer: c.actor,
+		Currency:   symbol,
+	}), nil
+}
+
+// RemoveCurrency removes currency from the allowed currencies
+func (c *Client) RemoveCurrency(ctx context.Context, currency string) (*TxResult, error) {
+
+	action, err := c.removeCurrencyAction(currency)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.exec(ctx, action)
+}
+
+func (c *Client) newEventAction(event []docgraph.ContentGroup) *eos.Action {
+	return c.newAction("newevent", c.actor, transact{
+		Issuer:          c.actor,
+		TransactionInfo: event,
+	})
+}
+
+// NewEvent stores an event and updates the cursor of its source
+func (c *Client) NewEvent(ctx context.Context, event []docgraph.ContentGroup) (*TxResult, error) {
+	return c.exec(ctx, c.newEventAction(event))
+}
+
+// CreateRoot creates the root document and the settings of the contract,
+// requires the contract authority
+func (c *Client) CreateRoot(ctx context.Context, notes string) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("createroot", c.contract, createRoot{
+		Notes: notes,
+	}))
+}
+
+func (c *Client) bindEventAction(name string, eventHash, componentHash eos.Checksum256) *eos.Action {
+	return c.newAction(name, c.actor, bindEvent{
+		Updater:       c.actor,
+		EventHash:     eventHash,
+		ComponentHash: componentHash,
+	})
+}
+
+// BindEvent links an event with a component of an unapproved transaction
+func (c *Client) BindEvent(ctx context.Context, eventHash, componentHash eos.Checksum256) (*TxResult, error) {
+	return c.exec(ctx, c.bindEventAction("bindevent", eventHash, componentHash))
+}
+
+// UnbindEvent removes the link between an event and a component of an
+// unapproved transaction
+func (c *Client) UnbindEvent(ctx context.Context, eventHash, componentHash eos.Checksum256) (*TxResult, error) {
+	return c.exec(ctx, c.bindEventAction("unbindevent", eventHash, componentHash))
+}
+
+// ClearEvent erases up to maxRemovableTrx events and cursors,
+// requires the contract authority
+func (c *Client) ClearEvent(ctx context.Context, maxRemovableTrx int64) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("clearevent", c.contract, clearEvent{
+		MaxRemovableTrx: maxRemovableTrx,
+	}))
+}
+
+// CleanTables selects the tables erased by the clean action
+type CleanTables struct {
+	Documents     bool `json:"documents"`
+	Edges         bool `json:"edges"`
+	ExchangeRates bool `json:"exchange_rates"`
+	Cursors       bool `json:"cursors"`
+	// Events clears up to 100 events and cursors through clearevent
+	Events bool `json:"events"`
+}
+
+// ContentGroups returns the tables input of the clean action,
+// the contract requires every flag to be present
+func (t CleanTables) ContentGroups() []docgraph.ContentGroup {
+
+	flag := func(label string, set bool) docgraph.ContentItem {
+		if set {
+			return int64Item(label, 1)
+		}
+		return int64Item(label, 0)
+	}
+
+	return []docgraph.ContentGroup{
+		newGroup(detailsGroup,
+			flag(cleanDocumentsLabel, t.Documents),
+			flag(cleanEdgesLabel, t.Edges),
+			flag(cleanExchangeRatesLabel, t.ExchangeRates),
+			flag(cleanCursorsLabel, t.Cursors),
+			flag(cleanEventsLabel, t.Events),
+		),
+	}
+}
+
+// Clean erases the selected tables, requires the contract authority
+func (c *Client) Clean(ctx context.Context, tables CleanTables) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("clean", c.contract, clean{
+		Tables: tables.ContentGroups(),
+	}))
+}
+
+// Reset erases up to batchSize documents and edges,
+// requires the contract authority
+func (c *Client) Reset(ctx context.Context, batchSize int64) (*TxResult, error) {
+	return c.exec(ctx, c.newAction("reset", c.contract, reset{
+		BatchSize: batchSize,
+	}))
+}
+
+// GetLastCursor returns the last cursor stored in the cursors table
+func (c *Client) GetLastCursor(ctx context.Context) (string, error) {
+
+	var request eos.GetTableRowsRequest
+	request.Code = string(c.contract)
+	request.Scope = string(c.contract)
+	request.Table = "cursors"
+	request.Limit = 1
+	request.Reverse = true
+	request.JSON = true
+	response, err := c.api.GetTableRows(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("get table rows: %v", err)
+	}
+
+	var cursors []CursorRow
+
+	err = response.JSONToStructs(&cursors)
+	if err != nil {
+		return "", fmt.Errorf("json to structs: %v", err)
+	}
+
+	if len(cursors) == 0 {
+		return "", fmt.Errorf("cursor not found: %v", err)
+	}
+
+	return cursors[0].LastCursor, nil
+}
+
+// GetCursorFromSource returns the last cursor stored for source
+func (c *Client) GetCursorFromSource(ctx context.Context, source string) (string, error) {
+
+	hashBytes := sha256.Sum256([]byte(source))
+	hashStr := hex.EncodeToString(hashBytes[:])
+
+	var request eos.GetTableRowsRequest
+	request.Code = string(c.contract)
+	request.Scope = string(c.contract)
+	request.Table = "cursors"
+	request.Index = "2"
+	request.KeyType = "sha256"
+	request.LowerBound = hashStr
+	request.UpperBound = hashStr
+	request.Limit = 1
+	request.Reverse = true
+	request.JSON = true
+	response, err := c.api.GetTableRows(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("get table rows %v: %v", hashStr, err)
+	}
+
+	var cursors []CursorRow
+
+	err = response.JSONToStructs(&cursors)
+	if err != nil {
+		return "", fmt.Errorf("json to structs %v: %v", hashStr, err)
+	}
+
+	if len(cursors) == 0 {
+		return "", fmt.Errorf("cursor not found %v: %v", hashStr, err)
+	}
+
+	return cursors[0].LastCursor, nil
+}
+
+// PrintLedger returns a printable representation of the accounts of ledger
+// and their balances
+//
+// Deprecated: use GetLedgerTree and its renderers
+func (c *Client) PrintLedger(ctx context.Context, ledger docgraph.Document) (string, error) {
+
+	tree, err := c.GetLedgerTree(ctx, ledger.Hash)
+
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+
+	if err := tree.WriteText(&out); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// GetAllEdgesForDocument returns the edges from and to document,
+// keyed by "from" and "to"
+func (c *Client) GetAllEdgesForDocument(ctx context.Context, document docgraph.Document) (map[string][]docgraph.Edge, error) {
+
+	edges := make(map[string][]docgraph.Edge)
+
+	fromEdges, err := docgraph.GetEdgesFromDocument(ctx, c.api, c.contract, document)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve from edges: %v", err)
+	}
+
+	edges["from"] = fromEdges
+
+	toEdges, err := docgraph.GetEdgesToDocument(ctx, c.api, c.contract, document)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve to edges: %v", err)
+	}
+
+	edges["to"] = toEdges
+
+	return edges, nil
+}
+
+// GetTrxNodeInfo returns a transaction document with its edges and components
+func (c *Client) GetTrxNodeInfo(ctx context.Context, transaction docgraph.Document) (TrxNodeInfo, error) {
+
+	trxEdges, err := c.GetAllEdgesForDocument(ctx, transaction)
+
+	if err != nil {
+		return TrxNodeInfo{}, err
+	}
+
+	fromEdges := trxEdges["from"]
+	var components []ComponentNodeInfo
+
+	for _, edge := range fromEdges {
+
+		if edge.EdgeName == "component" {
+			comptDoc, err := docgraph.LoadDocument(ctx, c.api, c.contract, edge.ToNode.String())
+
+			if err != nil {
+				return TrxNodeInfo{}, err
+			}
+
+			comptEdges, err := c.GetAllEdgesForDocument(ctx, comptDoc)
+
+			if err != nil {
+				return TrxNodeInfo{}, err
+			}
+
+			components = append(components, ComponentNodeInfo{
+				ComponentNode: comptDoc,
+				Edges:         comptEdges,
+			})
+		}
+
+	}
+
+	return TrxNodeInfo{
+		TrxNode:    transaction,
+		Edges:      trxEdges,
+		Components: components,
+	}, nil
+}
+
+// GetSettings reads the settings document of the contract
+func (c *Client) GetSettings(ctx context.Context) (Settings, error) {
+
+	settingsDoc, err := docgraph.GetLastDocumentOfEdge(ctx, c.api, c.contract, settingsEdge)
+
+	if err != nil {
+		return Settings{}, err
+	}
+
+	return SettingsFromDocument(settingsDoc)
+}
+
+// GetAllowedCurrencies returns the currencies allowed in transactions
+func (c *Client) GetAllowedCurrencies(ctx context.Context) ([]eos.Symbol, error) {
+
+	settingsDoc, err := docgraph.GetLastDocumentOfEdge(ctx, c.api, c.contract, "settings")
+
+	if err != nil {
+		return []eos.Symbol{}, err
+	}
+
+	allowedCurrenciesGroup, err := settingsDoc.GetContentGroup("allowed_currencies")
+
+	if err != nil {
+		return []eos.Symbol{}, err
+	}
+
+	var allowedCurrencies []eos.Symbol
+
+	for _, currency := range *allowedCurrenciesGroup {
+
+		if currency.Label == "allowed_currency" {
+			currencyAsset, err := currency.Value.Asset()
+
+			if err != nil {
+				return []eos.Symbol{}, err
+			}
+
+			allowedCurrencies = append(allowedCurrencies, currencyAsset.Symbol)
+		}
+	}
+
+	return allowedCurrencies, nil
+}
+
+// GetCoinIds returns the external coin ids of the allowed currencies
+func (c *Client) GetCoinIds(ctx context.Context) ([]string, error) {
+
+	settingsDoc, err := docgraph.GetLastDocumentOfEdge(ctx, c.api, c.contract, "settings")
+
+	if err != nil {
+		return []string{}, err
+	}
+
+	allowedCurrenciesGroup, err := settingsDoc.GetContentGroup("allowed_currencies")
+
+	if err != nil {
+		return []string{}, err
+	}
+
+	var coinIds []string
+
+	for _, currency := range *allowedCurrenciesGroup {
+
+		if strings.Contains(currency.Label, "_ID") {
+			coinIds = append(coinIds, currency.Value.String())
+		}
+	}
+
+	return coinIds, nil
+}
+
+// GetExchangeRates returns the exchange rates stored from currency from to currency to
+func (c *Client) GetExchangeRates(ctx context.Context, from, to string) ([]ExRateRow, error) {
+
+	rows := []ExRateRow{}
+
+	err := c.ScanExchangeRates(ctx, from, to, func(rate ExRateRow) error {
+		rows = append(rows, rate)
+		return nil
+	})
+
+	if err != nil {
+		return []ExRateRow{}, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,27 @@
+package accounting_test
+
+import (
+	"testing"
+
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func TestCleanTables(t *testing.T) {
+
+	tables := accounting.CleanTables{Edges: true, Events: true}.ContentGroups()
+
+	assert.Equal(t, len(tables), 1)
+	assert.DeepEqual(t, contentLabels(tables[0]), []string{
+		"content_group_label", "documents", "edges", "exchange_rates", "cursors", "events",
+	})
+
+	flags := make(map[string]int64)
+	for _, item := range tables[0][1:] {
+		flags[item.Label] = item.Value.Impl.(int64)
+	}
+
+	assert.DeepEqual(t, flags, map[string]int64{
+		"documents": 0, "edges": 1, "exchange_rates": 0, "cursors": 0, "events": 1,
+	})
+}
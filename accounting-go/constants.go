@@ -0,0 +1,105 @@
+package accounting
+
+// Labels and edge names used by the accounting contract,
+// they mirror include/constants.hpp
+const (
+	contentGroupLabel = "content_group_label"
+
+	detailsGroup           = "details"
+	balancesGroup          = "balances"
+	systemGroup            = "system"
+	settingsDataGroup      = "settings_data"
+	trustedAccountsGroup   = "trusted_accounts"
+	allowedCurrenciesGroup = "allowed_currencies"
+	componentGroup         = "component"
+
+	nodeLabel = "node_label"
+	typeLabel = "type"
+
+	ledgerNameLabel  = "name"
+	ledgerOwnerLabel = "owner"
+
+	accountNameLabel    = "account_name"
+	accountTypeLabel    = "account_type"
+	accountTagTypeLabel = "account_tag_type"
+	accountCodeLabel    = "account_code"
+	isLeafLabel         = "is_leaf"
+	parentAccountLabel  = "parent_account"
+	ledgerAccountLabel  = "ledger_account"
+	accountFixedLabel   = "account_fixed"
+	accountCodesLabel   = "ACCOUNT_CODE"
+
+	trxIDLabel       = "id"
+	trxMemoLabel     = "trx_memo"
+	trxNameLabel     = "trx_name"
+	trxNotesLabel    = "trx_notes"
+	trxDateLabel     = "trx_date"
+	trxLedgerLabel   = "trx_ledger"
+	trxApproverLabel = "approved_by"
+
+	// trxConversionLabel is set to 1 by crryconvtrx
+	trxConversionLabel = "currency_conversion"
+
+	componentAccountLabel = "account"
+	componentAmountLabel  = "amount"
+	componentMemoLabel    = "memo"
+	componentFromLabel    = "from"
+	componentToLabel      = "to"
+	componentTypeLabel    = "type"
+	componentDateLabel    = "create_date"
+	componentEventLabel   = "event"
+
+	accountBalancePrefix = "account_"
+	globalBalancePrefix  = "global_"
+	balanceIDLabel       = "balance_id"
+	numberOfUpdatesLabel = "number_of_updates"
+	createDateLabel      = "create_date"
+
+	eventSourceLabel = "source"
+	eventCursorLabel = "cursor"
+
+	rootNodeLabel        = "root_node"
+	trustedAccountLabel  = "trusted_account"
+	allowedCurrencyLabel = "allowed_currency"
+	coinIDSuffix         = "_ID"
+
+	cleanDocumentsLabel     = "documents"
+	cleanEdgesLabel         = "edges"
+	cleanExchangeRatesLabel = "exchange_rates"
+	cleanCursorsLabel       = "cursors"
+	cleanEventsLabel        = "events"
+)
+
+// Edge names
+const (
+	ledgerEdge           = "ledger"
+	accountEdge          = "account"
+	accountVariableEdge  = "accountv"
+	balancesEdge         = "balances"
+	ownedByEdge          = "ownedby"
+	componentEdge        = "component"
+	transactionEdge      = "transaction"
+	componentAccountEdge = "cmpacct"
+	accountComponentEdge = "acctcmp"
+	approvedEdge         = "approved"
+	unapprovedEdge       = "unapproved"
+	trxBucketEdge        = "trxbucket"
+	eventBucketEdge      = "eventbucket"
+	eventEdge            = "event"
+	settingsEdge         = "settings"
+	accountCodesEdge     = "acctcodes"
+)
+
+// Document types, the type item of the system group
+const (
+	rootDocumentType        = "root_node"
+	accountDocumentType     = "account"
+	transactionDocumentType = "transaction"
+	componentDocumentType   = "component"
+)
+
+// Component and account tag types
+const (
+	Debit  = "DEBIT"
+	Credit = "CREDIT"
+)
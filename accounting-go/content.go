@@ -0,0 +1,265 @@
+package accounting
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+func newFlexValue(typeName string, impl interface{}) *docgraph.FlexValue {
+	return &docgraph.FlexValue{
+		BaseVariant: eos.BaseVariant{
+			TypeID: docgraph.GetVariants().TypeID(typeName),
+			Impl:   impl,
+		}}
+}
+
+func newItem(label, typeName string, impl interface{}) docgraph.ContentItem {
+	return docgraph.ContentItem{
+		Label: label,
+		Value: newFlexValue(typeName, impl),
+	}
+}
+
+func stringItem(label, value string) docgraph.ContentItem {
+	return newItem(label, "string", value)
+}
+
+func int64Item(label string, value int64) docgraph.ContentItem {
+	return newItem(label, "int64", value)
+}
+
+func assetItem(label string, value eos.Asset) docgraph.ContentItem {
+	return newItem(label, "asset", value)
+}
+
+func checksumItem(label string, value eos.Checksum256) docgraph.ContentItem {
+	return newItem(label, "checksum256", value)
+}
+
+func timePointItem(label string, value eos.TimePoint) docgraph.ContentItem {
+	return newItem(label, "time_point", value)
+}
+
+func nameItem(label string, value eos.Name) docgraph.ContentItem {
+	return newItem(label, "name", value)
+}
+
+// newGroup creates a content group whose first item is its label
+func newGroup(label string, items ...docgraph.ContentItem) docgraph.ContentGroup {
+	group := docgraph.ContentGroup{stringItem(contentGroupLabel, label)}
+	return append(group, items...)
+}
+
+func groupLabel(group docgraph.ContentGroup) string {
+	for _, item := range group {
+		if item.Label == contentGroupLabel && item.Value != nil {
+			if label, err := flexString(item.Value); err == nil {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
+func findGroup(groups []docgraph.ContentGroup, label string) (docgraph.ContentGroup, bool) {
+	for _, group := range groups {
+		if groupLabel(group) == label {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
+func findItem(group docgraph.ContentGroup, label string) (*docgraph.FlexValue, bool) {
+	for _, item := range group {
+		if item.Label == label && item.Value != nil {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func flexString(value *docgraph.FlexValue) (string, error) {
+	switch v := value.Impl.(type) {
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("expected string, found %T", value.Impl)
+	}
+}
+
+func flexInt64(value *docgraph.FlexValue) (int64, error) {
+	switch v := value.Impl.(type) {
+	case int64:
+		return v, nil
+	case eos.Int64:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("expected int64, found %T", value.Impl)
+	}
+}
+
+func flexAsset(value *docgraph.FlexValue) (eos.Asset, error) {
+	switch v := value.Impl.(type) {
+	case eos.Asset:
+		return v, nil
+	case *eos.Asset:
+		return *v, nil
+	case string:
+		return eos.NewAssetFromString(v)
+	default:
+		return eos.Asset{}, fmt.Errorf("expected asset, found %T", value.Impl)
+	}
+}
+
+func flexChecksum(value *docgraph.FlexValue) (eos.Checksum256, error) {
+	switch v := value.Impl.(type) {
+	case eos.Checksum256:
+		return v, nil
+	case string:
+		return parseChecksum(v)
+	default:
+		return nil, fmt.Errorf("expected checksum256, found %T", value.Impl)
+	}
+}
+
+func flexTimePoint(value *docgraph.FlexValue) (eos.TimePoint, error) {
+	switch v := value.Impl.(type) {
+	case eos.TimePoint:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("expected time_point, found %T", value.Impl)
+	}
+}
+
+func flexName(value *docgraph.FlexValue) (eos.Name, error) {
+	switch v := value.Impl.(type) {
+	case eos.Name:
+		return v, nil
+	case eos.AccountName:
+		return eos.Name(v), nil
+	case string:
+		return eos.Name(v), nil
+	default:
+		return "", fmt.Errorf("expected name, found %T", value.Impl)
+	}
+}
+
+// contentReader decodes the items of a content group keeping the first error
+type contentReader struct {
+	group docgraph.ContentGroup
+	err   error
+}
+
+func (r *contentReader) fail(label string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%v: %v", label, err)
+	}
+}
+
+func (r *contentReader) value(label string, required bool) *docgraph.FlexValue {
+	value, ok := findItem(r.group, label)
+	if !ok && required {
+		r.fail(label, fmt.Errorf("missing content in %v group", groupLabel(r.group)))
+	}
+	return value
+}
+
+func (r *contentReader) text(label string, required bool) string {
+	value := r.value(label, required)
+	if value == nil {
+		return ""
+	}
+	s, err := flexString(value)
+	if err != nil {
+		r.fail(label, err)
+	}
+	return s
+}
+
+func (r *contentReader) integer(label string, required bool) int64 {
+	value := r.value(label, required)
+	if value == nil {
+		return 0
+	}
+	i, err := flexInt64(value)
+	if err != nil {
+		r.fail(label, err)
+	}
+	return i
+}
+
+func (r *contentReader) asset(label string, required bool) eos.Asset {
+	value := r.value(label, required)
+	if value == nil {
+		return eos.Asset{}
+	}
+	a, err := flexAsset(value)
+	if err != nil {
+		r.fail(label, err)
+	}
+	return a
+}
+
+func (r *contentReader) checksum(label string, required bool) eos.Checksum256 {
+	value := r.value(label, required)
+	if value == nil {
+		return nil
+	}
+	h, err := flexChecksum(value)
+	if err != nil {
+		r.fail(label, err)
+	}
+	return h
+}
+
+func (r *contentReader) timePoint(label string, required bool) eos.TimePoint {
+	value := r.value(label, required)
+	if value == nil {
+		return 0
+	}
+	tp, err := flexTimePoint(value)
+	if err != nil {
+		r.fail(label, err)
+	}
+	return tp
+}
+
+func (r *contentReader) name(label string, required bool) eos.Name {
+	value := r.value(label, required)
+	if value == nil {
+		return ""
+	}
+	n, err := flexName(value)
+	if err != nil {
+		r.fail(label, err)
+	}
+	return n
+}
+
+func parseChecksum(hash string) (eos.Checksum256, error) {
+	data, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum256 %v: %v", hash, err)
+	}
+	if len(data) != 32 {
+		return nil, fmt.Errorf("invalid checksum256 %v: expected 32 bytes, found %v", hash, len(data))
+	}
+	return eos.Checksum256(data), nil
+}
+
+// TimePointOf converts t to a time_point
+func TimePointOf(t time.Time) eos.TimePoint {
+	return eos.TimePoint(t.UnixNano() / int64(time.Microsecond))
+}
+
+// TimeOf converts a time_point to a UTC time
+func TimeOf(tp eos.TimePoint) time.Time {
+	return time.Unix(0, int64(tp)*int64(time.Microsecond)).UTC()
+}
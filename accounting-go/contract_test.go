@@ -0,0 +1,934 @@
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+// trxTestLedger is the ledger of the transaction tests on a Chain
+//
+//	000114 Expenses
+//	  000111 Marketing
+//	  000122 Development
+//	000113 Income
+//	  000115 Salary
+//	  000123 Sales
+//
+// with USD and HUSD as allowed currencies.
+type trxTestLedger struct {
+	chain    accounting.Chain
+	ledger   eos.Checksum256
+	accounts map[string]eos.Checksum256
+}
+
+// newFakeTrxChain creates a fake chain with a root, issuing the actions as
+// a trusted account like the nodeos environment
+func newFakeTrxChain(t *testing.T) *accounting.FakeChain {
+
+	ctx := context.Background()
+
+	chain := accounting.NewFakeChain(eos.AN("accounting"))
+
+	_, err := chain.CreateRoot(ctx, "notes")
+	assert.NilError(t, err)
+
+	_, err = chain.AddTrustedAccount(ctx, eos.AN("authacct1111"))
+	assert.NilError(t, err)
+
+	return chain.As(eos.AN("authacct1111"))
+}
+
+// newTrxTestLedger creates the ledger of the transaction tests on chain,
+// which must have a root and trust its actor
+func newTrxTestLedger(t *testing.T, chain accounting.Chain) *trxTestLedger {
+
+	fixture, err := accounting.LoadFixture("testdata/trx_test_info.yaml")
+	assert.NilError(t, err)
+
+	hashes, err := fixture.Apply(context.Background(), chain)
+	assert.NilError(t, err)
+
+	l := &trxTestLedger{
+		chain:    chain,
+		ledger:   hashes["common"],
+		accounts: make(map[string]eos.Checksum256),
+	}
+
+	for _, name := range []string{"Expenses", "Income", "Marketing", "Development", "Salary", "Sales"} {
+		l.accounts[name] = hashes[name]
+	}
+
+	return l
+}
+
+// newFakeTrxTestLedger creates the ledger of the transaction tests on a
+// new fake chain
+func newFakeTrxTestLedger(t *testing.T) (*trxTestLedger, *accounting.FakeChain) {
+	chain := newFakeTrxChain(t)
+	return newTrxTestLedger(t, chain), chain
+}
+
+// createAccount creates the account name under the account named parent,
+// or under the ledger when parent is empty
+func (l *trxTestLedger) createAccount(t *testing.T, parent, code, name, tagType string) eos.Checksum256 {
+
+	hash, err := l.tryCreateAccount(parent, code, name, tagType)
+	assert.NilError(t, err)
+
+	return hash
+}
+
+func (l *trxTestLedger) tryCreateAccount(parent, code, name, tagType string) (eos.Checksum256, error) {
+
+	ctx := context.Background()
+
+	parentHash := l.ledger
+	if parent != "" {
+		parentHash = l.accounts[parent]
+	}
+
+	_, err := l.chain.CreateAcct(ctx, accounting.Account{
+		Name:    name,
+		Code:    code,
+		TagType: tagType,
+		Type:    accounting.AccountTypeLiability,
+		Parent:  parentHash,
+		Ledger:  l.ledger,
+	}.ContentGroups())
+
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := accounting.ChildAccounts(ctx, l.chain, parentHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		if child.Code == code {
+			l.accounts[name] = child.Hash
+			return child.Hash, nil
+		}
+	}
+
+	return nil, fmt.Errorf("account %v not found", code)
+}
+
+// component debits or credits the account named account with amount
+func (l *trxTestLedger) component(t *testing.T, account, amount, tagType string) accounting.Component {
+
+	asset, err := eos.NewAssetFromString(amount)
+	assert.NilError(t, err)
+
+	return accounting.Component{
+		Account: l.accounts[account],
+		Amount:  asset,
+		Memo:    "Test component",
+		From:    "test_from",
+		To:      "test_to",
+		Type:    tagType,
+	}
+}
+
+// trx encodes the components without the builder checks so the contract
+// validations can be tested
+func (l *trxTestLedger) trx(components ...accounting.Component) []docgraph.ContentGroup {
+	return accounting.Transaction{
+		Ledger:     l.ledger,
+		Date:       accounting.TimePointOf(time.Date(2020, 12, 17, 21, 45, 11, 500000000, time.UTC)),
+		Memo:       "Test transaction",
+		Name:       "transaction name",
+		Components: components,
+	}.ContentGroups()
+}
+
+// transactions returns the transactions of the ledger ordered by id, with
+// their components
+func (l *trxTestLedger) transactions(t *testing.T) []accounting.Transaction {
+
+	ctx := context.Background()
+
+	buckets, err := l.chain.EdgesFrom(ctx, l.ledger, "trxbucket")
+	assert.NilError(t, err)
+
+	var transactions []accounting.Transaction
+
+	for _, bucket := range buckets {
+		for _, edgeName := range []string{"approved", "unapproved"} {
+
+			edges, err := l.chain.EdgesFrom(ctx, bucket.ToNode, edgeName)
+			assert.NilError(t, err)
+
+			for _, edge := range edges {
+				document, err := l.chain.Document(ctx, edge.ToNode)
+				assert.NilError(t, err)
+				trx, err := accounting.TransactionFromDocument(document)
+				assert.NilError(t, err)
+
+				components, err := l.chain.EdgesFrom(ctx, trx.Hash, "component")
+				assert.NilError(t, err)
+
+				for _, component := range components {
+					document, err := l.chain.Document(ctx, component.ToNode)
+					assert.NilError(t, err)
+					decoded, err := accounting.ComponentFromDocument(document)
+					assert.NilError(t, err)
+					trx.Components = append(trx.Components, decoded)
+				}
+
+				transactions = append(transactions, trx)
+			}
+		}
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	return transactions
+}
+
+// balances returns the balances of the account named name formatted as
+// [account_USD:1000.00 USD] and [global_USD:1000.00 USD], sorted
+func (l *trxTestLedger) balances(t *testing.T, name string) []string {
+
+	tree, err := accounting.ReadLedgerTree(context.Background(), l.chain, l.ledger)
+	assert.NilError(t, err)
+
+	node := tree.Find(name)
+	assert.Assert(t, node != nil, "account %v not found", name)
+
+	balances := []string{}
+
+	if node.Balances != nil {
+		for symbol, asset := range node.Balances.Account {
+			balances = append(balances, fmt.Sprintf("[account_%v:%v]", symbol, asset))
+		}
+		for symbol, asset := range node.Balances.Global {
+			balances = append(balances, fmt.Sprintf("[global_%v:%v]", symbol, asset))
+		}
+	}
+
+	sort.Strings(balances)
+
+	return balances
+}
+
+func (l *trxTestLedger) assertBalances(t *testing.T, name string, expected ...string) {
+	t.Helper()
+	if expected == nil {
+		expected = []string{}
+	}
+	sort.Strings(expected)
+	assert.DeepEqual(t, l.balances(t, name), expected)
+}
+
+// assertEdges checks the number of edges leaving from and arriving to hash
+func assertEdges(t *testing.T, chain *accounting.FakeChain, hash eos.Checksum256, from, to int) {
+	t.Helper()
+	snapshot := chain.Snapshot()
+	assert.Equal(t, len(snapshot.AllEdgesFrom(hash)), from)
+	assert.Equal(t, len(snapshot.AllEdgesTo(hash)), to)
+}
+
+func allowedCurrencies(t *testing.T, chain accounting.Chain) []string {
+
+	settings, err := chain.GetSettings(context.Background())
+	assert.NilError(t, err)
+
+	var codes []string
+	for _, symbol := range settings.AllowedCurrencies {
+		codes = append(codes, symbol.Symbol)
+	}
+
+	return codes
+}
+
+func TestCreateacc(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("Test create account successfully", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		account, err := accounting.LoadAccount(ctx, l.chain, l.accounts["Expenses"])
+		assert.NilError(t, err)
+		assert.Equal(t, account.Name, "Expenses")
+		assert.Equal(t, account.Code, "000114")
+		assert.Equal(t, account.Type, accounting.AccountTypeLiability)
+		assert.Assert(t, !account.IsLeaf)
+	})
+
+	t.Run("Test create tree of accounts", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		parent := "Development"
+		for i := 0; i < 10; i++ {
+			name := fmt.Sprintf("Marketing %v", i)
+			l.createAccount(t, parent, fmt.Sprint(i), name, accounting.Debit)
+			parent = name
+		}
+
+		deep, err := accounting.LoadAccount(ctx, l.chain, l.accounts[parent])
+		assert.NilError(t, err)
+		assert.Equal(t, deep.Code, "9")
+		assert.Assert(t, deep.IsLeaf)
+
+		tree, err := accounting.ReadLedgerTree(ctx, l.chain, l.ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, tree.Find(parent).Depth, 11)
+	})
+
+	t.Run("Test only unused accounts can have children", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+		), true)
+		assert.NilError(t, err)
+
+		_, err = l.tryCreateAccount("Marketing", "000116", "Campaigns", accounting.Debit)
+		assert.ErrorContains(t, err, "Parent account already has associated components. Parent hash: "+l.accounts["Marketing"].String())
+
+		var hasComponents *accounting.ErrHasComponents
+		assert.Assert(t, errors.As(err, &hasComponents), err)
+	})
+
+	t.Run("Account codes must be unique", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		l.createAccount(t, "", "000116", "Assets", accounting.Debit)
+
+		_, err := l.tryCreateAccount("", "000116", "Equity", accounting.Credit)
+		assert.ErrorContains(t, err, "account code 000116 already exists")
+
+		var duplicate *accounting.ErrDuplicateAccountCode
+		assert.Assert(t, errors.As(err, &duplicate), err)
+	})
+}
+
+func TestUpdateacc(t *testing.T) {
+
+	ctx := context.Background()
+
+	update := func(t *testing.T, l *trxTestLedger, name string) accounting.Account {
+
+		groups, err := StrToContentGroups(account_expenses_update)
+		assert.NilError(t, err)
+
+		_, err = l.chain.Updateacc(ctx, l.accounts[name], groups)
+		assert.NilError(t, err)
+
+		account, err := accounting.LoadAccount(ctx, l.chain, l.accounts[name])
+		assert.NilError(t, err)
+
+		return account
+	}
+
+	t.Run("Test update leaf account successfully", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		account := update(t, l, "Marketing")
+		assert.Equal(t, account.Name, "Expenses Updated")
+		assert.Equal(t, account.Code, "000111")
+		assert.Assert(t, account.IsLeaf)
+	})
+
+	t.Run("Update parent account successfully", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		account := update(t, l, "Expenses")
+		assert.Equal(t, account.Name, "Expenses Updated")
+		assert.Assert(t, !account.IsLeaf)
+
+		children, err := accounting.ChildAccounts(ctx, l.chain, l.accounts["Expenses"])
+		assert.NilError(t, err)
+		assert.Equal(t, len(children), 2)
+	})
+}
+
+func TestDeleteacc(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("Test delete leaf account successfully", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		hash := l.createAccount(t, "", "000116", "Assets", accounting.Debit)
+
+		_, err := l.chain.Deleteacc(ctx, hash)
+		assert.NilError(t, err)
+
+		_, err = l.chain.Document(ctx, hash)
+		assert.ErrorContains(t, err, "document not found")
+
+		children, err := accounting.ChildAccounts(ctx, l.chain, l.ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, len(children), 2)
+	})
+
+	t.Run("Test delete leafs in a tree successfully", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		for _, name := range []string{"Development", "Marketing"} {
+			_, err := l.chain.Deleteacc(ctx, l.accounts[name])
+			assert.NilError(t, err)
+
+			_, err = l.chain.Document(ctx, l.accounts[name])
+			assert.ErrorContains(t, err, "document not found")
+		}
+
+		expenses, err := accounting.LoadAccount(ctx, l.chain, l.accounts["Expenses"])
+		assert.NilError(t, err)
+		assert.Assert(t, expenses.IsLeaf)
+	})
+
+	t.Run("Test delete non leaf accounts (failure expected)", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Deleteacc(ctx, l.accounts["Expenses"])
+		assert.ErrorContains(t, err, "The account "+l.accounts["Expenses"].String()+" is not a leaf")
+
+		var notLeaf *accounting.ErrNotLeaf
+		assert.Assert(t, errors.As(err, &notLeaf), err)
+	})
+
+	t.Run("Test delete account with associated components (failure expected)", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+			l.component(t, "Salary", "500.00 HUSD", accounting.Debit),
+			l.component(t, "Development", "500.00 HUSD", accounting.Credit),
+		), true)
+		assert.NilError(t, err)
+
+		_, err = l.chain.Deleteacc(ctx, l.accounts["Salary"])
+		assert.ErrorContains(t, err, "The account "+l.accounts["Salary"].String()+" already has associated components")
+
+		var hasComponents *accounting.ErrHasComponents
+		assert.Assert(t, errors.As(err, &hasComponents), err)
+	})
+}
+
+func TestAddcurrency(t *testing.T) {
+
+	t.Run("Test add currency successfully", func(t *testing.T) {
+
+		chain := newFakeTrxChain(t)
+
+		for _, currency := range []string{"1,USD", "2,BTC", "2,ETH", "3,HUSD", "4,WAX", "5,SEEDS", "6,TLOS", "7,EOS"} {
+			_, err := chain.AddCurrency(context.Background(), currency)
+			assert.NilError(t, err)
+		}
+
+		assert.DeepEqual(t, allowedCurrencies(t, chain), []string{"USD", "BTC", "ETH", "HUSD", "WAX", "SEEDS", "TLOS", "EOS"})
+	})
+
+	t.Run("Currencies are only added once", func(t *testing.T) {
+
+		chain := newFakeTrxChain(t)
+
+		_, err := chain.AddCurrency(context.Background(), "2,USD")
+		assert.NilError(t, err)
+
+		_, err = chain.AddCurrency(context.Background(), "4,USD")
+		assert.Assert(t, errors.Is(err, accounting.ErrDuplicateCurrency), err)
+	})
+}
+
+func TestAddcoinid(t *testing.T) {
+
+	t.Run("An authorized account can add an id to an allowed currency", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		chain := newFakeTrxChain(t)
+
+		for _, currency := range []string{"1,USD", "2,BTC", "2,ETH", "3,HUSD", "5,SEEDS"} {
+			_, err := chain.AddCurrency(ctx, currency)
+			assert.NilError(t, err)
+		}
+
+		_, err := chain.AddCoinId(ctx, "5,BTC", "bitcoin")
+		assert.NilError(t, err)
+
+		_, err = chain.AddCoinId(ctx, "5,BTC", "ethereum")
+		assert.NilError(t, err)
+
+		settings, err := chain.LastDocumentOfEdge(ctx, "settings")
+		assert.NilError(t, err)
+
+		var ids []string
+		for _, group := range settings.ContentGroups {
+			for _, item := range group {
+				if strings.HasSuffix(item.Label, "_ID") {
+					ids = append(ids, item.Value.String())
+				}
+			}
+		}
+
+		assert.DeepEqual(t, ids, []string{"bitcoin", "ethereum"})
+
+		_, err = chain.AddCoinId(ctx, "2,WAX", "wax")
+		assert.ErrorContains(t, err, "There is no allowed currency with code WAX.")
+	})
+}
+
+func TestRemcurrency(t *testing.T) {
+
+	t.Run("Test remove currency successfully", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		chain := newFakeTrxChain(t)
+
+		for _, currency := range []string{"1,USD", "2,BTC", "2,ETH", "3,HUSD", "4,WAX", "5,SEEDS", "6,TLOS", "7,EOS"} {
+			_, err := chain.AddCurrency(ctx, currency)
+			assert.NilError(t, err)
+		}
+
+		for _, currency := range []string{"3,BTC", "3,WAX", "3,SEEDS"} {
+			_, err := chain.RemoveCurrency(ctx, currency)
+			assert.NilError(t, err)
+		}
+
+		assert.DeepEqual(t, allowedCurrencies(t, chain), []string{"USD", "ETH", "HUSD", "TLOS", "EOS"})
+
+		_, err := chain.RemoveCurrency(ctx, "3,BTC")
+		assert.ErrorContains(t, err, "There is no allowed currency with code BTC.")
+	})
+}
+
+func TestTrustedAccounts(t *testing.T) {
+
+	ctx := context.Background()
+
+	chain := accounting.NewFakeChain(eos.AN("accounting"))
+
+	_, err := chain.CreateRoot(ctx, "notes")
+	assert.NilError(t, err)
+
+	member := chain.As(eos.AN("member"))
+
+	_, err = member.AddCurrency(ctx, "2,USD")
+	assert.Assert(t, errors.Is(err, accounting.ErrNotTrusted), err)
+
+	_, err = chain.AddTrustedAccount(ctx, eos.AN("member"))
+	assert.NilError(t, err)
+
+	_, err = chain.AddTrustedAccount(ctx, eos.AN("member"))
+	assert.ErrorContains(t, err, "Account is trusted already")
+
+	_, err = member.AddCurrency(ctx, "2,USD")
+	assert.NilError(t, err)
+
+	_, err = chain.RemTrustedAccount(ctx, eos.AN("member"))
+	assert.NilError(t, err)
+
+	_, err = member.AddCurrency(ctx, "2,HUSD")
+	assert.Assert(t, errors.Is(err, accounting.ErrNotTrusted), err)
+
+	settings, err := chain.GetSettings(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, !settings.IsTrusted(eos.AN("member")))
+	assert.DeepEqual(t, allowedCurrencies(t, chain), []string{"USD"})
+}
+
+func TestDeletetrx(t *testing.T) {
+
+	t.Run("Test delete unapproved transaction", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Salary", "1000.00 USD", accounting.Debit),
+		), false)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+
+		_, err = l.chain.Deletetrx(ctx, transactions[0].Hash)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(l.transactions(t)), 0)
+
+		_, err = l.chain.Document(ctx, transactions[0].Components[0].Hash)
+		assert.ErrorContains(t, err, "document not found")
+	})
+}
+
+func TestUpserttrx(t *testing.T) {
+
+	ctx := context.Background()
+
+	assertTransaction := func(t *testing.T, chain *accounting.FakeChain, trx accounting.Transaction, id int64, components int) {
+		t.Helper()
+		assert.Equal(t, trx.Memo, "Test transaction")
+		assert.Equal(t, trx.Name, "transaction name")
+		assert.Equal(t, trx.ID, id)
+		assert.Equal(t, len(trx.Components), components)
+		assertEdges(t, chain, trx.Hash, components+1, components+1)
+	}
+
+	t.Run("Test insert without approval", func(t *testing.T) {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+			l.component(t, "Salary", "500.00 HUSD", accounting.Debit),
+			l.component(t, "Development", "500.00 HUSD", accounting.Credit),
+		), false)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assertTransaction(t, chain, transactions[0], 1, 4)
+		assert.Assert(t, !transactions[0].Approved())
+
+		for _, name := range []string{"Development", "Sales", "Marketing", "Salary"} {
+			l.assertBalances(t, name)
+		}
+	})
+
+	t.Run("Test insert with approval", func(t *testing.T) {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+			l.component(t, "Salary", "500.00 HUSD", accounting.Debit),
+			l.component(t, "Development", "500.00 HUSD", accounting.Credit),
+		), true)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assertTransaction(t, chain, transactions[0], 1, 4)
+		assert.Assert(t, transactions[0].Approved())
+
+		l.assertBalances(t, "Income", "[global_USD:-1000.00 USD]", "[global_HUSD:500.00 HUSD]")
+		l.assertBalances(t, "Sales", "[account_USD:-1000.00 USD]", "[global_USD:-1000.00 USD]")
+		l.assertBalances(t, "Salary", "[account_HUSD:500.00 HUSD]", "[global_HUSD:500.00 HUSD]")
+		l.assertBalances(t, "Expenses", "[global_HUSD:-500.00 HUSD]", "[global_USD:1000.00 USD]")
+		l.assertBalances(t, "Development", "[account_HUSD:-500.00 HUSD]", "[global_HUSD:-500.00 HUSD]")
+		l.assertBalances(t, "Marketing", "[account_USD:1000.00 USD]", "[global_USD:1000.00 USD]")
+
+		for _, currency := range []string{"8,BTC", "4,TLOS"} {
+			_, err = l.chain.AddCurrency(ctx, currency)
+			assert.NilError(t, err)
+		}
+
+		_, err = l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "100.000 USD", accounting.Credit),
+			l.component(t, "Salary", "80.000 USD", accounting.Debit),
+			l.component(t, "Sales", "20.000 USD", accounting.Debit),
+			l.component(t, "Marketing", "0.00100000 BTC", accounting.Credit),
+			l.component(t, "Sales", "0.00100000 BTC", accounting.Debit),
+			l.component(t, "Marketing", "50.0000 TLOS", accounting.Credit),
+			l.component(t, "Sales", "50.0000 TLOS", accounting.Debit),
+		), true)
+		assert.NilError(t, err)
+
+		transactions = l.transactions(t)
+		assert.Equal(t, len(transactions), 2)
+		assertTransaction(t, chain, transactions[1], 2, 7)
+
+		l.assertBalances(t, "Income",
+			"[global_USD:-900.000 USD]", "[global_HUSD:500.00 HUSD]", "[global_BTC:0.00100000 BTC]", "[global_TLOS:50.0000 TLOS]")
+		l.assertBalances(t, "Sales",
+			"[account_USD:-980.000 USD]", "[global_USD:-980.000 USD]",
+			"[account_BTC:0.00100000 BTC]", "[global_BTC:0.00100000 BTC]",
+			"[account_TLOS:50.0000 TLOS]", "[global_TLOS:50.0000 TLOS]")
+		l.assertBalances(t, "Salary",
+			"[account_HUSD:500.00 HUSD]", "[global_HUSD:500.00 HUSD]", "[account_USD:80.000 USD]", "[global_USD:80.000 USD]")
+		l.assertBalances(t, "Expenses",
+			"[global_HUSD:-500.00 HUSD]", "[global_USD:900.000 USD]", "[global_BTC:-0.00100000 BTC]", "[global_TLOS:-50.0000 TLOS]")
+		l.assertBalances(t, "Development", "[account_HUSD:-500.00 HUSD]", "[global_HUSD:-500.00 HUSD]")
+		l.assertBalances(t, "Marketing",
+			"[account_USD:900.000 USD]", "[global_USD:900.000 USD]",
+			"[account_BTC:-0.00100000 BTC]", "[global_BTC:-0.00100000 BTC]",
+			"[account_TLOS:-50.0000 TLOS]", "[global_TLOS:-50.0000 TLOS]")
+	})
+
+	update := func(t *testing.T, approve bool) (*trxTestLedger, *accounting.FakeChain) {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+		), false)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assertTransaction(t, chain, transactions[0], 1, 2)
+
+		_, err = l.chain.Upserttrx(ctx, transactions[0].Hash, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+			l.component(t, "Salary", "500.00 HUSD", accounting.Debit),
+			l.component(t, "Development", "500.00 HUSD", accounting.Credit),
+		), approve)
+		assert.NilError(t, err)
+
+		transactions = l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assertTransaction(t, chain, transactions[0], 1, 4)
+		assert.Equal(t, transactions[0].Approved(), approve)
+
+		return l, chain
+	}
+
+	t.Run("Test update without approval", func(t *testing.T) {
+
+		l, _ := update(t, false)
+
+		l.assertBalances(t, "Marketing")
+		l.assertBalances(t, "Expenses")
+	})
+
+	t.Run("Test update with approval", func(t *testing.T) {
+
+		l, _ := update(t, true)
+
+		l.assertBalances(t, "Income", "[global_USD:-1000.00 USD]", "[global_HUSD:500.00 HUSD]")
+		l.assertBalances(t, "Sales", "[account_USD:-1000.00 USD]", "[global_USD:-1000.00 USD]")
+		l.assertBalances(t, "Salary", "[account_HUSD:500.00 HUSD]", "[global_HUSD:500.00 HUSD]")
+		l.assertBalances(t, "Expenses", "[global_HUSD:-500.00 HUSD]", "[global_USD:1000.00 USD]")
+		l.assertBalances(t, "Development", "[account_HUSD:-500.00 HUSD]", "[global_HUSD:-500.00 HUSD]")
+		l.assertBalances(t, "Marketing", "[account_USD:1000.00 USD]", "[global_USD:1000.00 USD]")
+	})
+
+	withEvent := func(t *testing.T, approve bool) {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.NewEvent(ctx, accounting.ExternalEvent{
+			Source:  "treasury",
+			Cursor:  "1",
+			Details: map[string]string{"memo": "payment"},
+		}.ContentGroups())
+		assert.NilError(t, err)
+
+		event, err := chain.LastDocumentOfEdge(ctx, "event")
+		assert.NilError(t, err)
+
+		sales := l.component(t, "Sales", "1000.00 USD", accounting.Credit)
+		sales.Event = event.Hash
+
+		_, err = l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			sales,
+		), approve)
+		assert.NilError(t, err)
+
+		components, err := l.chain.EdgesFrom(ctx, event.Hash, "component")
+		assert.NilError(t, err)
+		assert.Equal(t, len(components), 1)
+
+		events, err := l.chain.EdgesFrom(ctx, components[0].ToNode, "event")
+		assert.NilError(t, err)
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].ToNode.String(), event.Hash.String())
+
+		found := false
+		for _, component := range l.transactions(t)[0].Components {
+			if component.Hash.String() == components[0].ToNode.String() {
+				found = true
+				assert.Equal(t, component.Account.String(), l.accounts["Sales"].String())
+			}
+		}
+		assert.Assert(t, found)
+	}
+
+	t.Run("Test insert transaction with event, without approval", func(t *testing.T) {
+		withEvent(t, false)
+	})
+
+	t.Run("Test insert transaction with event and approval", func(t *testing.T) {
+		withEvent(t, true)
+	})
+
+	t.Run("Test failures", func(t *testing.T) {
+
+		l, chain := newFakeTrxTestLedger(t)
+		before := len(chain.Snapshot().Documents())
+
+		_, err := l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "1000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "10000.000 USD", accounting.Credit),
+		), true)
+		assert.ErrorContains(t, err, "Transaction is unbalanced. Asset USD sums up to -9000.000 USD")
+
+		var unbalanced *accounting.ErrUnbalanced
+		assert.Assert(t, errors.As(err, &unbalanced), err)
+		assert.Equal(t, unbalanced.Symbol, "USD")
+
+		assert.Equal(t, len(chain.Snapshot().Documents()), before)
+
+		_, err = l.chain.Upserttrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "100.000 USD", accounting.Debit),
+			l.component(t, "Sales", "100.000 USD", accounting.Credit),
+		), true)
+		assert.NilError(t, err)
+
+		approved := l.transactions(t)[0]
+
+		_, err = l.chain.Upserttrx(ctx, approved.Hash, l.trx(
+			l.component(t, "Marketing", "10.000 USD", accounting.Debit),
+			l.component(t, "Sales", "10.000 USD", accounting.Credit),
+		), true)
+		assert.ErrorContains(t, err, "Cannot modify an approved transaction: "+approved.Hash.String())
+
+		var approvedErr *accounting.ErrApproved
+		assert.Assert(t, errors.As(err, &approvedErr), err)
+
+		for _, approve := range []bool{false, true} {
+			_, err = l.chain.Upserttrx(ctx, nil, l.trx(), approve)
+			assert.ErrorContains(t, err, "Transaction must contain at least 1 component")
+			assert.Assert(t, errors.Is(err, accounting.ErrNoComponents), err)
+		}
+	})
+}
+
+func TestCrryconvtrx(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("An authorized account can save a transaction for balancing two currencies", func(t *testing.T) {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Crryconvtrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "5000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 HUSD", accounting.Credit),
+		), false)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assert.Equal(t, transactions[0].ID, int64(1))
+		assert.Assert(t, transactions[0].CurrencyConversion)
+		assertEdges(t, chain, transactions[0].Hash, 3, 3)
+
+		l.assertBalances(t, "Marketing")
+	})
+
+	t.Run("An authorized account can approve a transaction for balancing two currencies", func(t *testing.T) {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.AddCurrency(ctx, "3,BTC")
+		assert.NilError(t, err)
+
+		_, err = l.chain.Crryconvtrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "5000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "100.000 BTC", accounting.Credit),
+		), true)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assert.Assert(t, transactions[0].Approved())
+		assertEdges(t, chain, transactions[0].Hash, 3, 3)
+
+		l.assertBalances(t, "Marketing", "[global_USD:5000.00 USD]", "[account_USD:5000.00 USD]")
+		l.assertBalances(t, "Sales", "[global_BTC:-100.000 BTC]", "[account_BTC:-100.000 BTC]")
+	})
+
+	update := func(t *testing.T, approve bool) *trxTestLedger {
+
+		l, chain := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Crryconvtrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "5000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 HUSD", accounting.Credit),
+		), false)
+		assert.NilError(t, err)
+
+		_, err = l.chain.Crryconvtrx(ctx, l.transactions(t)[0].Hash, l.trx(
+			l.component(t, "Marketing", "2000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 HUSD", accounting.Credit),
+		), approve)
+		assert.NilError(t, err)
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		assert.Equal(t, transactions[0].ID, int64(1))
+		assert.Equal(t, transactions[0].Components[0].Amount.String(), "2000.00 USD")
+		assert.Equal(t, transactions[0].Approved(), approve)
+		assertEdges(t, chain, transactions[0].Hash, 3, 3)
+
+		return l
+	}
+
+	t.Run("An authorized account can update a non approved transaction", func(t *testing.T) {
+
+		l := update(t, false)
+
+		l.assertBalances(t, "Marketing")
+	})
+
+	t.Run("An authorized account can update and approve a transaction", func(t *testing.T) {
+
+		l := update(t, true)
+
+		l.assertBalances(t, "Marketing", "[global_USD:2000.00 USD]", "[account_USD:2000.00 USD]")
+		l.assertBalances(t, "Sales", "[global_HUSD:-1000.00 HUSD]", "[account_HUSD:-1000.00 HUSD]")
+	})
+
+	t.Run("If the transaction has more than 2 currencies, the transaction is invalid", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Crryconvtrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "5000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 HUSD", accounting.Credit),
+			l.component(t, "Sales", "1000.00 HUSD", accounting.Credit),
+		), false)
+		assert.ErrorContains(t, err, "a currency conversion must have 2 components")
+	})
+
+	t.Run("If the 2 currencies are the same, the transaction is invalid", func(t *testing.T) {
+
+		l, _ := newFakeTrxTestLedger(t)
+
+		_, err := l.chain.Crryconvtrx(ctx, nil, l.trx(
+			l.component(t, "Marketing", "5000.00 USD", accounting.Debit),
+			l.component(t, "Sales", "1000.00 USD", accounting.Credit),
+		), false)
+		assert.ErrorContains(t, err, "a currency conversion must use 2 different currencies, provided only USD")
+	})
+}
@@ -2,7 +2,6 @@ package accounting_test
 
 import (
 	"context"
-	"os"
 	"strconv"
 	"testing"
 	"time"
@@ -18,12 +17,10 @@ import (
 
 //const defaultKey = "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
 
-var accountingHome, accountingWasm, accountingAbi, devHome, daoHome, daoWasm, daoAbi, tokenHome, tokenWasm, tokenAbi, tdHome, tdWasm, tdAbi string
+var daoHome, daoWasm, daoAbi, tokenHome, tokenWasm, tokenAbi, tdHome, tdWasm, tdAbi string
 var treasuryHome, treasuryWasm, treasuryAbi, monitorHome, monitorWasm, monitorAbi string
 var seedsHome, escrowWasm, escrowAbi, exchangeWasm, exchangeAbi string
 
-const testingEndpoint = "http://localhost:8888"
-
 type Member struct {
 	Member eos.AccountName
 	Doc    docgraph.Document
@@ -87,33 +84,16 @@ func (e *Environment) String() string {
 	return table.String()
 }
 
-func SetupEnvironment(t *testing.T) *Environment {
-
-	home, exists := os.LookupEnv("HOME")
-	if exists {
-		devHome = home
-	} else {
-		devHome = "."
-	}
-	devHome = devHome + ""
-	// devHome = "src/"
-	devHome = "../.."
-
-	accountingHome = devHome + "/accounting-contracts"
-	accountingWasm = accountingHome + "/build/accounting/accounting.wasm"
-	accountingAbi = accountingHome + "/build/accounting/accounting.abi"
+// SetupEnvironment creates the accounts on node and deploys the contract
+func SetupEnvironment(t *testing.T, node *Nodeos) *Environment {
 
 	var env Environment
 
-	env.api = *eos.New(testingEndpoint)
-	// api.Debug = true
 	env.ctx = context.Background()
-
-	keyBag := &eos.KeyBag{}
-	err := keyBag.ImportPrivateKey(env.ctx, eostest.DefaultKey())
+	api, err := node.API(env.ctx)
 	assert.NilError(t, err)
-
-	env.api.SetSigner(keyBag)
+	env.api = *api
+	// api.Debug = true
 
 	env.VotingDurationSeconds = 2
 	env.SeedsDeferralFactor = 100
@@ -141,12 +121,18 @@ func SetupEnvironment(t *testing.T) *Environment {
 	// assert.NilError(t, err)
 
 	t.Log("Deploying Accounting contract to 		: ", env.Accounting)
-	_, err = eostest.SetContract(env.ctx, &env.api, env.Accounting, accountingWasm, accountingAbi)
+	err = node.DeployContract(env.ctx, &env.api, env.Accounting)
 	assert.NilError(t, err)
 	// _, err = eostest.SetContract(env.ctx, &env.api, env.DAO, daoWasm, daoAbi)
 	// assert.NilError(t, err)
 
-	env.Root, err = CreateRoot(env.ctx, &env.api, env.Accounting, env.Accounting)
+	client, err := accounting.NewClient(&env.api, env.Accounting)
+	assert.NilError(t, err)
+
+	_, err = client.CreateRoot(env.ctx, "notes")
+	assert.NilError(t, err)
+
+	env.Root, err = docgraph.GetLastDocument(env.ctx, &env.api, env.Accounting)
 	assert.NilError(t, err)
 
 	_, err = accounting.AddTrustedAccount(env.ctx, &env.api, env.Accounting, env.Accounting)
@@ -0,0 +1,322 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	eos "github.com/eoscanada/eos-go"
+)
+
+// Errors for the contract assertions that don't carry details
+var (
+	ErrNotTrusted          = errors.New("only trusted accounts can perform this action")
+	ErrNegativeAmount      = errors.New("component amount must be a positive quantity")
+	ErrNoComponents        = errors.New("transaction must contain at least 1 component")
+	ErrNoAllowedCurrencies = errors.New("there are no allowed currencies")
+	ErrDuplicateCurrency   = errors.New("currency symbol already exists")
+	ErrInvalidAccountType  = errors.New("invalid account type")
+)
+
+// contractError ties one of the errors above to the error returned by the node
+type contractError struct {
+	sentinel error
+	err      error
+}
+
+func (e *contractError) Error() string        { return e.err.Error() }
+func (e *contractError) Is(target error) bool { return target == e.sentinel }
+func (e *contractError) Unwrap() error        { return e.err }
+
+// ErrUnbalanced is returned when the DEBIT and CREDIT components of an
+// approved transaction don't add up to zero for Symbol
+type ErrUnbalanced struct {
+	Symbol string
+	Sum    eos.Asset
+	Err    error
+}
+
+func (e *ErrUnbalanced) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("transaction is unbalanced: asset %v sums up to %v", e.Symbol, e.Sum)
+}
+
+func (e *ErrUnbalanced) Unwrap() error { return e.Err }
+
+// ErrCurrencyNotAllowed is returned when a component uses a currency that
+// is not in the settings allowed currencies
+type ErrCurrencyNotAllowed struct {
+	Symbol string
+	Err    error
+}
+
+func (e *ErrCurrencyNotAllowed) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("currency %v is not allowed", e.Symbol)
+}
+
+func (e *ErrCurrencyNotAllowed) Unwrap() error { return e.Err }
+
+// ErrNotLeaf is returned when a component is added to, or an account
+// deleted from, an account with children
+type ErrNotLeaf struct {
+	Account string
+	Err     error
+}
+
+func (e *ErrNotLeaf) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("account %v is not a leaf", e.Account)
+}
+
+func (e *ErrNotLeaf) Unwrap() error { return e.Err }
+
+// ErrApproved is returned when modifying an approved transaction
+type ErrApproved struct {
+	Transaction string
+	Err         error
+}
+
+func (e *ErrApproved) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("transaction %v is approved", e.Transaction)
+}
+
+func (e *ErrApproved) Unwrap() error { return e.Err }
+
+// ErrDuplicateAccountCode is returned when creating an account with a code
+// already used in the ledger
+type ErrDuplicateAccountCode struct {
+	Code string
+	Err  error
+}
+
+func (e *ErrDuplicateAccountCode) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("account code %v already exists", e.Code)
+}
+
+func (e *ErrDuplicateAccountCode) Unwrap() error { return e.Err }
+
+// ErrDuplicateAccountName is returned when creating an account with the
+// name of a sibling, names are compared ignoring case
+type ErrDuplicateAccountName struct {
+	Name string
+	Err  error
+}
+
+func (e *ErrDuplicateAccountName) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("there is already an account with name %v", e.Name)
+}
+
+func (e *ErrDuplicateAccountName) Unwrap() error { return e.Err }
+
+// ErrHasComponents is returned when creating a child of, or deleting, an
+// account with components
+type ErrHasComponents struct {
+	Account string
+	Err     error
+}
+
+func (e *ErrHasComponents) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("account %v already has associated components", e.Account)
+}
+
+func (e *ErrHasComponents) Unwrap() error { return e.Err }
+
+// ErrHasBalances is returned when deleting an account with balances
+type ErrHasBalances struct {
+	Account string
+	Err     error
+}
+
+func (e *ErrHasBalances) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("account %v already has balances", e.Account)
+}
+
+func (e *ErrHasBalances) Unwrap() error { return e.Err }
+
+// ErrInvalidComponentType is returned for components that are neither
+// DEBIT nor CREDIT
+type ErrInvalidComponentType struct {
+	Type string
+	Err  error
+}
+
+func (e *ErrInvalidComponentType) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("invalid component type %v, expected [%v or %v]", e.Type, Debit, Credit)
+}
+
+func (e *ErrInvalidComponentType) Unwrap() error { return e.Err }
+
+// ErrAlreadyBound is returned by bindevent when the event or the component
+// is already bound, only one of Event and Component is set
+type ErrAlreadyBound struct {
+	Event     string
+	Component string
+	Err       error
+}
+
+func (e *ErrAlreadyBound) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Event != "" {
+		return fmt.Sprintf("event %v is already bound to a component", e.Event)
+	}
+	return fmt.Sprintf("component %v is already bound to an event", e.Component)
+}
+
+func (e *ErrAlreadyBound) Unwrap() error { return e.Err }
+
+type errorPattern struct {
+	re    *regexp.Regexp
+	build func(match []string, err error) error
+}
+
+func sentinelPattern(expr string, sentinel error) errorPattern {
+	return errorPattern{
+		re: regexp.MustCompile(expr),
+		build: func(_ []string, err error) error {
+			return &contractError{sentinel: sentinel, err: err}
+		},
+	}
+}
+
+// errorPatterns match the assertion messages of src/accounting.cpp and
+// src/transaction.cpp
+var errorPatterns = []errorPattern{
+	{
+		re: regexp.MustCompile(`Transaction is unbalanced\. Asset (\S+) sums up to (-?[0-9.]+ [A-Z]+)`),
+		build: func(m []string, err error) error {
+			sum, _ := eos.NewAssetFromString(m[2])
+			return &ErrUnbalanced{Symbol: m[1], Sum: sum, Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Currency (\S+) is not allowed\.`),
+		build: func(m []string, err error) error {
+			return &ErrCurrencyNotAllowed{Symbol: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Only leafs are allowed to have associated components\. Account ([0-9a-fA-F]+) is not a leaf`),
+		build: func(m []string, err error) error {
+			return &ErrNotLeaf{Account: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`The account ([0-9a-fA-F]+) is not a leaf, it can not be deleted`),
+		build: func(m []string, err error) error {
+			return &ErrNotLeaf{Account: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Cannot (?:modify|delete) an approved transaction: ([0-9a-fA-F]+)`),
+		build: func(m []string, err error) error {
+			return &ErrApproved{Transaction: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Cannot unbind event from approved transaction: ([0-9a-fA-F]+)`),
+		build: func(m []string, err error) error {
+			return &ErrApproved{Transaction: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`account code (.+?) already exists`),
+		build: func(m []string, err error) error {
+			return &ErrDuplicateAccountCode{Code: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`There is already an account with name: (.+?)(?:: pending console output|\n|$)`),
+		build: func(m []string, err error) error {
+			return &ErrDuplicateAccountName{Name: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Parent account already has associated components\. Parent hash: ([0-9a-fA-F]+)`),
+		build: func(m []string, err error) error {
+			return &ErrHasComponents{Account: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`The account ([0-9a-fA-F]+) already has associated components`),
+		build: func(m []string, err error) error {
+			return &ErrHasComponents{Account: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`The account ([0-9a-fA-F]+) already has balances associated with it`),
+		build: func(m []string, err error) error {
+			return &ErrHasBalances{Account: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Invalid component type:(\S*) expected`),
+		build: func(m []string, err error) error {
+			return &ErrInvalidComponentType{Type: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Event: ([0-9a-fA-F]+) is already binded to a component`),
+		build: func(m []string, err error) error {
+			return &ErrAlreadyBound{Event: m[1], Err: err}
+		},
+	},
+	{
+		re: regexp.MustCompile(`Component: ([0-9a-fA-F]+) is already binded to an event`),
+		build: func(m []string, err error) error {
+			return &ErrAlreadyBound{Component: m[1], Err: err}
+		},
+	},
+	sentinelPattern(`Only trusted accounts can perform this action`, ErrNotTrusted),
+	sentinelPattern(`Component amount must be a positive quantity`, ErrNegativeAmount),
+	sentinelPattern(`Transaction must contain at least 1 component`, ErrNoComponents),
+	sentinelPattern(`There are no allowed currencies`, ErrNoAllowedCurrencies),
+	sentinelPattern(`Currency symbol already exists`, ErrDuplicateCurrency),
+	sentinelPattern(`Invalid account type: -?[0-9]+`, ErrInvalidAccountType),
+}
+
+// ClassifyError turns the assertion failures of the contract into the
+// errors of this package. The classified errors keep the message of the
+// node and unwrap to the original error, other errors are returned as is.
+func ClassifyError(err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+
+	for _, pattern := range errorPatterns {
+		if match := pattern.re.FindStringSubmatch(message); match != nil {
+			return pattern.build(match, err)
+		}
+	}
+
+	return err
+}
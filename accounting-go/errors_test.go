@@ -0,0 +1,69 @@
+package accounting_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+
+	nodeError := func(message string) error {
+		return fmt.Errorf("Error during trx push: Assert Exception: assertion failure with message: %v: pending console output: ", message)
+	}
+
+	t.Run("Unbalanced transaction", func(t *testing.T) {
+
+		err := accounting.ClassifyError(nodeError("Transaction is unbalanced. Asset USD sums up to -9000.000 USD"))
+
+		var unbalanced *accounting.ErrUnbalanced
+		assert.Assert(t, errors.As(err, &unbalanced))
+		assert.Equal(t, unbalanced.Symbol, "USD")
+		assert.Equal(t, unbalanced.Sum.String(), "-9000.000 USD")
+		assert.ErrorContains(t, err, "Transaction is unbalanced. Asset USD sums up to -9000.000 USD")
+	})
+
+	t.Run("Not trusted", func(t *testing.T) {
+
+		original := nodeError("Only trusted accounts can perform this action")
+		err := accounting.ClassifyError(original)
+
+		assert.Assert(t, errors.Is(err, accounting.ErrNotTrusted))
+		assert.Assert(t, errors.Is(err, original))
+		assert.Assert(t, errors.Is(accounting.ClassifyError(nodeError("Invalid account type: 9")), accounting.ErrInvalidAccountType))
+	})
+
+	t.Run("Details of typed errors", func(t *testing.T) {
+
+		var notAllowed *accounting.ErrCurrencyNotAllowed
+		assert.Assert(t, errors.As(accounting.ClassifyError(nodeError("Currency HUSD is not allowed.")), &notAllowed))
+		assert.Equal(t, notAllowed.Symbol, "HUSD")
+
+		var notLeaf *accounting.ErrNotLeaf
+		assert.Assert(t, errors.As(accounting.ClassifyError(nodeError(
+			"Only leafs are allowed to have associated components. Account 0a1b is not a leaf.")), &notLeaf))
+		assert.Equal(t, notLeaf.Account, "0a1b")
+
+		var approved *accounting.ErrApproved
+		assert.Assert(t, errors.As(accounting.ClassifyError(nodeError("Cannot delete an approved transaction: 89bf")), &approved))
+		assert.Equal(t, approved.Transaction, "89bf")
+
+		var duplicate *accounting.ErrDuplicateAccountCode
+		assert.Assert(t, errors.As(accounting.ClassifyError(nodeError("account code 000114 already exists")), &duplicate))
+		assert.Equal(t, duplicate.Code, "000114")
+
+		var duplicateName *accounting.ErrDuplicateAccountName
+		assert.Assert(t, errors.As(accounting.ClassifyError(nodeError("There is already an account with name: Office Rent")), &duplicateName))
+		assert.Equal(t, duplicateName.Name, "Office Rent")
+	})
+
+	t.Run("Other errors are kept", func(t *testing.T) {
+
+		original := nodeError("a currency conversion must have 2 components")
+		assert.Equal(t, accounting.ClassifyError(original), original)
+		assert.NilError(t, accounting.ClassifyError(nil))
+	})
+}
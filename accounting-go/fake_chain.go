@@ -0,0 +1,741 @@
+package accounting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// FakeChain runs the accounting actions in memory following
+// src/accounting.cpp: the documents and edges it writes, the balances
+// rollup, the leaf rules, the trusted accounts and the allowed currencies.
+// Failed actions leave the tables untouched and return the assertion
+// message of the contract through ClassifyError, so the errors of this
+// package can be checked the same way against a node.
+//
+// The authorization of the actions is not checked, the actions requiring
+// the contract authority run as if it was given and addtrustacnt accepts
+// any account name. Document hashes are computed from the content but
+// differ from the ones of the contract.
+type FakeChain struct {
+	contract eos.AccountName
+	actor    eos.AccountName
+	state    *fakeState
+}
+
+// fakeState is shared by the copies returned by FakeChain.As
+type fakeState struct {
+	mu     sync.Mutex
+	now    time.Time
+	block  uint32
+	tables *fakeTables
+}
+
+// fakeTables holds the documents, edges and cursors tables of the contract
+type fakeTables struct {
+	documents      map[string]docgraph.Document
+	edges          []docgraph.Edge
+	cursors        []CursorRow
+	nextDocumentID uint64
+	nextEdgeID     uint64
+}
+
+// fakeBlockInterval is the time the clock of a FakeChain advances with
+// every action
+const fakeBlockInterval = 500 * time.Millisecond
+
+// NewFakeChain creates an empty fake of the contract deployed at contract,
+// issuing the actions as contract
+func NewFakeChain(contract eos.AccountName) *FakeChain {
+	return &FakeChain{
+		contract: contract,
+		actor:    contract,
+		state: &fakeState{
+			now:    time.Now().UTC().Truncate(time.Second),
+			tables: &fakeTables{documents: make(map[string]docgraph.Document)},
+		},
+	}
+}
+
+// As returns a copy of the fake chain that issues actions as actor,
+// the copies share the tables
+func (f *FakeChain) As(actor eos.AccountName) *FakeChain {
+	clone := *f
+	clone.actor = actor
+	return &clone
+}
+
+// Contract returns the accounting contract account
+func (f *FakeChain) Contract() eos.AccountName {
+	return f.contract
+}
+
+// Actor returns the account issuing the actions
+func (f *FakeChain) Actor() eos.AccountName {
+	return f.actor
+}
+
+// SetTime sets the time of the next action, it is used for the create
+// dates written by the contract
+func (f *FakeChain) SetTime(now time.Time) {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	f.state.now = now.UTC().Add(-fakeBlockInterval)
+}
+
+// Now returns the time of the last action
+func (f *FakeChain) Now() time.Time {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.now
+}
+
+// Document returns the document hash
+func (f *FakeChain) Document(ctx context.Context, hash eos.Checksum256) (docgraph.Document, error) {
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	document, ok := f.state.tables.documents[hash.String()]
+
+	if !ok {
+		return docgraph.Document{}, fmt.Errorf("document not found %v", hash)
+	}
+
+	return document, nil
+}
+
+// EdgesFrom returns the edges named edgeName leaving from the document hash
+func (f *FakeChain) EdgesFrom(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.tables.edgesFrom(hash, edgeName), nil
+}
+
+// EdgesTo returns the edges named edgeName arriving to the document hash
+func (f *FakeChain) EdgesTo(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.tables.edgesTo(hash, edgeName), nil
+}
+
+// Snapshot returns a copy of the documents and edges tables
+func (f *FakeChain) Snapshot() *Snapshot {
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	documents := make([]docgraph.Document, 0, len(f.state.tables.documents))
+	for _, document := range f.state.tables.documents {
+		documents = append(documents, document)
+	}
+
+	return NewSnapshot(documents, append([]docgraph.Edge(nil), f.state.tables.edges...))
+}
+
+// Cursors returns a copy of the cursors table
+func (f *FakeChain) Cursors() []CursorRow {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return append([]CursorRow(nil), f.state.tables.cursors...)
+}
+
+// LastDocumentOfEdge returns the document pointed by the latest edge named
+// edgeName, like docgraph.GetLastDocumentOfEdge
+func (f *FakeChain) LastDocumentOfEdge(ctx context.Context, edgeName string) (docgraph.Document, error) {
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	for i := len(f.state.tables.edges) - 1; i >= 0; i-- {
+		if edge := f.state.tables.edges[i]; string(edge.EdgeName) == edgeName {
+			if document, ok := f.state.tables.documents[edge.ToNode.String()]; ok {
+				return document, nil
+			}
+			return docgraph.Document{}, fmt.Errorf("document not found %v", edge.ToNode)
+		}
+	}
+
+	return docgraph.Document{}, fmt.Errorf("no %v edges", edgeName)
+}
+
+// Settings decodes the settings document, the settings are empty until an
+// action creates them
+func (f *FakeChain) Settings(ctx context.Context) (Settings, error) {
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	for _, edge := range f.state.tables.edges {
+		if string(edge.EdgeName) == settingsEdge {
+			return SettingsFromDocument(f.state.tables.documents[edge.ToNode.String()])
+		}
+	}
+
+	return Settings{Data: make(map[string]*docgraph.FlexValue), CoinIDs: make(map[string]string)}, nil
+}
+
+// GetSettings is Settings for the Chain interface
+func (f *FakeChain) GetSettings(ctx context.Context) (Settings, error) {
+	return f.Settings(ctx)
+}
+
+// GetLastCursor returns the last cursor stored in the cursors table
+func (f *FakeChain) GetLastCursor(ctx context.Context) (string, error) {
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	cursors := f.state.tables.cursors
+
+	if len(cursors) == 0 {
+		return "", fmt.Errorf("cursor not found")
+	}
+
+	return cursors[len(cursors)-1].LastCursor, nil
+}
+
+// GetCursorFromSource returns the last cursor stored for source
+func (f *FakeChain) GetCursorFromSource(ctx context.Context, source string) (string, error) {
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	for _, cursor := range f.state.tables.cursors {
+		if cursor.Source == source {
+			return cursor.LastCursor, nil
+		}
+	}
+
+	return "", fmt.Errorf("cursor not found %v", source)
+}
+
+// fakeTx is an action running on a copy of the tables
+type fakeTx struct {
+	contract eos.AccountName
+	issuer   eos.AccountName
+	now      eos.TimePoint
+	tables   *fakeTables
+}
+
+// exec runs action on a copy of the tables and keeps the copy if it succeeds
+func (f *FakeChain) exec(ctx context.Context, action string, run func(tx *fakeTx) error) (*TxResult, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	now := f.state.now.Add(fakeBlockInterval)
+
+	tx := &fakeTx{
+		contract: f.contract,
+		issuer:   f.actor,
+		now:      TimePointOf(now),
+		tables:   f.state.tables.clone(),
+	}
+
+	if err := run(tx); err != nil {
+		return nil, err
+	}
+
+	f.state.now = now
+	f.state.block++
+	f.state.tables = tx.tables
+
+	id := sha256.Sum256([]byte(fmt.Sprintf("%v %v %v %v", f.contract, f.actor, action, f.state.block)))
+
+	return &TxResult{
+		TrxID:    hex.EncodeToString(id[:]),
+		BlockNum: f.state.block,
+		Traces:   []ActionResult{{Receiver: f.contract, Action: eos.ActN(action)}},
+		Attempts: 1,
+	}, nil
+}
+
+// fakeAssert fails an action with the assertion message of the contract
+func fakeAssert(format string, args ...interface{}) error {
+	return ClassifyError(fmt.Errorf("assertion failure with message: %v", fmt.Sprintf(format, args...)))
+}
+
+func (t *fakeTables) clone() *fakeTables {
+
+	clone := *t
+
+	clone.documents = make(map[string]docgraph.Document, len(t.documents))
+	for hash, document := range t.documents {
+		clone.documents[hash] = document
+	}
+
+	clone.edges = append([]docgraph.Edge(nil), t.edges...)
+	clone.cursors = append([]CursorRow(nil), t.cursors...)
+
+	return &clone
+}
+
+func (t *fakeTables) edgesFrom(hash eos.Checksum256, edgeName string) []docgraph.Edge {
+
+	edges := []docgraph.Edge{}
+
+	for _, edge := range t.edges {
+		if edge.FromNode.String() == hash.String() && (edgeName == "" || string(edge.EdgeName) == edgeName) {
+			edges = append(edges, edge)
+		}
+	}
+
+	return edges
+}
+
+func (t *fakeTables) edgesTo(hash eos.Checksum256, edgeName string) []docgraph.Edge {
+
+	edges := []docgraph.Edge{}
+
+	for _, edge := range t.edges {
+		if edge.ToNode.String() == hash.String() && (edgeName == "" || string(edge.EdgeName) == edgeName) {
+			edges = append(edges, edge)
+		}
+	}
+
+	return edges
+}
+
+// contentHash hashes the labels, types and values of groups
+func contentHash(groups []docgraph.ContentGroup) eos.Checksum256 {
+
+	h := sha256.New()
+
+	for _, group := range groups {
+		fmt.Fprint(h, "[")
+		for _, item := range group {
+			fmt.Fprintf(h, "%q:", item.Label)
+			if item.Value != nil {
+				fmt.Fprintf(h, "%T=%v", item.Value.Impl, item.Value.Impl)
+			}
+			fmt.Fprint(h, ";")
+		}
+		fmt.Fprint(h, "]")
+	}
+
+	return eos.Checksum256(h.Sum(nil))
+}
+
+// cloneGroups copies groups so their items can be replaced, the values
+// are shared
+func cloneGroups(groups []docgraph.ContentGroup) []docgraph.ContentGroup {
+
+	clone := make([]docgraph.ContentGroup, len(groups))
+
+	for i, group := range groups {
+		clone[i] = append(docgraph.ContentGroup(nil), group...)
+	}
+
+	return clone
+}
+
+func groupIndex(groups []docgraph.ContentGroup, label string) int {
+	for i, group := range groups {
+		if groupLabel(group) == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertOrReplace replaces the item of group with the label of item or
+// appends item
+func insertOrReplace(group docgraph.ContentGroup, item docgraph.ContentItem) docgraph.ContentGroup {
+
+	for i := range group {
+		if group[i].Label == item.Label {
+			group[i] = item
+			return group
+		}
+	}
+
+	return append(group, item)
+}
+
+func fakeSystemGroup(nodeName, typeName string, items ...docgraph.ContentItem) docgraph.ContentGroup {
+	return newGroup(systemGroup, append([]docgraph.ContentItem{
+		stringItem(nodeLabel, nodeName),
+		stringItem(typeLabel, typeName),
+	}, items...)...)
+}
+
+// read returns a reader of the group labelled label, failing like
+// ContentWrapper::getGroupOrFail when it is missing
+func read(groups []docgraph.ContentGroup, label string) (*contentReader, error) {
+
+	group, ok := findGroup(groups, label)
+
+	if !ok {
+		return nil, fakeAssert("group %v is required", label)
+	}
+
+	return &contentReader{group: group}, nil
+}
+
+func (tx *fakeTx) document(hash eos.Checksum256) (docgraph.Document, error) {
+
+	document, ok := tx.tables.documents[hash.String()]
+
+	if !ok {
+		return docgraph.Document{}, fakeAssert("document not found %v", hash)
+	}
+
+	return document, nil
+}
+
+func (tx *fakeTx) exists(hash eos.Checksum256) bool {
+	_, ok := tx.tables.documents[hash.String()]
+	return ok
+}
+
+func (tx *fakeTx) newDocument(creator eos.AccountName, groups []docgraph.ContentGroup) docgraph.Document {
+
+	document := docgraph.Document{
+		ID:            tx.tables.nextDocumentID,
+		Hash:          contentHash(groups),
+		Creator:       creator,
+		ContentGroups: groups,
+		CreatedDate:   eos.BlockTimestamp{Time: TimeOf(tx.now)},
+		Contract:      eos.Name(tx.contract),
+	}
+
+	tx.tables.nextDocumentID++
+	tx.tables.documents[document.Hash.String()] = document
+
+	return document
+}
+
+// createDocument stores a new document, like the Document constructor
+func (tx *fakeTx) createDocument(creator eos.AccountName, groups []docgraph.ContentGroup) (docgraph.Document, error) {
+
+	if hash := contentHash(groups); tx.exists(hash) {
+		return docgraph.Document{}, fakeAssert("document already exists: %v", hash)
+	}
+
+	return tx.newDocument(creator, groups), nil
+}
+
+// getOrNew returns the document with groups, creating it if needed
+func (tx *fakeTx) getOrNew(creator eos.AccountName, groups []docgraph.ContentGroup) docgraph.Document {
+
+	if document, ok := tx.tables.documents[contentHash(groups).String()]; ok {
+		return document
+	}
+
+	return tx.newDocument(creator, groups)
+}
+
+// createEdge stores an edge, like the Edge constructor
+func (tx *fakeTx) createEdge(creator eos.AccountName, from, to eos.Checksum256, edgeName string) error {
+
+	for _, edge := range tx.tables.edgesFrom(from, edgeName) {
+		if edge.ToNode.String() == to.String() {
+			return fakeAssert("edge from: %v to: %v with name: %v already exists", from, to, edgeName)
+		}
+	}
+
+	tx.tables.edges = append(tx.tables.edges, docgraph.Edge{
+		ID:          tx.tables.nextEdgeID,
+		FromNode:    from,
+		ToNode:      to,
+		EdgeName:    eos.Name(edgeName),
+		CreatedDate: tx.now,
+		Creator:     eos.Name(creator),
+		Contract:    eos.Name(tx.contract),
+	})
+
+	tx.tables.nextEdgeID++
+
+	return nil
+}
+
+// parent links parent and child in both directions
+func (tx *fakeTx) parent(creator eos.AccountName, parent, child eos.Checksum256, fromToEdge, toFromEdge string) error {
+
+	if err := tx.createEdge(creator, parent, child, fromToEdge); err != nil {
+		return err
+	}
+
+	return tx.createEdge(creator, child, parent, toFromEdge)
+}
+
+// edge returns the first edge named edgeName leaving from hash, like Edge::get
+func (tx *fakeTx) edge(from eos.Checksum256, edgeName string) (docgraph.Edge, error) {
+
+	edges := tx.tables.edgesFrom(from, edgeName)
+
+	if len(edges) == 0 {
+		return docgraph.Edge{}, fakeAssert("edge does not exist: from %v with name %v", from, edgeName)
+	}
+
+	return edges[0], nil
+}
+
+func (tx *fakeTx) removeEdges(remove func(edge docgraph.Edge) bool) {
+
+	edges := tx.tables.edges[:0]
+
+	for _, edge := range tx.tables.edges {
+		if !remove(edge) {
+			edges = append(edges, edge)
+		}
+	}
+
+	tx.tables.edges = edges
+}
+
+// eraseDocument removes hash and the edges leaving from or arriving to it
+func (tx *fakeTx) eraseDocument(hash eos.Checksum256) {
+
+	delete(tx.tables.documents, hash.String())
+
+	tx.removeEdges(func(edge docgraph.Edge) bool {
+		return edge.FromNode.String() == hash.String() || edge.ToNode.String() == hash.String()
+	})
+}
+
+// updateDocument replaces the document hash with a document holding groups
+// and moves its edges to the new document, like DocumentGraph::updateDocument
+func (tx *fakeTx) updateDocument(hash eos.Checksum256, groups []docgraph.ContentGroup) (docgraph.Document, error) {
+
+	if _, err := tx.document(hash); err != nil {
+		return docgraph.Document{}, err
+	}
+
+	updated, err := tx.createDocument(tx.contract, groups)
+
+	if err != nil {
+		return docgraph.Document{}, err
+	}
+
+	for i, edge := range tx.tables.edges {
+		moved := false
+		if edge.FromNode.String() == hash.String() {
+			edge.FromNode, moved = updated.Hash, true
+		}
+		if edge.ToNode.String() == hash.String() {
+			edge.ToNode, moved = updated.Hash, true
+		}
+		if moved {
+			edge.ID = tx.tables.nextEdgeID
+			edge.CreatedDate = tx.now
+			edge.Creator = eos.Name(tx.contract)
+			tx.tables.nextEdgeID++
+			tx.tables.edges[i] = edge
+		}
+	}
+
+	sort.SliceStable(tx.tables.edges, func(i, j int) bool {
+		return tx.tables.edges[i].ID < tx.tables.edges[j].ID
+	})
+
+	delete(tx.tables.documents, hash.String())
+
+	return updated, nil
+}
+
+// updateGroup replaces the items of the group labelled label of the document
+// hash, the group must exist
+func (tx *fakeTx) updateGroup(hash eos.Checksum256, label string, items ...docgraph.ContentItem) (docgraph.Document, error) {
+
+	document, err := tx.document(hash)
+
+	if err != nil {
+		return docgraph.Document{}, err
+	}
+
+	groups := cloneGroups(document.ContentGroups)
+	i := groupIndex(groups, label)
+
+	if i < 0 {
+		return docgraph.Document{}, fakeAssert("group %v is required in document %v", label, hash)
+	}
+
+	for _, item := range items {
+		groups[i] = insertOrReplace(groups[i], item)
+	}
+
+	return tx.updateDocument(hash, groups)
+}
+
+// root returns the root document, creating it on the first action
+func (tx *fakeTx) root() docgraph.Document {
+	return tx.getOrNew(tx.contract, []docgraph.ContentGroup{
+		newGroup(detailsGroup, nameItem(rootNodeLabel, eos.Name(tx.contract))),
+		fakeSystemGroup("root", rootNodeLabel),
+	})
+}
+
+// rootChild returns the document linked to the root by edgeName, creating
+// it with groups when there is none
+func (tx *fakeTx) rootChild(edgeName string, groups func(root docgraph.Document) []docgraph.ContentGroup) (docgraph.Document, error) {
+
+	root := tx.root()
+
+	if edges := tx.tables.edgesFrom(root.Hash, edgeName); len(edges) > 0 {
+		return tx.document(edges[0].ToNode)
+	}
+
+	document, err := tx.createDocument(tx.contract, groups(root))
+
+	if err != nil {
+		return docgraph.Document{}, err
+	}
+
+	return document, tx.createEdge(tx.contract, root.Hash, document.Hash, edgeName)
+}
+
+func (tx *fakeTx) settings() (docgraph.Document, error) {
+	return tx.rootChild(settingsEdge, func(root docgraph.Document) []docgraph.ContentGroup {
+		return []docgraph.ContentGroup{
+			newGroup(detailsGroup, checksumItem(rootNodeLabel, root.Hash)),
+			newGroup(settingsDataGroup),
+			fakeSystemGroup(settingsEdge, settingsEdge),
+		}
+	})
+}
+
+// settingInt returns the integer setting label or defaultValue
+func (tx *fakeTx) settingInt(label string, defaultValue int64) (int64, error) {
+
+	settings, err := tx.settings()
+
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := read(settings.ContentGroups, settingsDataGroup)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if r.value(label, false) == nil {
+		return defaultValue, nil
+	}
+
+	value := r.integer(label, true)
+
+	if r.err != nil {
+		return 0, fakeAssert("%v", r.err)
+	}
+
+	return value, nil
+}
+
+// setSetting adds or replaces a setting and its update date
+func (tx *fakeTx) setSetting(label string, value *docgraph.FlexValue) error {
+
+	settings, err := tx.settings()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.updateGroup(settings.Hash, settingsDataGroup,
+		docgraph.ContentItem{Label: label, Value: value},
+		timePointItem("update_date", tx.now),
+	)
+
+	return err
+}
+
+// updateSettings replaces the settings document with the groups returned
+// by update
+func (tx *fakeTx) updateSettings(update func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error)) error {
+
+	settings, err := tx.settings()
+
+	if err != nil {
+		return err
+	}
+
+	groups, err := update(cloneGroups(settings.ContentGroups))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.updateDocument(settings.Hash, groups)
+
+	return err
+}
+
+func (tx *fakeTx) requireTrusted(account eos.AccountName) error {
+
+	settings, err := tx.settings()
+
+	if err != nil {
+		return err
+	}
+
+	if group, ok := findGroup(settings.ContentGroups, trustedAccountsGroup); ok {
+		for _, item := range group[1:] {
+			if trusted, err := flexName(item.Value); err == nil && trusted == eos.Name(account) {
+				return nil
+			}
+		}
+	}
+
+	return fakeAssert("Only trusted accounts can perform this action")
+}
+
+// allowedCurrencies returns the symbol codes of the allowed currencies.
+// Like the contract it reads every item of the group as an asset, so the
+// coin ids added by addcoinid make it fail.
+func (tx *fakeTx) allowedCurrencies() (map[string]bool, error) {
+
+	settings, err := tx.settings()
+
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool)
+
+	if group, ok := findGroup(settings.ContentGroups, allowedCurrenciesGroup); ok {
+		for _, item := range group[1:] {
+			currency, err := flexAsset(item.Value)
+			if err != nil {
+				return nil, fakeAssert("Content value for label: %v is not of expected type", item.Label)
+			}
+			allowed[currency.Symbol.Symbol] = true
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil, fakeAssert("There are no allowed currencies.")
+	}
+
+	return allowed, nil
+}
+
+func (tx *fakeTx) accountCodes() (docgraph.Document, error) {
+	return tx.rootChild(accountCodesEdge, func(root docgraph.Document) []docgraph.ContentGroup {
+		return []docgraph.ContentGroup{
+			newGroup(detailsGroup, checksumItem(rootNodeLabel, root.Hash)),
+			fakeSystemGroup("accountcodes", "accountcodes"),
+		}
+	})
+}
+
+func (tx *fakeTx) eventBucket() (docgraph.Document, error) {
+	return tx.rootChild(eventBucketEdge, func(root docgraph.Document) []docgraph.ContentGroup {
+		return []docgraph.ContentGroup{
+			newGroup(detailsGroup),
+			fakeSystemGroup("Events Bucket", eventEdge),
+		}
+	})
+}
@@ -0,0 +1,326 @@
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+// fakeLedger is a ledger on a FakeChain with the accounts
+//
+//	1000 Assets
+//	  1100 Cash
+//	4000 Revenue
+//	  4100 Sales
+type fakeLedger struct {
+	chain   *accounting.FakeChain
+	ledger  eos.Checksum256
+	assets  eos.Checksum256
+	cash    eos.Checksum256
+	revenue eos.Checksum256
+	sales   eos.Checksum256
+}
+
+func newFakeLedger(t *testing.T) *fakeLedger {
+
+	ctx := context.Background()
+
+	chain := accounting.NewFakeChain(eos.AN("accounting"))
+
+	_, err := chain.CreateRoot(ctx, "accounting")
+	assert.NilError(t, err)
+
+	_, err = chain.AddTrustedAccount(ctx, eos.AN("treasurer"))
+	assert.NilError(t, err)
+
+	l := &fakeLedger{chain: chain.As(eos.AN("treasurer"))}
+
+	_, err = l.chain.AddCurrency(ctx, "2,USD")
+	assert.NilError(t, err)
+
+	_, err = l.chain.AddLedger(ctx, accounting.Ledger{Name: "Main"}.ContentGroups())
+	assert.NilError(t, err)
+
+	ledgers := l.chain.Snapshot().Ledgers()
+	assert.Equal(t, len(ledgers), 1)
+	l.ledger = ledgers[0].Hash
+
+	l.assets = l.createAccount(t, l.ledger, "1000", "Assets")
+	l.cash = l.createAccount(t, l.assets, "1100", "Cash")
+	l.revenue = l.createAccount(t, l.ledger, "4000", "Revenue")
+	l.sales = l.createAccount(t, l.revenue, "4100", "Sales")
+
+	return l
+}
+
+func (l *fakeLedger) newAccount(parent eos.Checksum256, code, name string) accounting.Account {
+	return accounting.Account{
+		Name:    name,
+		Code:    code,
+		TagType: accounting.Debit,
+		Type:    accounting.AccountTypeAsset,
+		Parent:  parent,
+		Ledger:  l.ledger,
+	}
+}
+
+func (l *fakeLedger) createAccount(t *testing.T, parent eos.Checksum256, code, name string) eos.Checksum256 {
+
+	ctx := context.Background()
+
+	_, err := l.chain.CreateAcct(ctx, l.newAccount(parent, code, name).ContentGroups())
+	assert.NilError(t, err)
+
+	children, err := accounting.ChildAccounts(ctx, l.chain, parent)
+	assert.NilError(t, err)
+
+	for _, child := range children {
+		if child.Code == code {
+			return child.Hash
+		}
+	}
+
+	t.Fatalf("account %v not found", code)
+	return nil
+}
+
+func (l *fakeLedger) sale(t *testing.T, memo, debit, credit string) *accounting.TransactionBuilder {
+
+	debitAmount, err := eos.NewAssetFromString(debit)
+	assert.NilError(t, err)
+
+	creditAmount, err := eos.NewAssetFromString(credit)
+	assert.NilError(t, err)
+
+	return accounting.NewTransactionBuilder(l.ledger).
+		Date(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)).
+		Memo(memo).
+		Name(memo).
+		Debit(l.cash, debitAmount, "cash").
+		Credit(l.sales, creditAmount, "sales")
+}
+
+func (l *fakeLedger) upsert(t *testing.T, trxHash eos.Checksum256, b *accounting.TransactionBuilder, approve bool) error {
+
+	trxInfo, err := b.Build()
+	assert.NilError(t, err)
+
+	_, err = l.chain.Upserttrx(context.Background(), trxHash, trxInfo, approve)
+
+	return err
+}
+
+// transactions returns the transactions of the ledger keyed by memo
+func (l *fakeLedger) transactions(t *testing.T) map[string]accounting.Transaction {
+
+	ctx := context.Background()
+
+	buckets, err := l.chain.EdgesFrom(ctx, l.ledger, "trxbucket")
+	assert.NilError(t, err)
+	assert.Equal(t, len(buckets), 1)
+
+	transactions := make(map[string]accounting.Transaction)
+
+	for _, edgeName := range []string{"approved", "unapproved"} {
+
+		edges, err := l.chain.EdgesFrom(ctx, buckets[0].ToNode, edgeName)
+		assert.NilError(t, err)
+
+		for _, edge := range edges {
+			document, err := l.chain.Document(ctx, edge.ToNode)
+			assert.NilError(t, err)
+			trx, err := accounting.TransactionFromDocument(document)
+			assert.NilError(t, err)
+			transactions[trx.Memo] = trx
+		}
+	}
+
+	return transactions
+}
+
+func TestFakeChain(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("rolls up the balances of approved transactions", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+
+		assets, err := accounting.LoadAccount(ctx, l.chain, l.assets)
+		assert.NilError(t, err)
+		assert.Assert(t, !assets.IsLeaf)
+
+		assert.NilError(t, l.upsert(t, nil, l.sale(t, "April sales", "50.00 USD", "50.00 USD"), true))
+		assert.NilError(t, l.upsert(t, nil, l.sale(t, "May sales", "25.00 USD", "25.00 USD"), true))
+
+		tree, err := accounting.ReadLedgerTree(ctx, l.chain, l.ledger)
+		assert.NilError(t, err)
+
+		balances := func(name string) *accounting.Balances {
+			node := tree.Find(name)
+			assert.Assert(t, node != nil && node.Balances != nil, name)
+			return node.Balances
+		}
+
+		assert.Equal(t, balances("Cash").Account["USD"].String(), "75.00 USD")
+		assert.Equal(t, balances("Cash").Global["USD"].String(), "75.00 USD")
+		assert.Equal(t, len(balances("Assets").Account), 0)
+		assert.Equal(t, balances("Assets").Global["USD"].String(), "75.00 USD")
+		assert.Equal(t, balances("Revenue").Global["USD"].String(), "-75.00 USD")
+
+		transactions := l.transactions(t)
+		assert.Equal(t, transactions["April sales"].ID, int64(1))
+		assert.Equal(t, transactions["May sales"].ID, int64(2))
+		assert.Equal(t, transactions["May sales"].ApprovedBy, eos.Name("treasurer"))
+
+		settings, err := l.chain.Settings(ctx)
+		assert.NilError(t, err)
+		assert.Assert(t, settings.IsTrusted(eos.AN("treasurer")))
+		assert.Assert(t, settings.IsAllowedCurrency("USD"))
+		assert.Equal(t, settings.Data["next_trx_id"].String(), "3")
+		assert.Equal(t, settings.Data["next_balances_id"].String(), "4")
+	})
+
+	t.Run("fails like the contract", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+		before := len(l.chain.Snapshot().Documents())
+
+		var unbalanced *accounting.ErrUnbalanced
+		err := l.upsert(t, nil, l.sale(t, "unbalanced", "50.00 USD", "40.00 USD"), true)
+		assert.Assert(t, errors.As(err, &unbalanced), err)
+		assert.Equal(t, unbalanced.Sum.String(), "10.00 USD")
+
+		var notAllowed *accounting.ErrCurrencyNotAllowed
+		err = l.upsert(t, nil, l.sale(t, "euros", "50.00 EUR", "50.00 EUR"), false)
+		assert.Assert(t, errors.As(err, &notAllowed), err)
+		assert.Equal(t, notAllowed.Symbol, "EUR")
+
+		var notLeaf *accounting.ErrNotLeaf
+		amount, _ := eos.NewAssetFromString("1.00 USD")
+		err = l.upsert(t, nil, accounting.NewTransactionBuilder(l.ledger).
+			Date(time.Now()).Memo("parent").Name("parent").
+			Debit(l.assets, amount, "assets").
+			Credit(l.sales, amount, "sales"), true)
+		assert.Assert(t, errors.As(err, &notLeaf), err)
+		assert.Equal(t, notLeaf.Account, l.assets.String())
+
+		var duplicate *accounting.ErrDuplicateAccountName
+		_, err = l.chain.CreateAcct(ctx, l.newAccount(l.assets, "1200", "CASH").ContentGroups())
+		assert.Assert(t, errors.As(err, &duplicate), err)
+
+		_, err = l.chain.As(eos.AN("mallory")).AddLedger(ctx, accounting.Ledger{Name: "Other"}.ContentGroups())
+		assert.Assert(t, errors.Is(err, accounting.ErrNotTrusted), err)
+
+		_, err = l.chain.AddCurrency(ctx, "4,USD")
+		assert.Assert(t, errors.Is(err, accounting.ErrDuplicateCurrency), err)
+
+		assert.Equal(t, len(l.chain.Snapshot().Documents()), before)
+		assert.Equal(t, len(l.transactions(t)), 0)
+	})
+
+	t.Run("replaces and deletes unapproved transactions", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+
+		assert.NilError(t, l.upsert(t, nil, l.sale(t, "draft", "50.00 USD", "50.00 USD"), false))
+		draft := l.transactions(t)["draft"]
+		assert.Assert(t, !draft.Approved())
+
+		assert.NilError(t, l.upsert(t, draft.Hash, l.sale(t, "final", "60.00 USD", "60.00 USD"), true))
+
+		transactions := l.transactions(t)
+		assert.Equal(t, len(transactions), 1)
+		final := transactions["final"]
+		assert.Equal(t, final.ID, draft.ID)
+		assert.Assert(t, final.Approved())
+
+		_, err := l.chain.Deletetrx(ctx, final.Hash)
+		var approved *accounting.ErrApproved
+		assert.Assert(t, errors.As(err, &approved), err)
+
+		assert.NilError(t, l.upsert(t, nil, l.sale(t, "mistake", "5.00 USD", "5.00 USD"), false))
+		before := len(l.chain.Snapshot().Documents())
+
+		_, err = l.chain.Deletetrx(ctx, l.transactions(t)["mistake"].Hash)
+		assert.NilError(t, err)
+		assert.Equal(t, len(l.chain.Snapshot().Documents()), before-3)
+		assert.Equal(t, len(l.transactions(t)), 1)
+	})
+
+	t.Run("deletes leaf accounts", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+
+		assert.NilError(t, l.upsert(t, nil, l.sale(t, "sales", "50.00 USD", "50.00 USD"), false))
+
+		_, err := l.chain.Deleteacc(ctx, l.assets)
+		var notLeaf *accounting.ErrNotLeaf
+		assert.Assert(t, errors.As(err, &notLeaf), err)
+
+		_, err = l.chain.Deleteacc(ctx, l.cash)
+		var hasComponents *accounting.ErrHasComponents
+		assert.Assert(t, errors.As(err, &hasComponents), err)
+
+		expenses := l.createAccount(t, l.ledger, "5000", "Expenses")
+		fees := l.createAccount(t, expenses, "5100", "Fees")
+
+		_, err = l.chain.Deleteacc(ctx, fees)
+		assert.NilError(t, err)
+
+		account, err := accounting.LoadAccount(ctx, l.chain, expenses)
+		assert.NilError(t, err)
+		assert.Assert(t, account.IsLeaf)
+
+		_, err = l.chain.Deleteacc(ctx, expenses)
+		assert.NilError(t, err)
+
+		children, err := accounting.ChildAccounts(ctx, l.chain, l.ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, len(children), 2)
+	})
+
+	t.Run("stores events and their cursors", func(t *testing.T) {
+
+		l := newFakeLedger(t)
+
+		for _, event := range []accounting.ExternalEvent{
+			{Source: "treasury", Cursor: "1", Details: map[string]string{"memo": "first"}},
+			{Source: "payroll", Cursor: "7"},
+			{Source: "treasury", Cursor: "2", Details: map[string]string{"memo": "second"}},
+		} {
+			_, err := l.chain.NewEvent(ctx, event.ContentGroups())
+			assert.NilError(t, err)
+		}
+
+		cursor, err := l.chain.GetCursorFromSource(ctx, "treasury")
+		assert.NilError(t, err)
+		assert.Equal(t, cursor, "2")
+
+		cursor, err = l.chain.GetLastCursor(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, cursor, "7")
+
+		event, err := l.chain.LastDocumentOfEdge(ctx, "event")
+		assert.NilError(t, err)
+
+		assert.NilError(t, l.upsert(t, nil, l.sale(t, "bound", "50.00 USD", "50.00 USD"), false))
+		component, err := l.chain.LastDocumentOfEdge(ctx, "component")
+		assert.NilError(t, err)
+
+		_, err = l.chain.BindEvent(ctx, event.Hash, component.Hash)
+		assert.NilError(t, err)
+
+		_, err = l.chain.BindEvent(ctx, event.Hash, component.Hash)
+		var bound *accounting.ErrAlreadyBound
+		assert.Assert(t, errors.As(err, &bound), err)
+
+		_, err = l.chain.UnbindEvent(ctx, event.Hash, component.Hash)
+		assert.NilError(t, err)
+	})
+}
@@ -0,0 +1,1160 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// CreateRoot creates the root document
+func (f *FakeChain) CreateRoot(ctx context.Context, notes string) (*TxResult, error) {
+	return f.exec(ctx, "createroot", func(tx *fakeTx) error {
+		tx.root()
+		return nil
+	})
+}
+
+// AddLedger creates a ledger and its transactions bucket
+func (f *FakeChain) AddLedger(ctx context.Context, ledger []docgraph.ContentGroup) (*TxResult, error) {
+	return f.exec(ctx, "addledger", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		r, err := read(ledger, detailsGroup)
+
+		if err != nil {
+			return err
+		}
+
+		name := r.text(ledgerNameLabel, true)
+
+		if r.err != nil {
+			return fakeAssert("%v", r.err)
+		}
+
+		document, err := tx.createDocument(tx.issuer, append(cloneGroups(ledger), fakeSystemGroup(name, "ledger")))
+
+		if err != nil {
+			return err
+		}
+
+		bucket, err := tx.createDocument(tx.issuer, []docgraph.ContentGroup{
+			newGroup(detailsGroup, timePointItem(createDateLabel, tx.now)),
+			fakeSystemGroup("Transactions Bucket", trxBucketEdge),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.createEdge(tx.issuer, document.Hash, bucket.Hash, trxBucketEdge); err != nil {
+			return err
+		}
+
+		return tx.createEdge(tx.issuer, tx.root().Hash, document.Hash, ledgerEdge)
+	})
+}
+
+// CreateAcct creates an account with its variable and balances documents
+func (f *FakeChain) CreateAcct(ctx context.Context, account []docgraph.ContentGroup) (*TxResult, error) {
+	return f.exec(ctx, "createacc", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		if _, ok := findGroup(account, detailsGroup); !ok {
+			return fakeAssert("Details group was expected but not found in account info")
+		}
+
+		r, _ := read(account, detailsGroup)
+
+		parent := r.checksum(parentAccountLabel, true)
+		accountType := r.integer(accountTypeLabel, true)
+		tagType := r.text(accountTagTypeLabel, true)
+		code := r.text(accountCodeLabel, true)
+		name := r.text(accountNameLabel, true)
+		ledger := r.checksum(ledgerAccountLabel, true)
+
+		if r.err != nil {
+			return fakeAssert("%v", r.err)
+		}
+
+		if name == "" {
+			return fakeAssert("Account name can not be empty.")
+		}
+
+		if accountType < int64(AccountTypeAsset) || accountType > int64(AccountTypeLoss) {
+			return fakeAssert("Invalid account type: %v", accountType)
+		}
+
+		if !tx.exists(parent) {
+			return fakeAssert("The parent document doesn't exists: %v", parent)
+		}
+
+		if siblings := tx.tables.edgesFrom(parent, accountEdge); len(siblings) > 0 {
+			for _, sibling := range siblings {
+
+				variable, err := tx.accountVariable(sibling.ToNode)
+
+				if err != nil {
+					return err
+				}
+
+				r := contentReader{group: findGroupOrEmpty(variable.ContentGroups, detailsGroup)}
+				siblingName := r.text(accountNameLabel, true)
+
+				if r.err != nil {
+					return fakeAssert("%v", r.err)
+				}
+
+				if strings.ToLower(siblingName) == strings.ToLower(name) {
+					return fakeAssert("There is already an account with name: %v", name)
+				}
+			}
+		} else if parent.String() != ledger.String() {
+
+			if tx.hasComponents(parent) {
+				return fakeAssert("Parent account already has associated components. Parent hash: %v", parent)
+			}
+
+			if err := tx.setLeaf(parent, false); err != nil {
+				return err
+			}
+		}
+
+		fixed, err := tx.createDocument(tx.issuer, []docgraph.ContentGroup{
+			newGroup(detailsGroup,
+				stringItem(accountTagTypeLabel, tagType),
+				stringItem(accountCodeLabel, code),
+				int64Item(accountTypeLabel, accountType),
+			),
+			fakeSystemGroup(name, "account"),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.insertAccountCode(code); err != nil {
+			return err
+		}
+
+		variable, err := tx.createDocument(tx.issuer, []docgraph.ContentGroup{
+			newGroup(detailsGroup,
+				stringItem(accountNameLabel, name),
+				stringItem(isLeafLabel, "true"),
+			),
+			fakeSystemGroup(name, "account_v", checksumItem(accountFixedLabel, fixed.Hash)),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.createEdge(tx.issuer, fixed.Hash, variable.Hash, accountVariableEdge); err != nil {
+			return err
+		}
+
+		balanceID, err := tx.settingInt("next_balances_id", 0)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.setSetting("next_balances_id", newFlexValue("int64", balanceID+1)); err != nil {
+			return err
+		}
+
+		balances, err := tx.createDocument(tx.issuer, []docgraph.ContentGroup{
+			newGroup(balancesGroup),
+			fakeSystemGroup(balancesGroup, balancesGroup,
+				timePointItem(createDateLabel, tx.now),
+				int64Item(balanceIDLabel, balanceID),
+				int64Item(numberOfUpdatesLabel, 0),
+			),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.createEdge(tx.issuer, fixed.Hash, balances.Hash, balancesEdge); err != nil {
+			return err
+		}
+
+		return tx.parent(tx.issuer, parent, fixed.Hash, accountEdge, ownedByEdge)
+	})
+}
+
+// Updateacc renames an account
+func (f *FakeChain) Updateacc(ctx context.Context, accountHash eos.Checksum256, accountInfo []docgraph.ContentGroup) (*TxResult, error) {
+	return f.exec(ctx, "updateacc", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		r, err := read(accountInfo, detailsGroup)
+
+		if err != nil {
+			return err
+		}
+
+		name := r.text(accountNameLabel, true)
+
+		if r.err != nil {
+			return fakeAssert("%v", r.err)
+		}
+
+		if name == "" {
+			return fakeAssert("An account name can not be empty.")
+		}
+
+		variable, err := tx.accountVariable(accountHash)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.updateGroup(variable.Hash, detailsGroup, stringItem(accountNameLabel, name))
+
+		return err
+	})
+}
+
+// Deleteacc deletes a leaf account without components nor balances
+func (f *FakeChain) Deleteacc(ctx context.Context, accountHash eos.Checksum256) (*TxResult, error) {
+	return f.exec(ctx, "deleteacc", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		if tx.hasComponents(accountHash) {
+			return fakeAssert("The account %v already has associated components, it can not be deleted.", accountHash)
+		}
+
+		variable, err := tx.accountVariable(accountHash)
+
+		if err != nil {
+			return err
+		}
+
+		r := contentReader{group: findGroupOrEmpty(variable.ContentGroups, detailsGroup)}
+		isLeaf := r.text(isLeafLabel, true)
+
+		if r.err != nil {
+			return fakeAssert("%v", r.err)
+		}
+
+		if isLeaf != "true" {
+			return fakeAssert("The account %v is not a leaf, it can not be deleted.", accountHash)
+		}
+
+		balances, err := tx.accountBalances(accountHash)
+
+		if err != nil {
+			return err
+		}
+
+		group, ok := findGroup(balances.ContentGroups, balancesGroup)
+
+		if !ok {
+			return fakeAssert("group %v is required", balancesGroup)
+		}
+
+		if len(group) > 1 {
+			return fakeAssert("The account %v already has balances associated with it, it can not be deleted.", accountHash)
+		}
+
+		owner, err := tx.edge(accountHash, ownedByEdge)
+
+		if err != nil {
+			return err
+		}
+
+		parent := owner.ToNode
+
+		tx.eraseDocument(accountHash)
+		tx.eraseDocument(variable.Hash)
+		tx.eraseDocument(balances.Hash)
+
+		for _, edge := range tx.tables.edgesFrom(tx.root().Hash, ledgerEdge) {
+			if edge.ToNode.String() == parent.String() {
+				return nil
+			}
+		}
+
+		if len(tx.tables.edgesFrom(parent, accountEdge)) > 0 {
+			return nil
+		}
+
+		return tx.setLeaf(parent, true)
+	})
+}
+
+// Upserttrx creates a transaction when trxHash is empty or replaces the
+// unapproved transaction trxHash keeping its id
+func (f *FakeChain) Upserttrx(ctx context.Context, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (*TxResult, error) {
+	return f.exec(ctx, "upserttrx", func(tx *fakeTx) error {
+		return tx.upsertTransaction(trxHash, trxInfo, approve, false)
+	})
+}
+
+// Crryconvtrx upserts a currency conversion transaction
+func (f *FakeChain) Crryconvtrx(ctx context.Context, trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve bool) (*TxResult, error) {
+	return f.exec(ctx, "crryconvtrx", func(tx *fakeTx) error {
+		return tx.upsertTransaction(trxHash, trxInfo, approve, true)
+	})
+}
+
+// Deletetrx deletes an unapproved transaction and its components
+func (f *FakeChain) Deletetrx(ctx context.Context, trxHash eos.Checksum256) (*TxResult, error) {
+	return f.exec(ctx, "deletetrx", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		return tx.deleteTransaction(trxHash)
+	})
+}
+
+// NewEvent stores an event and updates the cursor of its source
+func (f *FakeChain) NewEvent(ctx context.Context, event []docgraph.ContentGroup) (*TxResult, error) {
+	return f.exec(ctx, "newevent", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		bucket, err := tx.eventBucket()
+
+		if err != nil {
+			return err
+		}
+
+		r, err := read(event, detailsGroup)
+
+		if err != nil {
+			return err
+		}
+
+		source := r.text(eventSourceLabel, true)
+		cursor := r.text(eventCursorLabel, true)
+
+		if r.err != nil {
+			return fakeAssert("%v", r.err)
+		}
+
+		tx.upsertCursor(source, cursor)
+
+		document, err := tx.createDocument(tx.issuer, cloneGroups(event))
+
+		if err != nil {
+			return err
+		}
+
+		return tx.createEdge(tx.issuer, bucket.Hash, document.Hash, eventEdge)
+	})
+}
+
+// BindEvent links an event with a component
+func (f *FakeChain) BindEvent(ctx context.Context, eventHash, componentHash eos.Checksum256) (*TxResult, error) {
+	return f.exec(ctx, "bindevent", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		return tx.bindEvent(eventHash, componentHash)
+	})
+}
+
+// UnbindEvent removes the link between an event and a component of an
+// unapproved transaction
+func (f *FakeChain) UnbindEvent(ctx context.Context, eventHash, componentHash eos.Checksum256) (*TxResult, error) {
+	return f.exec(ctx, "unbindevent", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		transactions := tx.tables.edgesFrom(componentHash, transactionEdge)
+
+		if len(transactions) != 1 {
+			return fakeAssert("Missing transaction edge from component: %v", componentHash)
+		}
+
+		trxHash := transactions[0].ToNode
+
+		if len(tx.tables.edgesFrom(trxHash, unapprovedEdge)) == 0 {
+			return fakeAssert("Cannot unbind event from approved transaction: %v", trxHash)
+		}
+
+		for _, link := range []struct {
+			from, to eos.Checksum256
+			name     string
+		}{
+			{eventHash, componentHash, componentEdge},
+			{componentHash, eventHash, eventEdge},
+		} {
+			found := false
+
+			for _, edge := range tx.tables.edgesFrom(link.from, link.name) {
+				found = found || edge.ToNode.String() == link.to.String()
+			}
+
+			if !found {
+				return fakeAssert("edge does not exist: from %v to %v with name %v", link.from, link.to, link.name)
+			}
+
+			tx.removeEdges(func(edge docgraph.Edge) bool {
+				return edge.FromNode.String() == link.from.String() &&
+					edge.ToNode.String() == link.to.String() &&
+					string(edge.EdgeName) == link.name
+			})
+		}
+
+		return nil
+	})
+}
+
+// SetSetting adds or replaces a setting
+func (f *FakeChain) SetSetting(ctx context.Context, setting string, value docgraph.FlexValue) (*TxResult, error) {
+	return f.exec(ctx, "setsetting", func(tx *fakeTx) error {
+		return tx.setSetting(setting, &value)
+	})
+}
+
+// RemSetting removes a setting
+func (f *FakeChain) RemSetting(ctx context.Context, setting string) (*TxResult, error) {
+	return f.exec(ctx, "remsetting", func(tx *fakeTx) error {
+		return tx.updateSettings(func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error) {
+			return removeContent(groups, settingsDataGroup, func(item docgraph.ContentItem) bool {
+				return item.Label == setting
+			}, setting)
+		})
+	})
+}
+
+// AddTrustedAccount allows account to modify the ledgers
+func (f *FakeChain) AddTrustedAccount(ctx context.Context, account eos.AccountName) (*TxResult, error) {
+	return f.exec(ctx, "addtrustacnt", func(tx *fakeTx) error {
+		return tx.updateSettings(func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error) {
+
+			i := groupIndex(groups, trustedAccountsGroup)
+
+			if i < 0 {
+				groups = append(groups, newGroup(trustedAccountsGroup))
+				i = len(groups) - 1
+			}
+
+			for _, item := range groups[i] {
+				if trusted, err := flexName(item.Value); err == nil && item.Label == trustedAccountLabel && trusted == eos.Name(account) {
+					return nil, fakeAssert("Account is trusted already")
+				}
+			}
+
+			groups[i] = append(groups[i], nameItem(trustedAccountLabel, eos.Name(account)))
+
+			return groups, nil
+		})
+	})
+}
+
+// RemTrustedAccount revokes the trust of account
+func (f *FakeChain) RemTrustedAccount(ctx context.Context, account eos.AccountName) (*TxResult, error) {
+	return f.exec(ctx, "remtrustacnt", func(tx *fakeTx) error {
+		return tx.updateSettings(func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error) {
+			return removeContent(groups, trustedAccountsGroup, func(item docgraph.ContentItem) bool {
+				trusted, err := flexName(item.Value)
+				return err == nil && item.Label == trustedAccountLabel && trusted == eos.Name(account)
+			}, trustedAccountLabel)
+		})
+	})
+}
+
+// AddCurrency allows currency (i.e. "2,USD") to be used in transactions
+func (f *FakeChain) AddCurrency(ctx context.Context, currency string) (*TxResult, error) {
+
+	symbol, err := eos.StringToSymbol(currency)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding currency: %s", err)
+	}
+
+	return f.exec(ctx, "addcurrency", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		return tx.updateSettings(func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error) {
+
+			i := groupIndex(groups, allowedCurrenciesGroup)
+
+			if i < 0 {
+				groups = append(groups, newGroup(allowedCurrenciesGroup))
+				i = len(groups) - 1
+			}
+
+			for _, item := range groups[i] {
+				if item.Label != allowedCurrencyLabel {
+					continue
+				}
+				if allowed, err := flexAsset(item.Value); err == nil && allowed.Symbol.Symbol == symbol.Symbol {
+					return nil, fakeAssert("Currency symbol already exists.")
+				}
+			}
+
+			groups[i] = append(groups[i], assetItem(allowedCurrencyLabel, eos.Asset{Amount: 0, Symbol: symbol}))
+
+			return groups, nil
+		})
+	})
+}
+
+// AddCoinId attaches an external coin id to an allowed currency
+func (f *FakeChain) AddCoinId(ctx context.Context, currency, id string) (*TxResult, error) {
+
+	symbol, err := eos.StringToSymbol(currency)
+
+	if err != nil {
+		return nil, fmt.Errorf("error removing currency: %s", err)
+	}
+
+	return f.exec(ctx, "addcoinid", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		return tx.updateSettings(func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error) {
+
+			if i := groupIndex(groups, allowedCurrenciesGroup); i >= 0 && allowedCurrencyIndex(groups[i], symbol) >= 0 {
+				groups[i] = append(groups[i], stringItem(symbol.Symbol+coinIDSuffix, id))
+				return groups, nil
+			}
+
+			return nil, fakeAssert("There is no allowed currency with code %v.", symbol.Symbol)
+		})
+	})
+}
+
+// RemoveCurrency removes currency from the allowed currencies, its coin
+// ids are kept
+func (f *FakeChain) RemoveCurrency(ctx context.Context, currency string) (*TxResult, error) {
+
+	symbol, err := eos.StringToSymbol(currency)
+
+	if err != nil {
+		return nil, fmt.Errorf("error removing currency: %s", err)
+	}
+
+	return f.exec(ctx, "remcurrency", func(tx *fakeTx) error {
+
+		if err := tx.requireTrusted(tx.issuer); err != nil {
+			return err
+		}
+
+		return tx.updateSettings(func(groups []docgraph.ContentGroup) ([]docgraph.ContentGroup, error) {
+
+			if i := groupIndex(groups, allowedCurrenciesGroup); i >= 0 {
+				if j := allowedCurrencyIndex(groups[i], symbol); j >= 0 {
+					groups[i] = append(groups[i][:j], groups[i][j+1:]...)
+					return groups, nil
+				}
+			}
+
+			return nil, fakeAssert("There is no allowed currency with code %v.", symbol.Symbol)
+		})
+	})
+}
+
+func allowedCurrencyIndex(group docgraph.ContentGroup, symbol eos.Symbol) int {
+	for i, item := range group {
+		if item.Label != allowedCurrencyLabel {
+			continue
+		}
+		if allowed, err := flexAsset(item.Value); err == nil && allowed.Symbol.Symbol == symbol.Symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeContent removes the first item of the group labelled groupLabel
+// matching remove, like ContentWrapper::removeContent
+func removeContent(groups []docgraph.ContentGroup, label string, remove func(item docgraph.ContentItem) bool, content string) ([]docgraph.ContentGroup, error) {
+
+	i := groupIndex(groups, label)
+
+	if i < 0 {
+		return nil, fakeAssert("Can't remove content from unexisting group: %v", label)
+	}
+
+	for j, item := range groups[i] {
+		if item.Label != contentGroupLabel && remove(item) {
+			groups[i] = append(groups[i][:j], groups[i][j+1:]...)
+			return groups, nil
+		}
+	}
+
+	return nil, fakeAssert("Can't remove unexisting content [%v]", content)
+}
+
+func findGroupOrEmpty(groups []docgraph.ContentGroup, label string) docgraph.ContentGroup {
+	group, _ := findGroup(groups, label)
+	return group
+}
+
+func (tx *fakeTx) accountVariable(account eos.Checksum256) (docgraph.Document, error) {
+
+	edge, err := tx.edge(account, accountVariableEdge)
+
+	if err != nil {
+		return docgraph.Document{}, err
+	}
+
+	return tx.document(edge.ToNode)
+}
+
+func (tx *fakeTx) accountBalances(account eos.Checksum256) (docgraph.Document, error) {
+
+	edge, err := tx.edge(account, balancesEdge)
+
+	if err != nil {
+		return docgraph.Document{}, err
+	}
+
+	return tx.document(edge.ToNode)
+}
+
+// hasComponents tells if components point to account
+func (tx *fakeTx) hasComponents(account eos.Checksum256) bool {
+	return len(tx.tables.edgesTo(account, componentAccountEdge)) > 0
+}
+
+func (tx *fakeTx) setLeaf(account eos.Checksum256, leaf bool) error {
+
+	variable, err := tx.accountVariable(account)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.updateGroup(variable.Hash, detailsGroup, stringItem(isLeafLabel, fmt.Sprint(leaf)))
+
+	return err
+}
+
+// insertAccountCode records code in the account codes document. Like the
+// contract it replaces the previous code instead of appending it, so only
+// the code of the last account is checked.
+func (tx *fakeTx) insertAccountCode(code string) error {
+
+	codes, err := tx.accountCodes()
+
+	if err != nil {
+		return err
+	}
+
+	details, ok := findGroup(codes.ContentGroups, detailsGroup)
+
+	if !ok {
+		return nil
+	}
+
+	for _, item := range details[1:] {
+		if existing, err := flexString(item.Value); err == nil && item.Label == accountCodesLabel && existing == code {
+			return fakeAssert("account code %v already exists", code)
+		}
+	}
+
+	_, err = tx.updateGroup(codes.Hash, detailsGroup, stringItem(accountCodesLabel, code))
+
+	return err
+}
+
+func (tx *fakeTx) upsertCursor(source, cursor string) {
+
+	for i := range tx.tables.cursors {
+		if tx.tables.cursors[i].Source == source {
+			tx.tables.cursors[i].LastCursor = cursor
+			return
+		}
+	}
+
+	var key uint64
+	if n := len(tx.tables.cursors); n > 0 {
+		key = tx.tables.cursors[n-1].Key + 1
+	}
+
+	tx.tables.cursors = append(tx.tables.cursors, CursorRow{Key: key, Source: source, LastCursor: cursor})
+}
+
+func (tx *fakeTx) bindEvent(event, component eos.Checksum256) error {
+
+	if len(tx.tables.edgesFrom(event, componentEdge)) > 0 {
+		return fakeAssert("Event: %v is already binded to a component", event)
+	}
+
+	if len(tx.tables.edgesFrom(component, eventEdge)) > 0 {
+		return fakeAssert("Component: %v is already binded to an event", component)
+	}
+
+	return tx.parent(tx.issuer, event, component, componentEdge, eventEdge)
+}
+
+// fakeComponent is a component of the transaction input
+type fakeComponent struct {
+	account eos.Checksum256
+	memo    string
+	from    string
+	to      string
+	tagType string
+	amount  eos.Asset
+	event   eos.Checksum256
+}
+
+// readTransactionDetails checks the details of a transaction and returns
+// its ledger
+func readTransactionDetails(groups []docgraph.ContentGroup) (eos.Checksum256, error) {
+
+	r, err := read(groups, detailsGroup)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.text(trxMemoLabel, true)
+	r.text(trxNameLabel, true)
+	r.timePoint(trxDateLabel, true)
+	ledger := r.checksum(trxLedgerLabel, true)
+	r.integer(trxIDLabel, true)
+
+	if r.err != nil {
+		return nil, fakeAssert("%v", r.err)
+	}
+
+	return ledger, nil
+}
+
+// parseTransaction reads the ledger and the components of the transaction
+// input, like the Transaction constructor
+func parseTransaction(groups []docgraph.ContentGroup) (eos.Checksum256, []fakeComponent, error) {
+
+	ledger, err := readTransactionDetails(groups)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var components []fakeComponent
+
+	for i, group := range groups {
+
+		label := groupLabel(group)
+
+		if label == "" {
+			return nil, nil, fakeAssert("Unexpected content group withouh label: %v", i)
+		}
+
+		if label != componentGroup {
+			continue
+		}
+
+		r := contentReader{group: group}
+
+		component := fakeComponent{
+			memo:    r.text(componentMemoLabel, true),
+			account: r.checksum(componentAccountLabel, true),
+			from:    r.text(componentFromLabel, false),
+			to:      r.text(componentToLabel, true),
+			tagType: r.text(componentTypeLabel, true),
+		}
+
+		if r.err != nil {
+			return nil, nil, fakeAssert("%v", r.err)
+		}
+
+		if component.tagType != Debit && component.tagType != Credit {
+			return nil, nil, fakeAssert("Invalid component type:%v expected [%v or %v]", component.tagType, Debit, Credit)
+		}
+
+		component.amount = r.asset(componentAmountLabel, true)
+		component.event = r.checksum(componentEventLabel, false)
+
+		if r.err != nil {
+			return nil, nil, fakeAssert("%v", r.err)
+		}
+
+		components = append(components, component)
+	}
+
+	if len(components) == 0 {
+		return nil, nil, fakeAssert("Transaction must contain at least 1 component")
+	}
+
+	return ledger, components, nil
+}
+
+func (tx *fakeTx) upsertTransaction(trxHash eos.Checksum256, trxInfo []docgraph.ContentGroup, approve, conversion bool) error {
+
+	if err := tx.requireTrusted(tx.issuer); err != nil {
+		return err
+	}
+
+	null := true
+	for _, b := range trxHash {
+		null = null && b == 0
+	}
+
+	if null {
+		return tx.createTransaction(0, trxInfo, approve, conversion)
+	}
+
+	if len(tx.tables.edgesFrom(trxHash, unapprovedEdge)) == 0 {
+		return fakeAssert("Cannot modify an approved transaction: %v", trxHash)
+	}
+
+	trx, err := tx.document(trxHash)
+
+	if err != nil {
+		return err
+	}
+
+	r, err := read(trx.ContentGroups, detailsGroup)
+
+	if err != nil {
+		return err
+	}
+
+	id := r.integer(trxIDLabel, true)
+
+	if r.err != nil {
+		return fakeAssert("%v", r.err)
+	}
+
+	if err := tx.deleteTransaction(trxHash); err != nil {
+		return err
+	}
+
+	return tx.createTransaction(id, trxInfo, approve, conversion)
+}
+
+func (tx *fakeTx) createTransaction(id int64, trxInfo []docgraph.ContentGroup, approve, conversion bool) error {
+
+	if id == 0 {
+
+		next, err := tx.settingInt("next_trx_id", 1)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.setSetting("next_trx_id", newFlexValue("int64", next+1)); err != nil {
+			return err
+		}
+
+		id = next
+	}
+
+	groups := cloneGroups(trxInfo)
+	d := groupIndex(groups, detailsGroup)
+
+	if d < 0 {
+		return fakeAssert("group %v is required", detailsGroup)
+	}
+
+	groups[d] = insertOrReplace(groups[d], int64Item(trxIDLabel, id))
+
+	ledger, components, err := parseTransaction(groups)
+
+	if err != nil {
+		return err
+	}
+
+	if approve {
+
+		if !conversion {
+			if err := checkComponentsBalanced(components); err != nil {
+				return err
+			}
+		}
+
+		groups[d] = insertOrReplace(groups[d], nameItem(trxApproverLabel, eos.Name(tx.issuer)))
+
+		for _, component := range components {
+
+			amount := component.amount
+			if component.tagType == Credit {
+				amount = negateAsset(amount)
+			}
+
+			if err := tx.changeBalance(component.account, ledger, amount, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if conversion {
+
+		if len(components) != 2 {
+			return fakeAssert("a currency conversion must have 2 components")
+		}
+
+		from, to := components[0].amount, components[1].amount
+
+		groups[d] = insertOrReplace(groups[d], int64Item(trxConversionLabel, 1))
+
+		if from.Symbol.Symbol == to.Symbol.Symbol {
+			return fakeAssert("a currency conversion must use 2 different currencies, provided only %v", from.Symbol.Symbol)
+		}
+
+		groups[d] = insertOrReplace(groups[d], stringItem(from.Symbol.Symbol+"/"+to.Symbol.Symbol,
+			fmt.Sprintf("%f", assetFloat(from)/assetFloat(to))))
+		groups[d] = insertOrReplace(groups[d], stringItem(to.Symbol.Symbol+"/"+from.Symbol.Symbol,
+			fmt.Sprintf("%f", assetFloat(to)/assetFloat(from))))
+	}
+
+	trx, err := tx.createDocument(tx.issuer, []docgraph.ContentGroup{
+		groups[d],
+		fakeSystemGroup("Transaction", transactionEdge),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := tx.saveComponents(trx.Hash, components, approve); err != nil {
+		return err
+	}
+
+	bucket, err := tx.edge(ledger, trxBucketEdge)
+
+	if err != nil {
+		return err
+	}
+
+	edgeName := unapprovedEdge
+	if approve {
+		edgeName = approvedEdge
+	}
+
+	return tx.parent(tx.issuer, bucket.ToNode, trx.Hash, edgeName, edgeName)
+}
+
+// checkComponentsBalanced requires the signed amounts of every currency to
+// add up to zero, like Transaction::checkBalanced
+func checkComponentsBalanced(components []fakeComponent) error {
+
+	sums := make(assetSums)
+
+	for _, component := range components {
+		amount := component.amount
+		if component.tagType == Credit {
+			amount = negateAsset(amount)
+		}
+		sums.add(amount)
+	}
+
+	codes := make([]string, 0, len(sums))
+	for code := range sums {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if sums[code].Amount != 0 {
+			return fakeAssert("Transaction is unbalanced. Asset %v sums up to %v", code, sums[code])
+		}
+	}
+
+	return nil
+}
+
+func assetFloat(a eos.Asset) float64 {
+	return float64(a.Amount) / math.Pow10(int(a.Symbol.Precision))
+}
+
+// changeBalance adds amount to the balances of account and to the global
+// balances of its parents up to ledger, like changeAcctBalanceRecursively
+func (tx *fakeTx) changeBalance(account, ledger eos.Checksum256, amount eos.Asset, onlyGlobal bool) error {
+
+	if account.String() == ledger.String() {
+		return nil
+	}
+
+	balances, err := tx.accountBalances(account)
+
+	if err != nil {
+		return err
+	}
+
+	group, ok := findGroup(balances.ContentGroups, balancesGroup)
+
+	if !ok {
+		return fakeAssert("Missing balances group from balance document:%v", balances.Hash)
+	}
+
+	add := func(label string) (docgraph.ContentItem, error) {
+
+		total := amount
+
+		if value, ok := findItem(group, label); ok {
+			current, err := flexAsset(value)
+			if err != nil {
+				return docgraph.ContentItem{}, fakeAssert("%v: %v", label, err)
+			}
+			total = addAssetsAdjustingPrecision(current, amount)
+		}
+
+		return assetItem(label, total), nil
+	}
+
+	var items []docgraph.ContentItem
+
+	if !onlyGlobal {
+		item, err := add(accountBalancePrefix + amount.Symbol.Symbol)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	item, err := add(globalBalancePrefix + amount.Symbol.Symbol)
+
+	if err != nil {
+		return err
+	}
+
+	// The contract reads the number of updates from the system group, which
+	// is never updated, and writes it to the balances group
+	updates := int64(0)
+	if system, ok := findGroup(balances.ContentGroups, systemGroup); ok {
+		if value, ok := findItem(system, numberOfUpdatesLabel); ok {
+			updates, _ = flexInt64(value)
+		}
+	}
+
+	items = append(items, item, int64Item(numberOfUpdatesLabel, updates+1))
+
+	if _, err := tx.updateGroup(balances.Hash, balancesGroup, items...); err != nil {
+		return err
+	}
+
+	owner, err := tx.edge(account, ownedByEdge)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.changeBalance(owner.ToNode, ledger, amount, true)
+}
+
+func (tx *fakeTx) saveComponents(trxHash eos.Checksum256, components []fakeComponent, approved bool) error {
+
+	allowed, err := tx.allowedCurrencies()
+
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+
+		if component.amount.Amount < 0 {
+			return fakeAssert("Component amount must be a positive quantity.")
+		}
+
+		if !allowed[component.amount.Symbol.Symbol] {
+			return fakeAssert("Currency %v is not allowed.", component.amount.Symbol.Symbol)
+		}
+
+		variable, err := tx.accountVariable(component.account)
+
+		if err != nil {
+			return err
+		}
+
+		r := contentReader{group: findGroupOrEmpty(variable.ContentGroups, detailsGroup)}
+
+		if isLeaf := r.text(isLeafLabel, true); r.err != nil || isLeaf != "true" {
+			return fakeAssert("Only leafs are allowed to have associated components. Account %v is not a leaf.", component.account)
+		}
+
+		document, err := tx.createDocument(tx.issuer, []docgraph.ContentGroup{
+			newGroup(detailsGroup,
+				checksumItem(componentAccountLabel, component.account),
+				timePointItem(componentDateLabel, tx.now),
+				stringItem(componentMemoLabel, component.memo),
+				stringItem(componentFromLabel, component.from),
+				stringItem(componentToLabel, component.to),
+				stringItem(componentTypeLabel, component.tagType),
+				assetItem(componentAmountLabel, component.amount),
+			),
+			fakeSystemGroup("Component", componentEdge),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.parent(tx.issuer, trxHash, document.Hash, componentEdge, transactionEdge); err != nil {
+			return err
+		}
+
+		if len(component.event) > 0 {
+			if err := tx.bindEvent(component.event, document.Hash); err != nil {
+				return err
+			}
+		}
+
+		if approved {
+			err = tx.parent(tx.issuer, component.account, document.Hash, accountComponentEdge, componentAccountEdge)
+		} else {
+			err = tx.createEdge(tx.issuer, document.Hash, component.account, componentAccountEdge)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteTransaction erases an unapproved transaction and its components
+func (tx *fakeTx) deleteTransaction(trxHash eos.Checksum256) error {
+
+	if len(tx.tables.edgesFrom(trxHash, unapprovedEdge)) == 0 {
+		return fakeAssert("Cannot delete an approved transaction: %v", trxHash)
+	}
+
+	trx, err := tx.document(trxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := readTransactionDetails(trx.ContentGroups); err != nil {
+		return err
+	}
+
+	for _, edge := range tx.tables.edgesFrom(trxHash, componentEdge) {
+		tx.eraseDocument(edge.ToNode)
+	}
+
+	tx.eraseDocument(trxHash)
+
+	return nil
+}
@@ -0,0 +1,472 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture describes ledgers, currencies, trusted accounts and transactions
+// by symbolic names so the same data can be set up on any Chain. It is
+// written in YAML or JSON, i.e.
+//
+//	create_root: true
+//	trusted: [accounting]
+//	currencies: ["2,USD"]
+//	ledgers:
+//	  - name: Main
+//	    accounts:
+//	      - name: Assets
+//	        code: "1000"
+//	        type: asset
+//	        tag_type: DEBIT
+//	        accounts:
+//	          - {name: Cash, code: "1100", type: asset, tag_type: DEBIT}
+//	      - {name: Sales, code: "4100", type: revenue, tag_type: CREDIT}
+//	transactions:
+//	  - name: first sale
+//	    ledger: Main
+//	    date: 2021-04-01T00:00:00Z
+//	    memo: First sale
+//	    approve: true
+//	    components:
+//	      - {account: Cash, debit: 10.00 USD, memo: cash}
+//	      - {account: Sales, credit: 10.00 USD, memo: sales}
+//
+// Ledgers, accounts and transactions share a single namespace, so their
+// names must be unique within the fixture.
+type Fixture struct {
+	CreateRoot   bool                 `yaml:"create_root" json:"create_root"`
+	Trusted      []string             `yaml:"trusted" json:"trusted"`
+	Currencies   []string             `yaml:"currencies" json:"currencies"`
+	Ledgers      []FixtureLedger      `yaml:"ledgers" json:"ledgers"`
+	Transactions []FixtureTransaction `yaml:"transactions" json:"transactions"`
+}
+
+// FixtureLedger is a ledger and its tree of accounts
+type FixtureLedger struct {
+	Name     string           `yaml:"name" json:"name"`
+	Owner    string           `yaml:"owner" json:"owner"`
+	Accounts []FixtureAccount `yaml:"accounts" json:"accounts"`
+}
+
+// FixtureAccount is an account and its children
+type FixtureAccount struct {
+	Name     string           `yaml:"name" json:"name"`
+	Code     string           `yaml:"code" json:"code"`
+	Type     string           `yaml:"type" json:"type"`
+	TagType  string           `yaml:"tag_type" json:"tag_type"`
+	Accounts []FixtureAccount `yaml:"accounts" json:"accounts"`
+}
+
+// FixtureTransaction is a transaction of the ledger named Ledger
+type FixtureTransaction struct {
+	Name       string             `yaml:"name" json:"name"`
+	Ledger     string             `yaml:"ledger" json:"ledger"`
+	Date       string             `yaml:"date" json:"date"`
+	Memo       string             `yaml:"memo" json:"memo"`
+	Notes      string             `yaml:"notes" json:"notes"`
+	Approve    bool               `yaml:"approve" json:"approve"`
+	Components []FixtureComponent `yaml:"components" json:"components"`
+}
+
+// FixtureComponent debits or credits the account named Account, exactly one
+// of Debit and Credit is set
+type FixtureComponent struct {
+	Account string `yaml:"account" json:"account"`
+	Debit   string `yaml:"debit" json:"debit"`
+	Credit  string `yaml:"credit" json:"credit"`
+	Memo    string `yaml:"memo" json:"memo"`
+	From    string `yaml:"from" json:"from"`
+	To      string `yaml:"to" json:"to"`
+}
+
+// ReadFixture decodes a YAML or JSON fixture, failing on unknown fields
+func ReadFixture(r io.Reader) (*Fixture, error) {
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var fixture Fixture
+
+	if err := decoder.Decode(&fixture); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("unable to decode fixture: %v", err)
+	}
+
+	return &fixture, nil
+}
+
+// LoadFixture reads the fixture in path
+func LoadFixture(path string) (*Fixture, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	fixture, err := ReadFixture(file)
+
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	return fixture, nil
+}
+
+// Apply creates the contents of the fixture on chain as chain.Actor() and
+// returns the hashes of its ledgers, accounts and transactions by name
+func (f *Fixture) Apply(ctx context.Context, chain Chain) (map[string]eos.Checksum256, error) {
+
+	hashes := make(map[string]eos.Checksum256)
+
+	// the new accounts are found by code among their siblings
+	if err := checkFixtureCodes(f.Ledgers); err != nil {
+		return nil, err
+	}
+
+	if f.CreateRoot {
+		if _, err := chain.CreateRoot(ctx, string(chain.Contract())); err != nil {
+			return nil, fmt.Errorf("fixture root: %v", err)
+		}
+	}
+
+	for _, account := range f.Trusted {
+		if _, err := chain.AddTrustedAccount(ctx, eos.AN(account)); err != nil {
+			return nil, fmt.Errorf("fixture trusted account %v: %v", account, err)
+		}
+	}
+
+	for _, currency := range f.Currencies {
+		if _, err := chain.AddCurrency(ctx, currency); err != nil {
+			return nil, fmt.Errorf("fixture currency %v: %v", currency, err)
+		}
+	}
+
+	for _, ledger := range f.Ledgers {
+		if err := applyFixtureLedger(ctx, chain, ledger, hashes); err != nil {
+			return nil, fmt.Errorf("fixture ledger %v: %v", ledger.Name, err)
+		}
+	}
+
+	for _, trx := range f.Transactions {
+		if err := applyFixtureTransaction(ctx, chain, trx, hashes); err != nil {
+			return nil, fmt.Errorf("fixture transaction %v: %v", trx.Name, err)
+		}
+	}
+
+	return hashes, nil
+}
+
+// checkFixtureCodes fails when two accounts of ledgers have the same code,
+// the codes are unique across the ledgers of the contract
+func checkFixtureCodes(ledgers []FixtureLedger) error {
+
+	codes := make(map[string]string)
+
+	var check func(accounts []FixtureAccount) error
+
+	check = func(accounts []FixtureAccount) error {
+
+		for _, account := range accounts {
+
+			if other, ok := codes[account.Code]; ok {
+				return fmt.Errorf("fixture account %v: code %q is already used by account %v", account.Name, account.Code, other)
+			}
+
+			codes[account.Code] = account.Name
+
+			if err := check(account.Accounts); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, ledger := range ledgers {
+		if err := check(ledger.Accounts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addFixtureName records the hash of a new name
+func addFixtureName(hashes map[string]eos.Checksum256, name string, hash eos.Checksum256) error {
+
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if _, ok := hashes[name]; ok {
+		return fmt.Errorf("name %v is already used", name)
+	}
+
+	hashes[name] = hash
+	return nil
+}
+
+// fixtureHash returns the hash of name, which must be of an earlier entry
+func fixtureHash(hashes map[string]eos.Checksum256, name string) (eos.Checksum256, error) {
+
+	hash, ok := hashes[name]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown name %v", name)
+	}
+
+	return hash, nil
+}
+
+func applyFixtureLedger(ctx context.Context, chain Chain, ledger FixtureLedger, hashes map[string]eos.Checksum256) error {
+
+	if _, ok := hashes[ledger.Name]; ok {
+		return fmt.Errorf("name %v is already used", ledger.Name)
+	}
+
+	settings, err := chain.GetSettings(ctx)
+
+	if err != nil {
+		return fmt.Errorf("settings: %v", err)
+	}
+
+	if settings.Root == nil {
+		return fmt.Errorf("the settings have no root, create the root first")
+	}
+
+	// the ledger hash is computed by the contract, so the new ledger is
+	// the one the root was not linked to before
+	before, err := chain.EdgesFrom(ctx, settings.Root, ledgerEdge)
+
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+
+	for _, edge := range before {
+		existing[edge.ToNode.String()] = true
+	}
+
+	_, err = chain.AddLedger(ctx, Ledger{Name: ledger.Name, Owner: eos.Name(ledger.Owner)}.ContentGroups())
+
+	if err != nil {
+		return err
+	}
+
+	after, err := chain.EdgesFrom(ctx, settings.Root, ledgerEdge)
+
+	if err != nil {
+		return err
+	}
+
+	var hash eos.Checksum256
+
+	for _, edge := range after {
+		if !existing[edge.ToNode.String()] {
+			hash = edge.ToNode
+		}
+	}
+
+	if hash == nil {
+		return fmt.Errorf("ledger not found after addledger")
+	}
+
+	if err := addFixtureName(hashes, ledger.Name, hash); err != nil {
+		return err
+	}
+
+	return applyFixtureAccounts(ctx, chain, hash, hash, ledger.Accounts, hashes)
+}
+
+func applyFixtureAccounts(ctx context.Context, chain Chain, ledger, parent eos.Checksum256, accounts []FixtureAccount, hashes map[string]eos.Checksum256) error {
+
+	for _, account := range accounts {
+
+		if _, ok := hashes[account.Name]; ok {
+			return fmt.Errorf("account %v: name is already used", account.Name)
+		}
+
+		accountType, err := ParseAccountType(account.Type)
+
+		if err != nil {
+			return fmt.Errorf("account %v: %v", account.Name, err)
+		}
+
+		if account.TagType != Debit && account.TagType != Credit {
+			return fmt.Errorf("account %v: tag_type must be %v or %v, found %q", account.Name, Debit, Credit, account.TagType)
+		}
+
+		_, err = chain.CreateAcct(ctx, Account{
+			Name:    account.Name,
+			Code:    account.Code,
+			TagType: account.TagType,
+			Type:    accountType,
+			Parent:  parent,
+			Ledger:  ledger,
+		}.ContentGroups())
+
+		if err != nil {
+			return fmt.Errorf("account %v: %v", account.Name, err)
+		}
+
+		// the codes were checked to be unique, so the code finds the new child
+		children, err := ChildAccounts(ctx, chain, parent)
+
+		if err != nil {
+			return fmt.Errorf("account %v: %v", account.Name, err)
+		}
+
+		var hash eos.Checksum256
+
+		for _, child := range children {
+			if child.Code == account.Code {
+				hash = child.Hash
+			}
+		}
+
+		if hash == nil {
+			return fmt.Errorf("account %v: not found after createacct", account.Name)
+		}
+
+		if err := addFixtureName(hashes, account.Name, hash); err != nil {
+			return fmt.Errorf("account %v: %v", account.Name, err)
+		}
+
+		if err := applyFixtureAccounts(ctx, chain, ledger, hash, account.Accounts, hashes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyFixtureTransaction(ctx context.Context, chain Chain, trx FixtureTransaction, hashes map[string]eos.Checksum256) error {
+
+	if _, ok := hashes[trx.Name]; ok {
+		return fmt.Errorf("name %v is already used", trx.Name)
+	}
+
+	ledger, err := fixtureHash(hashes, trx.Ledger)
+
+	if err != nil {
+		return fmt.Errorf("ledger: %v", err)
+	}
+
+	date, err := time.Parse(time.RFC3339, trx.Date)
+
+	if err != nil {
+		return fmt.Errorf("date: %v", err)
+	}
+
+	b := NewTransactionBuilder(ledger).
+		Date(date).
+		Memo(trx.Memo).
+		Name(trx.Name).
+		Notes(trx.Notes)
+
+	for i, component := range trx.Components {
+
+		account, err := fixtureHash(hashes, component.Account)
+
+		if err != nil {
+			return fmt.Errorf("component %v: account: %v", i, err)
+		}
+
+		var opts []ComponentOption
+
+		if component.From != "" {
+			opts = append(opts, WithFrom(component.From))
+		}
+
+		if component.To != "" {
+			opts = append(opts, WithTo(component.To))
+		}
+
+		switch {
+		case component.Debit != "" && component.Credit == "":
+			amount, err := eos.NewAssetFromString(component.Debit)
+			if err != nil {
+				return fmt.Errorf("component %v: debit: %v", i, err)
+			}
+			b.Debit(account, amount, component.Memo, opts...)
+		case component.Credit != "" && component.Debit == "":
+			amount, err := eos.NewAssetFromString(component.Credit)
+			if err != nil {
+				return fmt.Errorf("component %v: credit: %v", i, err)
+			}
+			b.Credit(account, amount, component.Memo, opts...)
+		default:
+			return fmt.Errorf("component %v: exactly one of debit and credit is required", i)
+		}
+	}
+
+	trxInfo, err := b.Build()
+
+	if err != nil {
+		return err
+	}
+
+	bucket, err := singleEdgeFrom(ctx, chain, ledger, trxBucketEdge)
+
+	if err != nil {
+		return err
+	}
+
+	// the transaction hash is computed by the contract, so the new
+	// transaction is the one that was not in the bucket before
+	before, err := bucketTransactions(ctx, chain, bucket)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := chain.Upserttrx(ctx, nil, trxInfo, trx.Approve); err != nil {
+		return err
+	}
+
+	after, err := bucketTransactions(ctx, chain, bucket)
+
+	if err != nil {
+		return err
+	}
+
+	for key, hash := range after {
+		if _, ok := before[key]; !ok {
+			return addFixtureName(hashes, trx.Name, hash)
+		}
+	}
+
+	return fmt.Errorf("transaction not found after upserttrx")
+}
+
+// bucketTransactions returns the approved and unapproved transactions of a
+// transactions bucket
+func bucketTransactions(ctx context.Context, g Graph, bucket eos.Checksum256) (map[string]eos.Checksum256, error) {
+
+	transactions := make(map[string]eos.Checksum256)
+
+	for _, edgeName := range []string{approvedEdge, unapprovedEdge} {
+
+		edges, err := g.EdgesFrom(ctx, bucket, edgeName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, edge := range edges {
+			transactions[edge.ToNode.String()] = edge.ToNode
+		}
+	}
+
+	return transactions, nil
+}
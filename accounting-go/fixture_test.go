@@ -0,0 +1,215 @@
+package accounting_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+const salesFixture = `
+create_root: true
+trusted: [accounting]
+currencies: ["2,USD"]
+ledgers:
+  - name: Main
+    owner: tester
+    accounts:
+      - name: Assets
+        code: "1000"
+        type: asset
+        tag_type: DEBIT
+        accounts:
+          - {name: Cash, code: "1100", type: asset, tag_type: DEBIT}
+      - name: Revenue
+        code: "4000"
+        type: revenue
+        tag_type: CREDIT
+        accounts:
+          - {name: Sales, code: "4100", type: revenue, tag_type: CREDIT}
+transactions:
+  - name: April sales
+    ledger: Main
+    date: 2021-04-30T00:00:00Z
+    memo: April sales
+    approve: true
+    components:
+      - {account: Cash, debit: 50.00 USD, memo: cash}
+      - {account: Sales, credit: 50.00 USD, memo: sales, from: shop}
+  - name: May sales
+    ledger: Main
+    date: 2021-05-31T00:00:00Z
+    memo: May sales
+    components:
+      - {account: Cash, debit: 25.00 USD, memo: cash}
+      - {account: Sales, credit: 25.00 USD, memo: sales}
+`
+
+func TestFixture(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("applies ledgers, accounts and transactions", func(t *testing.T) {
+
+		fixture, err := accounting.ReadFixture(strings.NewReader(salesFixture))
+		assert.NilError(t, err)
+
+		chain := accounting.NewFakeChain(eos.AN("accounting"))
+
+		hashes, err := fixture.Apply(ctx, chain)
+		assert.NilError(t, err)
+		assert.Equal(t, len(hashes), 7)
+
+		ledger, err := chain.Document(ctx, hashes["Main"])
+		assert.NilError(t, err)
+		decoded, err := accounting.LedgerFromDocument(ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, decoded.Name, "Main")
+		assert.Equal(t, decoded.Owner, eos.Name("tester"))
+
+		children, err := accounting.ChildAccounts(ctx, chain, hashes["Assets"])
+		assert.NilError(t, err)
+		assert.Equal(t, len(children), 1)
+		assert.DeepEqual(t, children[0].Hash, hashes["Cash"])
+
+		sales, err := accounting.LoadAccount(ctx, chain, hashes["Sales"])
+		assert.NilError(t, err)
+		assert.Equal(t, sales.Code, "4100")
+		assert.Equal(t, sales.Type, accounting.AccountTypeRevenue)
+		assert.Equal(t, sales.TagType, accounting.Credit)
+
+		for name, approved := range map[string]bool{"April sales": true, "May sales": false} {
+			document, err := chain.Document(ctx, hashes[name])
+			assert.NilError(t, err)
+			trx, err := accounting.TransactionFromDocument(document)
+			assert.NilError(t, err)
+			assert.Equal(t, trx.Memo, name)
+			assert.Equal(t, trx.Approved(), approved)
+
+			components, err := chain.EdgesFrom(ctx, hashes[name], "component")
+			assert.NilError(t, err)
+			assert.Equal(t, len(components), 2)
+		}
+	})
+
+	t.Run("reads JSON", func(t *testing.T) {
+
+		fixture, err := accounting.ReadFixture(strings.NewReader(`{
+			"currencies": ["2,USD", "2,HUSD"],
+			"ledgers": [{"name": "Main", "accounts": [{"name": "Cash", "code": "1100", "type": "asset", "tag_type": "DEBIT"}]}]
+		}`))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, fixture.Currencies, []string{"2,USD", "2,HUSD"})
+		assert.Equal(t, fixture.Ledgers[0].Accounts[0].TagType, accounting.Debit)
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		_, err := accounting.ReadFixture(strings.NewReader("ledgers:\n  - name: Main\n    acounts: []\n"))
+		assert.ErrorContains(t, err, "acounts")
+	})
+
+	failures := []struct {
+		name    string
+		fixture string
+		err     string
+	}{
+		{
+			name: "duplicate names",
+			fixture: `
+ledgers:
+  - name: Main
+    accounts:
+      - {name: Main, code: "1000", type: asset, tag_type: DEBIT}
+`,
+			err: "fixture ledger Main: account Main: name is already used",
+		},
+		{
+			name: "unknown accounts",
+			fixture: `
+ledgers:
+  - name: Main
+transactions:
+  - name: sale
+    ledger: Main
+    date: 2021-04-30T00:00:00Z
+    components:
+      - {account: Cash, debit: 50.00 USD}
+`,
+			err: "fixture transaction sale: component 0: account: unknown name Cash",
+		},
+		{
+			name: "components debiting and crediting",
+			fixture: `
+ledgers:
+  - name: Main
+    accounts:
+      - {name: Cash, code: "1100", type: asset, tag_type: DEBIT}
+transactions:
+  - name: sale
+    ledger: Main
+    date: 2021-04-30T00:00:00Z
+    components:
+      - {account: Cash, debit: 50.00 USD, credit: 50.00 USD}
+`,
+			err: "fixture transaction sale: component 0: exactly one of debit and credit is required",
+		},
+		{
+			name: "duplicate codes",
+			fixture: `
+ledgers:
+  - name: Main
+    accounts:
+      - {name: Cash, code: "1100", type: asset, tag_type: DEBIT}
+  - name: Other
+    accounts:
+      - name: Assets
+        code: "1000"
+        type: asset
+        tag_type: DEBIT
+        accounts:
+          - {name: Bank, code: "1100", type: asset, tag_type: DEBIT}
+`,
+			err: `fixture account Bank: code "1100" is already used by account Cash`,
+		},
+		{
+			name: "contract failures",
+			fixture: `
+ledgers:
+  - name: Main
+    accounts:
+      - {name: Cash, code: "1100", type: asset, tag_type: DEBIT}
+      - {name: Sales, code: "4100", type: revenue, tag_type: CREDIT}
+transactions:
+  - name: sale
+    ledger: Main
+    date: 2021-04-30T00:00:00Z
+    components:
+      - {account: Cash, debit: 50.00 USD}
+      - {account: Sales, credit: 50.00 USD}
+`,
+			err: "fixture transaction sale: assertion failure with message: There are no allowed currencies",
+		},
+	}
+
+	for _, failure := range failures {
+		t.Run("fails on "+failure.name, func(t *testing.T) {
+
+			fixture, err := accounting.ReadFixture(strings.NewReader(failure.fixture))
+			assert.NilError(t, err)
+
+			chain := accounting.NewFakeChain(eos.AN("accounting"))
+
+			_, err = chain.CreateRoot(ctx, "accounting")
+			assert.NilError(t, err)
+
+			_, err = chain.AddTrustedAccount(ctx, eos.AN("accounting"))
+			assert.NilError(t, err)
+
+			_, err = fixture.Apply(ctx, chain)
+			assert.ErrorContains(t, err, failure.err)
+		})
+	}
+}
@@ -6,12 +6,10 @@ require (
 	github.com/alexeyco/simpletable v0.0.0-20200730140406-5bb24159ccfb
 	github.com/digital-scarcity/eos-go-test v0.0.0-20210421164317-b5d51aa34fbb
 	github.com/eoscanada/eos-go v0.9.1-0.20200805141443-a9d5402a7bc5
-	github.com/golang-collections/collections v0.0.0-20130729185459-604e922904d3
 	github.com/hypha-dao/dao-go v0.0.0-20201114163733-815f68275eca
 	github.com/hypha-dao/document-graph/docgraph v0.0.0-20210301235139-24626f87a02a
-	github.com/k0kubun/go-ansi v0.0.0-20180517002512-3bf9e2903213
-	github.com/schollz/progressbar/v3 v3.7.4
 	golang.org/x/mod v0.4.0 // indirect
 	golang.org/x/tools v0.0.0-20201218024724-ae774e9781d2 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 	gotest.tools v2.2.0+incompatible
 )
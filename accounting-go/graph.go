@@ -0,0 +1,158 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// Graph reads the documents and edges of the accounting contract, it is
+// implemented by Client and lets the readers of this package work on
+// other sources of documents
+type Graph interface {
+	Document(ctx context.Context, hash eos.Checksum256) (docgraph.Document, error)
+	// EdgesFrom and EdgesTo return the edges named edgeName leaving from
+	// or arriving to the document hash
+	EdgesFrom(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error)
+	EdgesTo(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error)
+}
+
+// Document loads the document hash from the contract
+func (c *Client) Document(ctx context.Context, hash eos.Checksum256) (docgraph.Document, error) {
+	return docgraph.LoadDocument(ctx, c.api, c.contract, hash.String())
+}
+
+// EdgesFrom returns the edges named edgeName leaving from the document hash
+func (c *Client) EdgesFrom(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	return docgraph.GetEdgesFromDocumentWithEdge(ctx, c.api, c.contract, docgraph.Document{Hash: hash}, eos.Name(edgeName))
+}
+
+// EdgesTo returns the edges named edgeName arriving to the document hash
+func (c *Client) EdgesTo(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	return docgraph.GetEdgesToDocumentWithEdge(ctx, c.api, c.contract, docgraph.Document{Hash: hash}, eos.Name(edgeName))
+}
+
+// singleEdgeFrom returns the node pointed by the only edge named edgeName
+// leaving from hash
+func singleEdgeFrom(ctx context.Context, g Graph, hash eos.Checksum256, edgeName string) (eos.Checksum256, error) {
+
+	edges, err := g.EdgesFrom(ctx, hash, edgeName)
+
+	if err != nil {
+		return nil, fmt.Errorf("%v edge from %v: %v", edgeName, hash, err)
+	}
+
+	if len(edges) != 1 {
+		return nil, fmt.Errorf("expected 1 %v edge from %v, found %v", edgeName, hash, len(edges))
+	}
+
+	return edges[0].ToNode, nil
+}
+
+// LoadAccount reads the fixed and variable documents of account
+func LoadAccount(ctx context.Context, g Graph, account eos.Checksum256) (Account, error) {
+	decoded, _, err := loadAccount(ctx, g, account)
+	return decoded, err
+}
+
+// loadAccount also returns the variable document of the account
+func loadAccount(ctx context.Context, g Graph, account eos.Checksum256) (Account, docgraph.Document, error) {
+
+	fixed, err := g.Document(ctx, account)
+
+	if err != nil {
+		return Account{}, docgraph.Document{}, fmt.Errorf("load account %v: %v", account, err)
+	}
+
+	variableHash, err := singleEdgeFrom(ctx, g, account, accountVariableEdge)
+
+	if err != nil {
+		return Account{}, docgraph.Document{}, fmt.Errorf("load account: %v", err)
+	}
+
+	variable, err := g.Document(ctx, variableHash)
+
+	if err != nil {
+		return Account{}, docgraph.Document{}, fmt.Errorf("load account variable %v: %v", variableHash, err)
+	}
+
+	decoded, err := AccountFromDocuments(fixed, variable)
+
+	if err != nil {
+		return Account{}, docgraph.Document{}, err
+	}
+
+	return decoded, variable, nil
+}
+
+// ChildAccounts returns the accounts hanging from parent, a ledger or an
+// account, sorted by code
+func ChildAccounts(ctx context.Context, g Graph, parent eos.Checksum256) ([]Account, error) {
+
+	edges, err := g.EdgesFrom(ctx, parent, accountEdge)
+
+	if err != nil {
+		return nil, fmt.Errorf("children of %v: %v", parent, err)
+	}
+
+	accounts := make([]Account, 0, len(edges))
+
+	for _, edge := range edges {
+
+		account, err := LoadAccount(ctx, g, edge.ToNode)
+
+		if err != nil {
+			return nil, err
+		}
+
+		account.Parent = parent
+		accounts = append(accounts, account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Code != accounts[j].Code {
+			return accounts[i].Code < accounts[j].Code
+		}
+		return accounts[i].Name < accounts[j].Name
+	})
+
+	return accounts, nil
+}
+
+// WalkAccounts visits the accounts of ledger depth first, parents before
+// their children and siblings sorted by code. path holds the names from
+// the top level account down to the visited one.
+func WalkAccounts(ctx context.Context, g Graph, ledger eos.Checksum256, visit func(account Account, path []string) error) error {
+
+	var walk func(parent eos.Checksum256, path []string) error
+
+	walk = func(parent eos.Checksum256, path []string) error {
+
+		children, err := ChildAccounts(ctx, g, parent)
+
+		if err != nil {
+			return err
+		}
+
+		for _, child := range children {
+
+			child.Ledger = ledger
+			childPath := append(append([]string(nil), path...), child.Name)
+
+			if err := visit(child, childPath); err != nil {
+				return err
+			}
+
+			if err := walk(child.Hash, childPath); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return walk(ledger, nil)
+}
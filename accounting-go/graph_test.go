@@ -0,0 +1,125 @@
+package accounting_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+// memoryGraph is an accounting.Graph kept in memory
+type memoryGraph struct {
+	documents map[string]docgraph.Document
+	edges     []docgraph.Edge
+}
+
+func newMemoryGraph() *memoryGraph {
+	return &memoryGraph{documents: make(map[string]docgraph.Document)}
+}
+
+func (g *memoryGraph) Document(ctx context.Context, hash eos.Checksum256) (docgraph.Document, error) {
+	document, ok := g.documents[hash.String()]
+	if !ok {
+		return docgraph.Document{}, fmt.Errorf("document not found %v", hash)
+	}
+	return document, nil
+}
+
+func (g *memoryGraph) EdgesFrom(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	var edges []docgraph.Edge
+	for _, edge := range g.edges {
+		if edge.FromNode.String() == hash.String() && string(edge.EdgeName) == edgeName {
+			edges = append(edges, edge)
+		}
+	}
+	return edges, nil
+}
+
+func (g *memoryGraph) EdgesTo(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	var edges []docgraph.Edge
+	for _, edge := range g.edges {
+		if edge.ToNode.String() == hash.String() && string(edge.EdgeName) == edgeName {
+			edges = append(edges, edge)
+		}
+	}
+	return edges, nil
+}
+
+func (g *memoryGraph) add(t *testing.T, key, data string) eos.Checksum256 {
+	document := strToDocument(t, data)
+	sum := sha256.Sum256([]byte(key))
+	document.Hash = eos.Checksum256(sum[:])
+	g.documents[document.Hash.String()] = document
+	return document.Hash
+}
+
+func (g *memoryGraph) link(from, to eos.Checksum256, edgeName string) {
+	g.edges = append(g.edges, docgraph.Edge{FromNode: from, ToNode: to, EdgeName: eos.Name(edgeName)})
+}
+
+func (g *memoryGraph) addLedger(t *testing.T, name string) eos.Checksum256 {
+	return g.add(t, "ledger/"+name, fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "name", "value": [ "string", "%v" ] }
+	]]
+}`, name))
+}
+
+func (g *memoryGraph) addAccount(t *testing.T, parent eos.Checksum256, code, name, tagType string, isLeaf bool) eos.Checksum256 {
+
+	account := g.add(t, "account/"+code, fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "account_tag_type", "value": [ "string", "%v" ] },
+		{ "label": "account_code", "value": [ "string", "%v" ] }
+	]]
+}`, tagType, code))
+
+	variable := g.add(t, "accountv/"+code, fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "account_name", "value": [ "string", "%v" ] },
+		{ "label": "is_leaf", "value": [ "string", "%v" ] }
+	]]
+}`, name, isLeaf))
+
+	g.link(parent, account, "account")
+	g.link(account, parent, "ownedby")
+	g.link(account, variable, "accountv")
+
+	return account
+}
+
+func TestWalkAccounts(t *testing.T) {
+
+	g := newMemoryGraph()
+	ledger := g.addLedger(t, "Main")
+	expenses := g.addAccount(t, ledger, "5000", "Expenses", accounting.Debit, false)
+	assets := g.addAccount(t, ledger, "1000", "Assets", accounting.Debit, false)
+	g.addAccount(t, assets, "1100", "Cash", accounting.Debit, true)
+	g.addAccount(t, expenses, "5100", "Salaries", accounting.Debit, true)
+
+	var visited []string
+
+	err := accounting.WalkAccounts(context.Background(), g, ledger, func(account accounting.Account, path []string) error {
+		visited = append(visited, fmt.Sprintf("%v %v", account.Code, path))
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, visited, []string{
+		"1000 [Assets]",
+		"1100 [Assets Cash]",
+		"5000 [Expenses]",
+		"5100 [Expenses Salaries]",
+	})
+
+	g.edges = g.edges[:len(g.edges)-1]
+	err = accounting.WalkAccounts(context.Background(), g, ledger, func(accounting.Account, []string) error { return nil })
+	assert.ErrorContains(t, err, "expected 1 accountv edge")
+}
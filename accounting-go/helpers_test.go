@@ -1,74 +1,12 @@
 package accounting_test
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
-	"testing"
-	"time"
 
-	eostest "github.com/digital-scarcity/eos-go-test"
-	eos "github.com/eoscanada/eos-go"
 	"github.com/hypha-dao/document-graph/docgraph"
-	"github.com/k0kubun/go-ansi"
-	progressbar "github.com/schollz/progressbar/v3"
 )
 
-type createRoot struct {
-	Notes string `json:"notes"`
-}
-
-func pause(t *testing.T, seconds time.Duration, headline, prefix string) {
-	if headline != "" {
-		t.Log(headline)
-	}
-
-	bar := progressbar.NewOptions(100,
-		progressbar.OptionSetWriter(ansi.NewAnsiStdout()),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionSetWidth(90),
-		// progressbar.OptionShowIts(),
-		progressbar.OptionSetDescription("[cyan]"+fmt.Sprintf("%20v", prefix)),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}))
-
-	chunk := seconds / 100
-	for i := 0; i < 100; i++ {
-		bar.Add(1)
-		time.Sleep(chunk)
-	}
-	fmt.Println()
-	fmt.Println()
-}
-
-func CreateRoot(ctx context.Context, api *eos.API, contract, creator eos.AccountName) (docgraph.Document, error) {
-	actions := []*eos.Action{{
-		Account: contract,
-		Name:    eos.ActN("createroot"),
-		Authorization: []eos.PermissionLevel{
-			{Actor: creator, Permission: eos.PN("active")},
-		},
-		ActionData: eos.NewActionData(createRoot{
-			Notes: "notes",
-		}),
-	}}
-	_, err := eostest.ExecWithRetry(ctx, api, actions)
-	if err != nil {
-		return docgraph.Document{}, fmt.Errorf("execute create root: %v", err)
-	}
-
-	lastDoc, err := docgraph.GetLastDocument(ctx, api, contract)
-	if err != nil {
-		return docgraph.Document{}, fmt.Errorf("get last document: %v", err)
-	}
-	return lastDoc, nil
-}
-
 func StrToContentGroups(data string) ([]docgraph.ContentGroup, error) {
 	var tempDoc docgraph.Document
 	err := json.Unmarshal([]byte(data), &tempDoc)
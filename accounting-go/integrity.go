@@ -0,0 +1,339 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// IntegritySeverity ranks the issues found by CheckGraph
+type IntegritySeverity int
+
+const (
+	// SeverityWarning is a state the contract does not produce but that
+	// does not break the actions or the balances
+	SeverityWarning IntegritySeverity = iota
+	// SeverityError is a state that breaks the actions of the contract or
+	// the balances read from the graph
+	SeverityError
+)
+
+var integritySeverityNames = []string{"warning", "error"}
+
+func (s IntegritySeverity) String() string {
+	if s < SeverityWarning || int(s) >= len(integritySeverityNames) {
+		return "unknown"
+	}
+	return integritySeverityNames[s]
+}
+
+// MarshalJSON encodes the severity by name
+func (s IntegritySeverity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// IntegrityCode identifies the structural check a document fails
+type IntegrityCode string
+
+const (
+	IntegrityDanglingEdge         IntegrityCode = "dangling_edge"
+	IntegrityAccountVariable      IntegrityCode = "account_variable"
+	IntegrityAccountBalances      IntegrityCode = "account_balances"
+	IntegrityComponentTransaction IntegrityCode = "component_transaction"
+	IntegrityMissingAcctcmp       IntegrityCode = "missing_acctcmp"
+	IntegrityTransactionBucket    IntegrityCode = "transaction_bucket"
+	IntegrityEventComponents      IntegrityCode = "event_components"
+	IntegrityDuplicateAccountCode IntegrityCode = "duplicate_account_code"
+	IntegrityNonLeafComponents    IntegrityCode = "non_leaf_components"
+	IntegrityOrphanDocument       IntegrityCode = "orphan_document"
+)
+
+var integritySeverities = map[IntegrityCode]IntegritySeverity{
+	IntegrityDanglingEdge:         SeverityError,
+	IntegrityAccountVariable:      SeverityError,
+	IntegrityAccountBalances:      SeverityError,
+	IntegrityComponentTransaction: SeverityError,
+	IntegrityMissingAcctcmp:       SeverityError,
+	IntegrityTransactionBucket:    SeverityError,
+	IntegrityEventComponents:      SeverityError,
+	IntegrityDuplicateAccountCode: SeverityError,
+	IntegrityNonLeafComponents:    SeverityWarning,
+	IntegrityOrphanDocument:       SeverityWarning,
+}
+
+// IntegrityIssue is a structural problem of a document of the graph
+type IntegrityIssue struct {
+	Severity IntegritySeverity `json:"severity"`
+	Code     IntegrityCode     `json:"code"`
+	Document eos.Checksum256   `json:"document"`
+	Message  string            `json:"message"`
+}
+
+func (i IntegrityIssue) String() string {
+	return fmt.Sprintf("%v %v %v: %v", i.Severity, i.Code, i.Document, i.Message)
+}
+
+// IntegrityReport lists the issues of a graph, the errors first
+type IntegrityReport struct {
+	Documents int              `json:"documents"`
+	Edges     int              `json:"edges"`
+	Issues    []IntegrityIssue `json:"issues"`
+}
+
+// Count returns the number of issues with severity
+func (r *IntegrityReport) Count(severity IntegritySeverity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// OK returns true when the graph has no errors
+func (r *IntegrityReport) OK() bool {
+	return r.Count(SeverityError) == 0
+}
+
+func (r *IntegrityReport) add(code IntegrityCode, document eos.Checksum256, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, IntegrityIssue{
+		Severity: integritySeverities[code],
+		Code:     code,
+		Document: document,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// CheckGraph checks the tables of the contract on the node
+func (c *Client) CheckGraph(ctx context.Context) (*IntegrityReport, error) {
+
+	s, err := c.ReadSnapshot(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return CheckGraph(ctx, s), nil
+}
+
+// documentType returns the type item of the system group of document
+func documentType(document docgraph.Document) string {
+
+	system, ok := findGroup(document.ContentGroups, systemGroup)
+
+	if !ok {
+		return ""
+	}
+
+	value, ok := findItem(system, typeLabel)
+
+	if !ok {
+		return ""
+	}
+
+	return value.String()
+}
+
+// detailsText returns a string item of the details group of document
+func detailsText(document docgraph.Document, label string) string {
+
+	details, ok := findGroup(document.ContentGroups, detailsGroup)
+
+	if !ok {
+		return ""
+	}
+
+	value, ok := findItem(details, label)
+
+	if !ok {
+		return ""
+	}
+
+	return value.String()
+}
+
+// CheckGraph looks for the structural problems of the accounting documents
+// of s: edges to missing documents, accounts without exactly one accountv
+// and balances edge, components without a transaction, approved components
+// not linked to their account, transactions outside the buckets, events
+// bound more than once, non-leaf accounts with components, duplicate
+// account codes and documents without edges.
+func CheckGraph(ctx context.Context, s *Snapshot) *IntegrityReport {
+
+	documents := s.Documents()
+	edges := s.Edges()
+
+	r := &IntegrityReport{
+		Documents: len(documents),
+		Edges:     len(edges),
+	}
+
+	for _, edge := range edges {
+		for _, node := range []eos.Checksum256{edge.FromNode, edge.ToNode} {
+			if _, err := s.Document(ctx, node); err != nil {
+				r.add(IntegrityDanglingEdge, node, "%v edge %v -> %v points to a missing document",
+					edge.EdgeName, edge.FromNode, edge.ToNode)
+			}
+		}
+	}
+
+	count := func(edges []docgraph.Edge, err error) int {
+		return len(edges)
+	}
+
+	codes := make(map[string][]docgraph.Document)
+
+	for _, document := range documents {
+
+		hash := document.Hash
+
+		switch documentType(document) {
+
+		case rootDocumentType:
+			continue
+
+		case accountDocumentType:
+
+			code := detailsText(document, accountCodeLabel)
+
+			// the name is in the variable document, the code alone
+			// identifies the accounts without one
+			account := code
+			variables, _ := s.EdgesFrom(ctx, hash, accountVariableEdge)
+
+			if len(variables) == 1 {
+				if variable, err := s.Document(ctx, variables[0].ToNode); err == nil {
+					account = strings.TrimSpace(code + " " + detailsText(variable, accountNameLabel))
+				}
+			}
+
+			if n := len(variables); n != 1 {
+				r.add(IntegrityAccountVariable, hash, "account %v has %v %v edges, expected 1", account, n, accountVariableEdge)
+			}
+
+			if n := count(s.EdgesFrom(ctx, hash, balancesEdge)); n != 1 {
+				r.add(IntegrityAccountBalances, hash, "account %v has %v %v edges, expected 1", account, n, balancesEdge)
+			}
+
+			children := count(s.EdgesFrom(ctx, hash, accountEdge))
+			components := count(s.EdgesTo(ctx, hash, componentAccountEdge))
+
+			if children > 0 && components > 0 {
+				r.add(IntegrityNonLeafComponents, hash, "account %v has %v child accounts and %v components", account, children, components)
+			}
+
+			codes[code] = append(codes[code], document)
+
+		case transactionDocumentType:
+
+			approved := count(s.EdgesTo(ctx, hash, approvedEdge))
+			unapproved := count(s.EdgesTo(ctx, hash, unapprovedEdge))
+
+			if approved+unapproved != 1 {
+				r.add(IntegrityTransactionBucket, hash, "transaction is in %v approved and %v unapproved buckets, expected 1", approved, unapproved)
+			}
+
+			if approved > 0 {
+
+				components, _ := s.EdgesFrom(ctx, hash, componentEdge)
+
+				for _, component := range components {
+					if count(s.EdgesTo(ctx, component.ToNode, accountComponentEdge)) == 0 {
+						r.add(IntegrityMissingAcctcmp, component.ToNode, "component of approved transaction %v has no %v edge from its account", hash, accountComponentEdge)
+					}
+				}
+			}
+
+		case componentDocumentType:
+
+			if n := count(s.EdgesFrom(ctx, hash, transactionEdge)); n != 1 {
+				r.add(IntegrityComponentTransaction, hash, "component has %v %v edges, expected 1", n, transactionEdge)
+			}
+		}
+
+		if len(s.AllEdgesFrom(hash)) == 0 && len(s.AllEdgesTo(hash)) == 0 {
+			r.add(IntegrityOrphanDocument, hash, "document %v has no edges", document.ID)
+		}
+	}
+
+	// the events are the documents linked to the events bucket, the
+	// components bound to them have event edges too
+	for _, bucket := range s.EdgesNamed(eventBucketEdge) {
+
+		events, _ := s.EdgesFrom(ctx, bucket.ToNode, eventEdge)
+
+		for _, event := range events {
+			if n := count(s.EdgesFrom(ctx, event.ToNode, componentEdge)); n > 1 {
+				r.add(IntegrityEventComponents, event.ToNode, "event is bound to %v components, expected at most 1", n)
+			}
+		}
+	}
+
+	for _, code := range sortedDocumentKeys(codes) {
+		if accounts := codes[code]; len(accounts) > 1 {
+			for _, account := range accounts {
+				r.add(IntegrityDuplicateAccountCode, account.Hash, "account code %v is used by %v accounts", code, len(accounts))
+			}
+		}
+	}
+
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		return r.Issues[i].Severity > r.Issues[j].Severity
+	})
+
+	return r
+}
+
+// sortedDocumentKeys returns the keys of documents sorted
+func sortedDocumentKeys(documents map[string][]docgraph.Document) []string {
+
+	keys := make([]string, 0, len(documents))
+
+	for key := range documents {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// WriteText writes a summary line and an issue per line, the errors first
+func (r *IntegrityReport) WriteText(w io.Writer) error {
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "documents: %v edges: %v errors: %v warnings: %v\n",
+		r.Documents, r.Edges, r.Count(SeverityError), r.Count(SeverityWarning))
+
+	for _, issue := range r.Issues {
+		out.WriteString(issue.String())
+		out.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write integrity report: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *IntegrityReport) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("write integrity report: %v", err)
+	}
+
+	return nil
+}
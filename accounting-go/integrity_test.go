@@ -0,0 +1,171 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+// issues returns the severity, code and document of the issues of s
+func issues(s *accounting.Snapshot) []string {
+
+	report := accounting.CheckGraph(context.Background(), s)
+
+	var found []string
+	for _, issue := range report.Issues {
+		found = append(found, issue.Severity.String()+" "+string(issue.Code)+" "+issue.Document.String())
+	}
+
+	return found
+}
+
+// withoutEdge returns edges without the edges named edgeName to hash
+func withoutEdge(edges []docgraph.Edge, edgeName string, hash eos.Checksum256) []docgraph.Edge {
+
+	var kept []docgraph.Edge
+
+	for _, edge := range edges {
+		if string(edge.EdgeName) != edgeName || edge.ToNode.String() != hash.String() {
+			kept = append(kept, edge)
+		}
+	}
+
+	return kept
+}
+
+func TestCheckGraph(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := newFakeLedger(t)
+
+	assert.NilError(t, l.upsert(t, nil, l.sale(t, "April sales", "50.00 USD", "50.00 USD"), true))
+	assert.NilError(t, l.upsert(t, nil, l.sale(t, "May sales", "25.00 USD", "25.00 USD"), false))
+
+	_, err := l.chain.NewEvent(ctx, accounting.ExternalEvent{Source: "treasury", Cursor: "1"}.ContentGroups())
+	assert.NilError(t, err)
+
+	event, err := l.chain.LastDocumentOfEdge(ctx, "event")
+	assert.NilError(t, err)
+
+	transactions := l.transactions(t)
+
+	approved, err := l.chain.EdgesFrom(ctx, transactions["April sales"].Hash, "component")
+	assert.NilError(t, err)
+
+	unapproved, err := l.chain.EdgesFrom(ctx, transactions["May sales"].Hash, "component")
+	assert.NilError(t, err)
+
+	_, err = l.chain.BindEvent(ctx, event.Hash, unapproved[0].ToNode)
+	assert.NilError(t, err)
+
+	snapshot := l.chain.Snapshot()
+	documents := snapshot.Documents()
+	edges := snapshot.Edges()
+
+	t.Run("accepts the graph of the contract", func(t *testing.T) {
+
+		report := accounting.CheckGraph(ctx, snapshot)
+
+		assert.Assert(t, report.OK())
+		assert.Equal(t, len(report.Issues), 0, report.Issues)
+		assert.Equal(t, report.Documents, len(documents))
+		assert.Equal(t, report.Edges, len(edges))
+	})
+
+	t.Run("reports missing edges", func(t *testing.T) {
+
+		balances, err := l.chain.EdgesFrom(ctx, l.cash, "balances")
+		assert.NilError(t, err)
+
+		corrupted := withoutEdge(edges, "balances", balances[0].ToNode)
+		corrupted = withoutEdge(corrupted, "acctcmp", approved[0].ToNode)
+		corrupted = withoutEdge(corrupted, "unapproved", transactions["May sales"].Hash)
+		corrupted = withoutEdge(corrupted, "transaction", transactions["May sales"].Hash)
+
+		assert.DeepEqual(t, issues(accounting.NewSnapshot(documents, corrupted)), []string{
+			"error account_balances " + l.cash.String(),
+			"error missing_acctcmp " + approved[0].ToNode.String(),
+			"error transaction_bucket " + transactions["May sales"].Hash.String(),
+			"error component_transaction " + unapproved[0].ToNode.String(),
+			"error component_transaction " + unapproved[1].ToNode.String(),
+			"warning orphan_document " + balances[0].ToNode.String(),
+		})
+
+		report := accounting.CheckGraph(ctx, accounting.NewSnapshot(documents, corrupted))
+		assert.Equal(t, report.Issues[0].Message, "account 1100 Cash has 0 balances edges, expected 1")
+	})
+
+	t.Run("reports transactions without edges", func(t *testing.T) {
+
+		may := transactions["May sales"].Hash
+
+		var corrupted []docgraph.Edge
+		for _, edge := range edges {
+			if edge.FromNode.String() != may.String() && edge.ToNode.String() != may.String() {
+				corrupted = append(corrupted, edge)
+			}
+		}
+
+		assert.DeepEqual(t, issues(accounting.NewSnapshot(documents, corrupted)), []string{
+			"error transaction_bucket " + may.String(),
+			"error component_transaction " + unapproved[0].ToNode.String(),
+			"error component_transaction " + unapproved[1].ToNode.String(),
+			"warning orphan_document " + may.String(),
+		})
+	})
+
+	t.Run("reports events bound twice and non-leaf accounts with components", func(t *testing.T) {
+
+		corrupted := append(append([]docgraph.Edge(nil), edges...),
+			docgraph.Edge{FromNode: event.Hash, ToNode: unapproved[1].ToNode, EdgeName: eos.Name("component")},
+			docgraph.Edge{FromNode: approved[0].ToNode, ToNode: l.assets, EdgeName: eos.Name("cmpacct")},
+		)
+
+		assert.DeepEqual(t, issues(accounting.NewSnapshot(documents, corrupted)), []string{
+			"error event_components " + event.Hash.String(),
+			"warning non_leaf_components " + l.assets.String(),
+		})
+	})
+
+	t.Run("reports dangling edges, duplicate codes and orphans", func(t *testing.T) {
+
+		cash, err := l.chain.Document(ctx, l.cash)
+		assert.NilError(t, err)
+
+		copied := cash
+		copied.ID = 1000
+		copied.Hash = bytes.Repeat([]byte{0xff}, 32)
+
+		missing := eos.Checksum256(bytes.Repeat([]byte{0xee}, 32))
+
+		corrupted := append(append([]docgraph.Edge(nil), edges...),
+			docgraph.Edge{FromNode: l.ledger, ToNode: missing, EdgeName: eos.Name("account")},
+		)
+
+		corruptedSnapshot := accounting.NewSnapshot(append(documents, copied), corrupted)
+		report := accounting.CheckGraph(ctx, corruptedSnapshot)
+
+		assert.Assert(t, !report.OK())
+		assert.Equal(t, report.Count(accounting.SeverityError), 5)
+		assert.Equal(t, report.Count(accounting.SeverityWarning), 1)
+
+		assert.DeepEqual(t, issues(corruptedSnapshot), []string{
+			"error dangling_edge " + missing.String(),
+			"error account_variable " + copied.Hash.String(),
+			"error account_balances " + copied.Hash.String(),
+			"error duplicate_account_code " + l.cash.String(),
+			"error duplicate_account_code " + copied.Hash.String(),
+			"warning orphan_document " + copied.Hash.String(),
+		})
+
+		var text bytes.Buffer
+		assert.NilError(t, report.WriteText(&text))
+		assert.Assert(t, bytes.Contains(text.Bytes(), []byte("errors: 5 warnings: 1")))
+	})
+}
@@ -0,0 +1,257 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// LedgerNode is an account of a LedgerTree
+type LedgerNode struct {
+	Account  Account           `json:"account"`
+	Variable docgraph.Document `json:"variable"`
+	// Balances is nil when the account has no balances edge, createacc
+	// always creates one so it only happens on a corrupt graph
+	Balances *Balances    `json:"balances,omitempty"`
+	Depth    int          `json:"depth"`
+	Children []LedgerNode `json:"children,omitempty"`
+}
+
+// LedgerTree holds the accounts of a ledger, siblings are sorted by code
+type LedgerTree struct {
+	Ledger   eos.Checksum256 `json:"ledger"`
+	Accounts []LedgerNode    `json:"accounts"`
+}
+
+// GetLedgerTree reads the accounts of ledger and their balances
+func (c *Client) GetLedgerTree(ctx context.Context, ledger eos.Checksum256) (*LedgerTree, error) {
+	return ReadLedgerTree(ctx, c, ledger)
+}
+
+// ReadLedgerTree reads the accounts of ledger and their balances from g
+func ReadLedgerTree(ctx context.Context, g Graph, ledger eos.Checksum256) (*LedgerTree, error) {
+
+	accounts, err := readLedgerNodes(ctx, g, ledger, ledger, 0)
+
+	if err != nil {
+		return nil, fmt.Errorf("ledger tree %v: %v", ledger, err)
+	}
+
+	return &LedgerTree{
+		Ledger:   ledger,
+		Accounts: accounts,
+	}, nil
+}
+
+func readLedgerNodes(ctx context.Context, g Graph, ledger, parent eos.Checksum256, depth int) ([]LedgerNode, error) {
+
+	edges, err := g.EdgesFrom(ctx, parent, accountEdge)
+
+	if err != nil {
+		return nil, fmt.Errorf("children of %v: %v", parent, err)
+	}
+
+	nodes := make([]LedgerNode, 0, len(edges))
+
+	for _, edge := range edges {
+
+		account, variable, err := loadAccount(ctx, g, edge.ToNode)
+
+		if err != nil {
+			return nil, err
+		}
+
+		account.Parent = parent
+		account.Ledger = ledger
+
+		balances, err := readBalances(ctx, g, account.Hash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		children, err := readLedgerNodes(ctx, g, ledger, account.Hash, depth+1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, LedgerNode{
+			Account:  account,
+			Variable: variable,
+			Balances: balances,
+			Depth:    depth,
+			Children: children,
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Account.Code != nodes[j].Account.Code {
+			return nodes[i].Account.Code < nodes[j].Account.Code
+		}
+		return nodes[i].Account.Name < nodes[j].Account.Name
+	})
+
+	return nodes, nil
+}
+
+// readBalances returns the balances document of account, nil if the
+// balances edge is missing
+func readBalances(ctx context.Context, g Graph, account eos.Checksum256) (*Balances, error) {
+
+	edges, err := g.EdgesFrom(ctx, account, balancesEdge)
+
+	if err != nil {
+		return nil, fmt.Errorf("balances edge from %v: %v", account, err)
+	}
+
+	switch len(edges) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("expected at most 1 balances edge from %v, found %v", account, len(edges))
+	}
+
+	document, err := g.Document(ctx, edges[0].ToNode)
+
+	if err != nil {
+		return nil, fmt.Errorf("load balances %v: %v", edges[0].ToNode, err)
+	}
+
+	balances, err := BalancesFromDocument(document)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &balances, nil
+}
+
+// Walk visits the accounts of the tree depth first, parents before their
+// children, until visit returns false
+func (t *LedgerTree) Walk(visit func(node *LedgerNode) bool) {
+
+	var walk func(nodes []LedgerNode) bool
+
+	walk = func(nodes []LedgerNode) bool {
+		for i := range nodes {
+			if !visit(&nodes[i]) || !walk(nodes[i].Children) {
+				return false
+			}
+		}
+		return true
+	}
+
+	walk(t.Accounts)
+}
+
+// Find returns the first account named name, nil if there is none
+func (t *LedgerTree) Find(name string) *LedgerNode {
+
+	var found *LedgerNode
+
+	t.Walk(func(node *LedgerNode) bool {
+		if node.Account.Name == name {
+			found = node
+		}
+		return found == nil
+	})
+
+	return found
+}
+
+// sortedBalances formats balances sorted by currency, e.g. "5.0000 TLOS, 1000.00 USD"
+func sortedBalances(balances map[string]eos.Asset) string {
+
+	currencies := sortedCurrencies(balances)
+	formatted := make([]string, len(currencies))
+
+	for i, currency := range currencies {
+		asset := balances[currency]
+		formatted[i] = asset.String()
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// WriteText writes the tree with an account per line, children are
+// indented with a tab
+func (t *LedgerTree) WriteText(w io.Writer) error {
+
+	var err error
+
+	t.Walk(func(node *LedgerNode) bool {
+
+		line := fmt.Sprintf("%v%v %v", strings.Repeat("\t", node.Depth), node.Account.Code, node.Account.Name)
+
+		if node.Balances != nil {
+			line += fmt.Sprintf(" account: [%v] global: [%v]",
+				sortedBalances(node.Balances.Account), sortedBalances(node.Balances.Global))
+		}
+
+		_, err = fmt.Fprintln(w, line)
+		return err == nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("write ledger tree: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the tree as indented JSON
+func (t *LedgerTree) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(t); err != nil {
+		return fmt.Errorf("write ledger tree: %v", err)
+	}
+
+	return nil
+}
+
+// WriteMarkdown writes the tree as a Markdown table, the account names are
+// indented by depth
+func (t *LedgerTree) WriteMarkdown(w io.Writer) error {
+
+	var out strings.Builder
+
+	out.WriteString("| Code | Account | Leaf | Account balances | Global balances |\n")
+	out.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	t.Walk(func(node *LedgerNode) bool {
+
+		accountBalances, globalBalances := "", ""
+
+		if node.Balances != nil {
+			accountBalances = sortedBalances(node.Balances.Account)
+			globalBalances = sortedBalances(node.Balances.Global)
+		}
+
+		fmt.Fprintf(&out, "| %v | %v%v | %v | %v | %v |\n",
+			node.Account.Code,
+			strings.Repeat("&nbsp;&nbsp;", node.Depth),
+			strings.ReplaceAll(node.Account.Name, "|", `\|`),
+			node.Account.IsLeaf,
+			accountBalances,
+			globalBalances)
+
+		return true
+	})
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write ledger tree: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+const ledger_tree_balances = `
+{
+	"content_groups": [
+		[
+			{ "label": "content_group_label", "value": [ "string", "balances" ] },
+			{ "label": "account_USD", "value": [ "asset", "1000.00 USD" ] },
+			{ "label": "global_USD", "value": [ "asset", "1000.00 USD" ] },
+			{ "label": "global_HUSD", "value": [ "asset", "-500.00 HUSD" ] }
+		]
+	]
+}`
+
+func TestLedgerTree(t *testing.T) {
+
+	g := newMemoryGraph()
+	ledger := g.addLedger(t, "Main")
+	expenses := g.addAccount(t, ledger, "5000", "Expenses", accounting.Debit, false)
+	marketing := g.addAccount(t, expenses, "5200", "Marketing", accounting.Debit, true)
+	g.addAccount(t, expenses, "5100", "Development", accounting.Debit, true)
+	g.link(marketing, g.add(t, "balances/5200", ledger_tree_balances), "balances")
+
+	tree, err := accounting.ReadLedgerTree(context.Background(), g, ledger)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(tree.Accounts), 1)
+	assert.Equal(t, len(tree.Accounts[0].Children), 2)
+	assert.Assert(t, tree.Accounts[0].Balances == nil)
+
+	node := tree.Find("Marketing")
+	assert.Assert(t, node != nil)
+	assert.Equal(t, node.Depth, 1)
+	assert.Equal(t, node.Account.Parent.String(), expenses.String())
+	assert.Equal(t, node.Balances.Account["USD"].String(), "1000.00 USD")
+	assert.Equal(t, node.Balances.Global["HUSD"].String(), "-500.00 HUSD")
+	assert.Equal(t, tree.Accounts[0].Children[0].Account.Name, "Development")
+	assert.Assert(t, tree.Find("Sales") == nil)
+
+	t.Run("Text", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NilError(t, tree.WriteText(&out))
+		assert.Equal(t, out.String(), "5000 Expenses\n"+
+			"\t5100 Development\n"+
+			"\t5200 Marketing account: [1000.00 USD] global: [-500.00 HUSD, 1000.00 USD]\n")
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NilError(t, tree.WriteMarkdown(&out))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, len(lines), 5)
+		assert.Equal(t, lines[4], "| 5200 | &nbsp;&nbsp;Marketing | true | 1000.00 USD | -500.00 HUSD, 1000.00 USD |")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NilError(t, tree.WriteJSON(&out))
+
+		var decoded struct {
+			Accounts []struct {
+				Account  accounting.Account `json:"account"`
+				Children []struct {
+					Depth    int                  `json:"depth"`
+					Balances *accounting.Balances `json:"balances"`
+				} `json:"children"`
+			} `json:"accounts"`
+		}
+		assert.NilError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, decoded.Accounts[0].Account.Code, "5000")
+		assert.Equal(t, decoded.Accounts[0].Children[1].Depth, 1)
+		assert.Equal(t, decoded.Accounts[0].Children[1].Balances.Account["USD"], eos.Asset{Amount: 100000, Symbol: eos.Symbol{Precision: 2, Symbol: "USD"}})
+	})
+}
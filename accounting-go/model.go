@@ -0,0 +1,721 @@
+package accounting
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// AccountType classifies an account, it mirrors ACCOUNT_GROUP in include/accounting.hpp
+type AccountType int64
+
+const (
+	// AccountTypeUnknown is decoded for the accounts created before the
+	// contract stored the account_type
+	AccountTypeUnknown AccountType = iota - 1
+	AccountTypeAsset
+	AccountTypeLiability
+	AccountTypeEquity
+	AccountTypeRevenue
+	AccountTypeExpense
+	AccountTypeGain
+	AccountTypeLoss
+)
+
+var accountTypeNames = []string{"Asset", "Liability", "Equity", "Revenue", "Expense", "Gain", "Loss"}
+
+func (t AccountType) String() string {
+	if t < AccountTypeAsset || int(t) >= len(accountTypeNames) {
+		return "Unknown"
+	}
+	return accountTypeNames[t]
+}
+
+// ParseAccountType parses an account type from its name (case insensitive) or number
+func ParseAccountType(s string) (AccountType, error) {
+	s = strings.TrimSpace(s)
+	for i, name := range accountTypeNames {
+		if strings.EqualFold(name, s) || fmt.Sprint(i) == s {
+			return AccountType(i), nil
+		}
+	}
+	return AccountTypeUnknown, fmt.Errorf("unknown account type: %v", s)
+}
+
+// MarshalJSON encodes the account type by name
+func (t AccountType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON decodes an account type from its name or number
+func (t *AccountType) UnmarshalJSON(data []byte) error {
+
+	var value interface{}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if s, ok := value.(string); ok && strings.EqualFold(s, AccountTypeUnknown.String()) {
+		*t = AccountTypeUnknown
+		return nil
+	}
+
+	accountType, err := ParseAccountType(strings.Trim(string(data), `"`))
+
+	if err != nil {
+		return err
+	}
+
+	*t = accountType
+	return nil
+}
+
+// Ledger is the root of a tree of accounts
+type Ledger struct {
+	Hash    eos.Checksum256 `json:"hash,omitempty"`
+	Creator eos.AccountName `json:"creator,omitempty"`
+	Name    string          `json:"name"`
+	Owner   eos.Name        `json:"owner,omitempty"`
+}
+
+// LedgerFromDocument decodes a ledger document
+func LedgerFromDocument(doc docgraph.Document) (Ledger, error) {
+
+	details, ok := findGroup(doc.ContentGroups, detailsGroup)
+
+	if !ok {
+		return Ledger{}, fmt.Errorf("ledger %v: missing details group", doc.Hash)
+	}
+
+	r := contentReader{group: details}
+
+	ledger := Ledger{
+		Hash:    doc.Hash,
+		Creator: doc.Creator,
+		Name:    r.text(ledgerNameLabel, true),
+		Owner:   r.name(ledgerOwnerLabel, false),
+	}
+
+	if r.err != nil {
+		return Ledger{}, fmt.Errorf("ledger %v: %v", doc.Hash, r.err)
+	}
+
+	return ledger, nil
+}
+
+// ContentGroups encodes the ledger as expected by the addledger action
+func (l Ledger) ContentGroups() []docgraph.ContentGroup {
+
+	details := newGroup(detailsGroup)
+
+	if l.Owner != "" {
+		details = append(details, nameItem(ledgerOwnerLabel, l.Owner))
+	}
+
+	details = append(details, stringItem(ledgerNameLabel, l.Name))
+
+	return []docgraph.ContentGroup{details}
+}
+
+// Account merges the fixed account document with its variable (accountv) document
+type Account struct {
+	Hash         eos.Checksum256 `json:"hash,omitempty"`
+	VariableHash eos.Checksum256 `json:"variable_hash,omitempty"`
+	Creator      eos.AccountName `json:"creator,omitempty"`
+	Name         string          `json:"name"`
+	Code         string          `json:"code"`
+	TagType      string          `json:"tag_type"`
+	Type         AccountType     `json:"type"`
+	IsLeaf       bool            `json:"is_leaf"`
+	// Parent and Ledger are only part of the createacc input,
+	// they are not stored in the account documents
+	Parent eos.Checksum256 `json:"parent,omitempty"`
+	Ledger eos.Checksum256 `json:"ledger,omitempty"`
+}
+
+// AccountFromDocuments decodes an account from its fixed and variable documents
+func AccountFromDocuments(fixed, variable docgraph.Document) (Account, error) {
+
+	fixedDetails, ok := findGroup(fixed.ContentGroups, detailsGroup)
+
+	if !ok {
+		return Account{}, fmt.Errorf("account %v: missing details group", fixed.Hash)
+	}
+
+	variableDetails, ok := findGroup(variable.ContentGroups, detailsGroup)
+
+	if !ok {
+		return Account{}, fmt.Errorf("account variable %v: missing details group", variable.Hash)
+	}
+
+	fr := contentReader{group: fixedDetails}
+	vr := contentReader{group: variableDetails}
+
+	account := Account{
+		Hash:         fixed.Hash,
+		VariableHash: variable.Hash,
+		Creator:      fixed.Creator,
+		Code:         fr.text(accountCodeLabel, true),
+		TagType:      fr.text(accountTagTypeLabel, true),
+		Type:         AccountTypeUnknown,
+		Name:         vr.text(accountNameLabel, true),
+		IsLeaf:       vr.text(isLeafLabel, true) == "true",
+	}
+
+	if value, ok := findItem(fixedDetails, accountTypeLabel); ok {
+		accountType, err := flexInt64(value)
+		if err != nil {
+			fr.fail(accountTypeLabel, err)
+		}
+		account.Type = AccountType(accountType)
+	}
+
+	if fr.err != nil {
+		return Account{}, fmt.Errorf("account %v: %v", fixed.Hash, fr.err)
+	}
+
+	if vr.err != nil {
+		return Account{}, fmt.Errorf("account variable %v: %v", variable.Hash, vr.err)
+	}
+
+	return account, nil
+}
+
+// ContentGroups encodes the account as expected by the createacc action
+func (a Account) ContentGroups() []docgraph.ContentGroup {
+	return []docgraph.ContentGroup{
+		newGroup(detailsGroup,
+			stringItem(accountNameLabel, a.Name),
+			int64Item(accountTypeLabel, int64(a.Type)),
+			stringItem(accountTagTypeLabel, a.TagType),
+			stringItem(accountCodeLabel, a.Code),
+			checksumItem(parentAccountLabel, a.Parent),
+			checksumItem(ledgerAccountLabel, a.Ledger),
+		),
+	}
+}
+
+// Balances holds the balances document of an account. Account balances
+// come from components of the account itself while global balances also
+// include the components of its descendants. Both are keyed by symbol code.
+type Balances struct {
+	Hash            eos.Checksum256      `json:"hash,omitempty"`
+	Account         map[string]eos.Asset `json:"account"`
+	Global          map[string]eos.Asset `json:"global"`
+	BalanceID       int64                `json:"balance_id"`
+	NumberOfUpdates int64                `json:"number_of_updates"`
+}
+
+// BalancesFromDocument decodes a balances document
+func BalancesFromDocument(doc docgraph.Document) (Balances, error) {
+
+	group, ok := findGroup(doc.ContentGroups, balancesGroup)
+
+	if !ok {
+		return Balances{}, fmt.Errorf("balances %v: missing balances group", doc.Hash)
+	}
+
+	balances := Balances{
+		Hash:    doc.Hash,
+		Account: make(map[string]eos.Asset),
+		Global:  make(map[string]eos.Asset),
+	}
+
+	r := contentReader{group: group}
+
+	for _, item := range group {
+		switch {
+		case strings.HasPrefix(item.Label, accountBalancePrefix):
+			balances.Account[strings.TrimPrefix(item.Label, accountBalancePrefix)] = r.asset(item.Label, true)
+		case strings.HasPrefix(item.Label, globalBalancePrefix):
+			balances.Global[strings.TrimPrefix(item.Label, globalBalancePrefix)] = r.asset(item.Label, true)
+		}
+	}
+
+	// The contract stores the updates counter in the balances group once the
+	// account has been updated, before that only the system group has it
+	updates, hasUpdates := findItem(group, numberOfUpdatesLabel)
+
+	if system, ok := findGroup(doc.ContentGroups, systemGroup); ok {
+		sr := contentReader{group: system}
+		balances.BalanceID = sr.integer(balanceIDLabel, false)
+		if !hasUpdates {
+			balances.NumberOfUpdates = sr.integer(numberOfUpdatesLabel, false)
+		}
+		if sr.err != nil {
+			r.fail(systemGroup, sr.err)
+		}
+	}
+
+	if hasUpdates {
+		n, err := flexInt64(updates)
+		if err != nil {
+			r.fail(numberOfUpdatesLabel, err)
+		}
+		balances.NumberOfUpdates = n
+	}
+
+	if r.err != nil {
+		return Balances{}, fmt.Errorf("balances %v: %v", doc.Hash, r.err)
+	}
+
+	return balances, nil
+}
+
+// ContentGroups encodes the balances document
+func (b Balances) ContentGroups() []docgraph.ContentGroup {
+
+	group := newGroup(balancesGroup)
+
+	for _, code := range sortedAssetKeys(b.Account) {
+		group = append(group, assetItem(accountBalancePrefix+code, b.Account[code]))
+	}
+
+	for _, code := range sortedAssetKeys(b.Global) {
+		group = append(group, assetItem(globalBalancePrefix+code, b.Global[code]))
+	}
+
+	if b.NumberOfUpdates > 0 {
+		group = append(group, int64Item(numberOfUpdatesLabel, b.NumberOfUpdates))
+	}
+
+	return []docgraph.ContentGroup{
+		group,
+		newGroup(systemGroup,
+			stringItem(nodeLabel, balancesGroup),
+			stringItem(typeLabel, balancesGroup),
+			int64Item(balanceIDLabel, b.BalanceID),
+		),
+	}
+}
+
+// Component is a single debit or credit of a transaction
+type Component struct {
+	Hash       eos.Checksum256 `json:"hash,omitempty"`
+	Account    eos.Checksum256 `json:"account"`
+	Amount     eos.Asset       `json:"amount"`
+	Memo       string          `json:"memo"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Type       string          `json:"type"`
+	Event      eos.Checksum256 `json:"event,omitempty"`
+	CreateDate eos.TimePoint   `json:"create_date,omitempty"`
+}
+
+func componentFromGroup(group docgraph.ContentGroup) (Component, error) {
+
+	r := contentReader{group: group}
+
+	component := Component{
+		Account:    r.checksum(componentAccountLabel, true),
+		Amount:     r.asset(componentAmountLabel, true),
+		Memo:       r.text(componentMemoLabel, true),
+		From:       r.text(componentFromLabel, false),
+		To:         r.text(componentToLabel, false),
+		Type:       r.text(componentTypeLabel, true),
+		Event:      r.checksum(componentEventLabel, false),
+		CreateDate: r.timePoint(componentDateLabel, false),
+	}
+
+	return component, r.err
+}
+
+// ComponentFromDocument decodes a component document. The event a component
+// is bound to is stored as an edge so it is not part of the document.
+func ComponentFromDocument(doc docgraph.Document) (Component, error) {
+
+	details, ok := findGroup(doc.ContentGroups, detailsGroup)
+
+	if !ok {
+		return Component{}, fmt.Errorf("component %v: missing details group", doc.Hash)
+	}
+
+	component, err := componentFromGroup(details)
+
+	if err != nil {
+		return Component{}, fmt.Errorf("component %v: %v", doc.Hash, err)
+	}
+
+	component.Hash = doc.Hash
+
+	return component, nil
+}
+
+// ContentGroup encodes the component as a 'component' group of a transaction
+func (c Component) ContentGroup() docgraph.ContentGroup {
+
+	group := newGroup(componentGroup,
+		stringItem(componentMemoLabel, c.Memo),
+		checksumItem(componentAccountLabel, c.Account),
+		assetItem(componentAmountLabel, c.Amount),
+		stringItem(componentFromLabel, c.From),
+		stringItem(componentToLabel, c.To),
+		stringItem(componentTypeLabel, c.Type),
+	)
+
+	if len(c.Event) > 0 {
+		group = append(group, checksumItem(componentEventLabel, c.Event))
+	}
+
+	return group
+}
+
+// Transaction groups balanced components under a ledger
+type Transaction struct {
+	Hash       eos.Checksum256 `json:"hash,omitempty"`
+	ID         int64           `json:"id,omitempty"`
+	Ledger     eos.Checksum256 `json:"ledger"`
+	Date       eos.TimePoint   `json:"date"`
+	Memo       string          `json:"memo"`
+	Name       string          `json:"name,omitempty"`
+	Notes      string          `json:"notes,omitempty"`
+	ApprovedBy eos.Name        `json:"approved_by,omitempty"`
+	// CurrencyConversion is set on the transactions of crryconvtrx, their
+	// two components are in different currencies and are not balanced
+	CurrencyConversion bool        `json:"currency_conversion,omitempty"`
+	Components         []Component `json:"components"`
+}
+
+// Approved tells if the transaction was approved when it was stored
+func (t Transaction) Approved() bool {
+	return t.ApprovedBy != ""
+}
+
+func transactionFromDetails(details docgraph.ContentGroup) (Transaction, error) {
+
+	r := contentReader{group: details}
+
+	trx := Transaction{
+		ID:         r.integer(trxIDLabel, false),
+		Ledger:     r.checksum(trxLedgerLabel, true),
+		Date:       r.timePoint(trxDateLabel, true),
+		Memo:       r.text(trxMemoLabel, true),
+		Name:       r.text(trxNameLabel, false),
+		Notes:      r.text(trxNotesLabel, false),
+		ApprovedBy: r.name(trxApproverLabel, false),
+	}
+
+	trx.CurrencyConversion = r.integer(trxConversionLabel, false) == 1
+
+	return trx, r.err
+}
+
+// TransactionFromDocument decodes a transaction document. Components are
+// stored in their own documents and have to be decoded with ComponentFromDocument.
+func TransactionFromDocument(doc docgraph.Document) (Transaction, error) {
+
+	details, ok := findGroup(doc.ContentGroups, detailsGroup)
+
+	if !ok {
+		return Transaction{}, fmt.Errorf("transaction %v: missing details group", doc.Hash)
+	}
+
+	trx, err := transactionFromDetails(details)
+
+	if err != nil {
+		return Transaction{}, fmt.Errorf("transaction %v: %v", doc.Hash, err)
+	}
+
+	trx.Hash = doc.Hash
+
+	return trx, nil
+}
+
+// TransactionFromContentGroups decodes the trx_info of an upserttrx action
+func TransactionFromContentGroups(groups []docgraph.ContentGroup) (Transaction, error) {
+
+	details, ok := findGroup(groups, detailsGroup)
+
+	if !ok {
+		return Transaction{}, fmt.Errorf("transaction: missing details group")
+	}
+
+	trx, err := transactionFromDetails(details)
+
+	if err != nil {
+		return Transaction{}, fmt.Errorf("transaction: %v", err)
+	}
+
+	for i, group := range groups {
+		if groupLabel(group) != componentGroup {
+			continue
+		}
+
+		component, err := componentFromGroup(group)
+
+		if err != nil {
+			return Transaction{}, fmt.Errorf("transaction component at %v: %v", i, err)
+		}
+
+		trx.Components = append(trx.Components, component)
+	}
+
+	return trx, nil
+}
+
+// ContentGroups encodes the transaction as expected by the upserttrx action
+func (t Transaction) ContentGroups() []docgraph.ContentGroup {
+
+	details := newGroup(detailsGroup,
+		timePointItem(trxDateLabel, t.Date),
+		checksumItem(trxLedgerLabel, t.Ledger),
+		stringItem(trxMemoLabel, t.Memo),
+		stringItem(trxNameLabel, t.Name),
+	)
+
+	if t.Notes != "" {
+		details = append(details, stringItem(trxNotesLabel, t.Notes))
+	}
+
+	if t.ID != 0 {
+		details = append(details, int64Item(trxIDLabel, t.ID))
+	}
+
+	if t.ApprovedBy != "" {
+		details = append(details, nameItem(trxApproverLabel, t.ApprovedBy))
+	}
+
+	if t.CurrencyConversion {
+		details = append(details, int64Item(trxConversionLabel, 1))
+	}
+
+	groups := []docgraph.ContentGroup{details}
+
+	for _, component := range t.Components {
+		groups = append(groups, component.ContentGroup())
+	}
+
+	return groups
+}
+
+// ExternalEvent is an external event (i.e. a treasury transfer) that can be
+// bound to a component. Besides source and cursor the details are free form.
+type ExternalEvent struct {
+	Hash    eos.Checksum256   `json:"hash,omitempty"`
+	Creator eos.AccountName   `json:"creator,omitempty"`
+	Source  string            `json:"source"`
+	Cursor  string            `json:"cursor"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// ExternalEventFromDocument decodes an event document
+func ExternalEventFromDocument(doc docgraph.Document) (ExternalEvent, error) {
+
+	details, ok := findGroup(doc.ContentGroups, detailsGroup)
+
+	if !ok {
+		return ExternalEvent{}, fmt.Errorf("event %v: missing details group", doc.Hash)
+	}
+
+	r := contentReader{group: details}
+
+	event := ExternalEvent{
+		Hash:    doc.Hash,
+		Creator: doc.Creator,
+		Source:  r.text(eventSourceLabel, true),
+		Cursor:  r.text(eventCursorLabel, true),
+		Details: make(map[string]string),
+	}
+
+	for _, item := range details {
+		switch item.Label {
+		case contentGroupLabel, eventSourceLabel, eventCursorLabel:
+			continue
+		}
+		if item.Value != nil {
+			event.Details[item.Label] = item.Value.String()
+		}
+	}
+
+	if r.err != nil {
+		return ExternalEvent{}, fmt.Errorf("event %v: %v", doc.Hash, r.err)
+	}
+
+	return event, nil
+}
+
+// ContentGroups encodes the event as expected by the newevent action
+func (e ExternalEvent) ContentGroups() []docgraph.ContentGroup {
+
+	details := newGroup(detailsGroup)
+
+	keys := make([]string, 0, len(e.Details))
+	for key := range e.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		details = append(details, stringItem(key, e.Details[key]))
+	}
+
+	details = append(details,
+		stringItem(eventSourceLabel, e.Source),
+		stringItem(eventCursorLabel, e.Cursor),
+	)
+
+	return []docgraph.ContentGroup{details}
+}
+
+// Settings is the contract settings document
+type Settings struct {
+	Hash              eos.Checksum256                `json:"hash,omitempty"`
+	Root              eos.Checksum256                `json:"root,omitempty"`
+	Data              map[string]*docgraph.FlexValue `json:"data"`
+	TrustedAccounts   []eos.Name                     `json:"trusted_accounts"`
+	AllowedCurrencies []eos.Symbol                   `json:"allowed_currencies"`
+	// CoinIDs holds the external id of the allowed currencies by symbol code
+	CoinIDs map[string]string `json:"coin_ids"`
+}
+
+// SettingsFromDocument decodes the settings document
+func SettingsFromDocument(doc docgraph.Document) (Settings, error) {
+
+	settings := Settings{
+		Hash:    doc.Hash,
+		Data:    make(map[string]*docgraph.FlexValue),
+		CoinIDs: make(map[string]string),
+	}
+
+	var r contentReader
+
+	if details, ok := findGroup(doc.ContentGroups, detailsGroup); ok {
+		r.group = details
+		settings.Root = r.checksum(rootNodeLabel, false)
+	}
+
+	if data, ok := findGroup(doc.ContentGroups, settingsDataGroup); ok {
+		for _, item := range data {
+			if item.Label != contentGroupLabel {
+				settings.Data[item.Label] = item.Value
+			}
+		}
+	}
+
+	if trusted, ok := findGroup(doc.ContentGroups, trustedAccountsGroup); ok {
+		for i, item := range trusted {
+			if item.Label == trustedAccountLabel {
+				account, err := flexName(item.Value)
+				if err != nil {
+					r.fail(fmt.Sprint(trustedAccountLabel, " at ", i), err)
+					continue
+				}
+				settings.TrustedAccounts = append(settings.TrustedAccounts, account)
+			}
+		}
+	}
+
+	if currencies, ok := findGroup(doc.ContentGroups, allowedCurrenciesGroup); ok {
+		r.group = currencies
+		for i, item := range currencies {
+			switch {
+			case item.Label == allowedCurrencyLabel:
+				asset, err := flexAsset(item.Value)
+				if err != nil {
+					r.fail(fmt.Sprint(allowedCurrencyLabel, " at ", i), err)
+					continue
+				}
+				settings.AllowedCurrencies = append(settings.AllowedCurrencies, asset.Symbol)
+			case strings.HasSuffix(item.Label, coinIDSuffix):
+				settings.CoinIDs[strings.TrimSuffix(item.Label, coinIDSuffix)] = item.Value.String()
+			}
+		}
+	}
+
+	if r.err != nil {
+		return Settings{}, fmt.Errorf("settings %v: %v", doc.Hash, r.err)
+	}
+
+	return settings, nil
+}
+
+// IsTrusted tells if account is a trusted account
+func (s Settings) IsTrusted(account eos.AccountName) bool {
+	for _, trusted := range s.TrustedAccounts {
+		if trusted == eos.Name(account) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllowedCurrency tells if symbol code is one of the allowed currencies
+func (s Settings) IsAllowedCurrency(code string) bool {
+	for _, symbol := range s.AllowedCurrencies {
+		if symbol.Symbol == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentGroups encodes the settings document
+func (s Settings) ContentGroups() []docgraph.ContentGroup {
+
+	details := newGroup(detailsGroup)
+
+	if len(s.Root) > 0 {
+		details = append(details, checksumItem(rootNodeLabel, s.Root))
+	}
+
+	data := newGroup(settingsDataGroup)
+
+	keys := make([]string, 0, len(s.Data))
+	for key := range s.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		data = append(data, docgraph.ContentItem{Label: key, Value: s.Data[key]})
+	}
+
+	groups := []docgraph.ContentGroup{details, data}
+
+	if len(s.TrustedAccounts) > 0 {
+		trusted := newGroup(trustedAccountsGroup)
+		for _, account := range s.TrustedAccounts {
+			trusted = append(trusted, nameItem(trustedAccountLabel, account))
+		}
+		groups = append(groups, trusted)
+	}
+
+	if len(s.AllowedCurrencies) > 0 || len(s.CoinIDs) > 0 {
+		currencies := newGroup(allowedCurrenciesGroup)
+		for _, symbol := range s.AllowedCurrencies {
+			currencies = append(currencies, assetItem(allowedCurrencyLabel, eos.Asset{Amount: 0, Symbol: symbol}))
+		}
+		for _, code := range sortedStringKeys(s.CoinIDs) {
+			currencies = append(currencies, stringItem(code+coinIDSuffix, s.CoinIDs[code]))
+		}
+		groups = append(groups, currencies)
+	}
+
+	return append(groups, newGroup(systemGroup,
+		stringItem(nodeLabel, settingsEdge),
+		stringItem(typeLabel, settingsEdge),
+	))
+}
+
+func sortedAssetKeys(m map[string]eos.Asset) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,169 @@
+package accounting_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+const model_account_fixed = `
+{
+	"content_groups": [
+		[
+			{ "label": "content_group_label", "value": [ "string", "details" ] },
+			{ "label": "account_tag_type", "value": [ "string", "DEBIT" ] },
+			{ "label": "account_code", "value": [ "string", "000111" ] }
+		],
+		[
+			{ "label": "content_group_label", "value": [ "string", "system" ] },
+			{ "label": "node_label", "value": [ "string", "Marketing" ] },
+			{ "label": "type", "value": [ "string", "account" ] }
+		]
+	]
+}`
+
+const model_account_variable = `
+{
+	"content_groups": [
+		[
+			{ "label": "content_group_label", "value": [ "string", "details" ] },
+			{ "label": "account_name", "value": [ "string", "Marketing" ] },
+			{ "label": "is_leaf", "value": [ "string", "true" ] }
+		]
+	]
+}`
+
+const model_balances = `
+{
+	"content_groups": [
+		[
+			{ "label": "content_group_label", "value": [ "string", "balances" ] },
+			{ "label": "account_USD", "value": [ "asset", "1000.00 USD" ] },
+			{ "label": "global_USD", "value": [ "asset", "1000.00 USD" ] },
+			{ "label": "global_HUSD", "value": [ "asset", "-500.00 HUSD" ] },
+			{ "label": "number_of_updates", "value": [ "int64", 2 ] }
+		],
+		[
+			{ "label": "content_group_label", "value": [ "string", "system" ] },
+			{ "label": "node_label", "value": [ "string", "balances" ] },
+			{ "label": "type", "value": [ "string", "balances" ] },
+			{ "label": "balance_id", "value": [ "int64", 7 ] },
+			{ "label": "number_of_updates", "value": [ "int64", 0 ] }
+		]
+	]
+}`
+
+const model_settings = `
+{
+	"content_groups": [
+		[
+			{ "label": "content_group_label", "value": [ "string", "details" ] }
+		],
+		[
+			{ "label": "content_group_label", "value": [ "string", "settings_data" ] },
+			{ "label": "next_trx_id", "value": [ "int64", 3 ] }
+		],
+		[
+			{ "label": "content_group_label", "value": [ "string", "trusted_accounts" ] },
+			{ "label": "trusted_account", "value": [ "name", "accounting" ] },
+			{ "label": "trusted_account", "value": [ "name", "authacct1111" ] }
+		],
+		[
+			{ "label": "content_group_label", "value": [ "string", "allowed_currencies" ] },
+			{ "label": "allowed_currency", "value": [ "asset", "0.00 USD" ] },
+			{ "label": "allowed_currency", "value": [ "asset", "0.00000000 BTC" ] },
+			{ "label": "BTC_ID", "value": [ "string", "bitcoin" ] }
+		]
+	]
+}`
+
+func strToDocument(t *testing.T, data string) docgraph.Document {
+	cgs, err := StrToContentGroups(data)
+	assert.NilError(t, err)
+	return docgraph.Document{ContentGroups: cgs}
+}
+
+func TestAccountFromDocuments(t *testing.T) {
+
+	account, err := accounting.AccountFromDocuments(
+		strToDocument(t, model_account_fixed),
+		strToDocument(t, model_account_variable),
+	)
+	assert.NilError(t, err)
+
+	assert.Equal(t, account.Name, "Marketing")
+	assert.Equal(t, account.Code, "000111")
+	assert.Equal(t, account.TagType, accounting.Debit)
+	assert.Equal(t, account.Type, accounting.AccountTypeUnknown)
+	assert.Assert(t, account.IsLeaf)
+
+	_, err = accounting.AccountFromDocuments(strToDocument(t, model_account_variable), strToDocument(t, model_account_variable))
+	assert.ErrorContains(t, err, "account_code")
+}
+
+func TestBalancesFromDocument(t *testing.T) {
+
+	balances, err := accounting.BalancesFromDocument(strToDocument(t, model_balances))
+	assert.NilError(t, err)
+
+	assert.Equal(t, balances.Account["USD"].String(), "1000.00 USD")
+	assert.Equal(t, balances.Global["USD"].String(), "1000.00 USD")
+	assert.Equal(t, balances.Global["HUSD"].String(), "-500.00 HUSD")
+	assert.Equal(t, len(balances.Account), 1)
+	assert.Equal(t, balances.BalanceID, int64(7))
+	assert.Equal(t, balances.NumberOfUpdates, int64(2))
+}
+
+func TestSettingsFromDocument(t *testing.T) {
+
+	settings, err := accounting.SettingsFromDocument(strToDocument(t, model_settings))
+	assert.NilError(t, err)
+
+	assert.Assert(t, settings.IsTrusted(eos.AN("authacct1111")))
+	assert.Assert(t, !settings.IsTrusted(eos.AN("someone")))
+	assert.Assert(t, settings.IsAllowedCurrency("BTC"))
+	assert.Assert(t, !settings.IsAllowedCurrency("HUSD"))
+	assert.Equal(t, settings.CoinIDs["BTC"], "bitcoin")
+	assert.Equal(t, settings.Data["next_trx_id"].String(), "3")
+}
+
+func TestTransactionContentGroups(t *testing.T) {
+
+	ledger := eos.Checksum256(make([]byte, 32))
+	account := eos.Checksum256(make([]byte, 32))
+	account[0] = 1
+	usd, _ := eos.StringToSymbol("2,USD")
+
+	trx := accounting.Transaction{
+		Ledger: ledger,
+		Date:   accounting.TimePointOf(time.Date(2021, 8, 11, 0, 0, 0, 0, time.UTC)),
+		Memo:   "memo",
+		Name:   "name",
+		Notes:  "notes",
+		Components: []accounting.Component{
+			{Account: account, Amount: eos.Asset{Amount: 100, Symbol: usd}, Memo: "debit", To: "to", Type: accounting.Debit},
+			{Account: account, Amount: eos.Asset{Amount: 100, Symbol: usd}, Memo: "credit", From: "from", Type: accounting.Credit},
+		},
+	}
+
+	groups := trx.ContentGroups()
+	assert.Equal(t, len(groups), 3)
+
+	decoded, err := accounting.TransactionFromContentGroups(groups)
+	assert.NilError(t, err)
+
+	assert.Equal(t, decoded.Ledger.String(), ledger.String())
+	assert.Equal(t, decoded.Date, trx.Date)
+	assert.Equal(t, decoded.Memo, "memo")
+	assert.Equal(t, decoded.Name, "name")
+	assert.Equal(t, decoded.Notes, "notes")
+	assert.Equal(t, len(decoded.Components), 2)
+	assert.Equal(t, decoded.Components[0].Account.String(), account.String())
+	assert.Equal(t, decoded.Components[0].Type, accounting.Debit)
+	assert.Equal(t, decoded.Components[1].From, "from")
+	assert.Assert(t, !decoded.Approved())
+}
@@ -1,2 +0,0 @@
-#!/bin/sh
-nodeos -e -p eosio --plugin eosio::producer_plugin --abi-serializer-max-time-ms 30000 --max-transaction-time 300 --plugin eosio::producer_api_plugin --plugin eosio::chain_api_plugin --plugin eosio::http_plugin --plugin eosio::history_plugin --plugin eosio::history_api_plugin --filter-on='*' --access-control-allow-origin='*' --contracts-console --http-validate-host=false --verbose-http-errors --delete-all-blocks
\ No newline at end of file
@@ -0,0 +1,389 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	eostest "github.com/digital-scarcity/eos-go-test"
+	eos "github.com/eoscanada/eos-go"
+)
+
+// Environment variables that configure the nodeos harness
+const (
+	nodeosBinaryEnv = "ACCOUNTING_NODEOS"
+	contractDirEnv  = "ACCOUNTING_CONTRACT_DIR"
+)
+
+// defaultContractDir is where cmake leaves the contract when built from the
+// root of this repository
+const defaultContractDir = "../build/accounting"
+
+const (
+	nodeosReadyTimeout = 30 * time.Second
+	nodeosStopTimeout  = 10 * time.Second
+)
+
+// NodeosOptions configures a managed nodeos. Empty fields are taken from the
+// environment and then from the defaults.
+type NodeosOptions struct {
+	// Binary is the nodeos executable, ACCOUNTING_NODEOS or nodeos on PATH
+	Binary string
+	// ContractDir holds accounting.wasm and accounting.abi,
+	// ACCOUNTING_CONTRACT_DIR or ../build/accounting
+	ContractDir  string
+	ReadyTimeout time.Duration
+	// Args are appended to the producer and api plugins arguments
+	Args []string
+}
+
+// Nodeos is a single producer chain running in its own data directory and on
+// its own ports, so several of them can run side by side.
+type Nodeos struct {
+	Endpoint    string
+	DataDir     string
+	ContractDir string
+
+	cmd  *exec.Cmd
+	done chan error
+	logs *testLogWriter
+	once sync.Once
+	err  error
+}
+
+// StartNodeos starts nodeos and waits until it answers get_info and produces
+// blocks. The node is stopped and its data removed when the test finishes.
+// The test is skipped when there is no nodeos binary.
+func StartNodeos(t *testing.T, opts NodeosOptions) *Nodeos {
+
+	t.Helper()
+
+	binary, err := nodeosBinary(opts.Binary)
+
+	if err != nil {
+		t.Skipf("nodeos is not available: %v", err)
+	}
+
+	contractDir := opts.ContractDir
+
+	if contractDir == "" {
+		contractDir = os.Getenv(contractDirEnv)
+	}
+
+	if contractDir == "" {
+		contractDir = defaultContractDir
+	}
+
+	contractDir, err = filepath.Abs(contractDir)
+
+	if err != nil {
+		t.Fatalf("unable to resolve contract directory: %v", err)
+	}
+
+	dataDir, err := ioutil.TempDir("", "nodeos")
+
+	if err != nil {
+		t.Fatalf("unable to create nodeos data directory: %v", err)
+	}
+
+	ports, err := freePorts(2)
+
+	if err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatalf("unable to find free ports for nodeos: %v", err)
+	}
+
+	httpAddress := fmt.Sprintf("127.0.0.1:%d", ports[0])
+	args := append(nodeosArgs(dataDir, httpAddress, fmt.Sprintf("127.0.0.1:%d", ports[1])), opts.Args...)
+
+	node := &Nodeos{
+		Endpoint:    "http://" + httpAddress,
+		DataDir:     dataDir,
+		ContractDir: contractDir,
+		cmd:         exec.Command(binary, args...),
+		done:        make(chan error, 1),
+		logs:        &testLogWriter{t: t, prefix: "nodeos: "},
+	}
+
+	node.cmd.Stdout = node.logs
+	node.cmd.Stderr = node.logs
+
+	t.Logf("Starting %v on %v with data in %v", binary, node.Endpoint, dataDir)
+
+	if err := node.cmd.Start(); err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatalf("unable to start nodeos: %v", err)
+	}
+
+	go func() {
+		node.done <- node.cmd.Wait()
+	}()
+
+	t.Cleanup(func() {
+		if err := node.Stop(); err != nil {
+			t.Errorf("unable to stop nodeos: %v", err)
+		}
+	})
+
+	timeout := opts.ReadyTimeout
+
+	if timeout == 0 {
+		timeout = nodeosReadyTimeout
+	}
+
+	if err := node.waitReady(timeout); err != nil {
+		t.Fatalf("nodeos did not become ready: %v", err)
+	}
+
+	return node
+}
+
+// API returns a client for the node signing with the default test key
+func (n *Nodeos) API(ctx context.Context) (*eos.API, error) {
+
+	api := eos.New(n.Endpoint)
+	keyBag := &eos.KeyBag{}
+
+	if err := keyBag.ImportPrivateKey(ctx, eostest.DefaultKey()); err != nil {
+		return nil, fmt.Errorf("unable to import default key: %v", err)
+	}
+
+	api.SetSigner(keyBag)
+
+	return api, nil
+}
+
+// DeployContract sets the accounting contract from ContractDir on account
+func (n *Nodeos) DeployContract(ctx context.Context, api *eos.API, account eos.AccountName) error {
+
+	wasm := filepath.Join(n.ContractDir, "accounting.wasm")
+	abi := filepath.Join(n.ContractDir, "accounting.abi")
+
+	for _, file := range []string{wasm, abi} {
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("contract not built, set %v to its directory: %v", contractDirEnv, err)
+		}
+	}
+
+	_, err := eostest.SetContract(ctx, api, account, wasm, abi)
+
+	if err != nil {
+		return fmt.Errorf("unable to deploy %v to %v: %v", wasm, account, err)
+	}
+
+	return nil
+}
+
+// Stop interrupts nodeos, kills it if it does not exit in time and removes
+// its data directory. It is safe to call more than once.
+func (n *Nodeos) Stop() error {
+
+	n.once.Do(func() {
+
+		defer os.RemoveAll(n.DataDir)
+
+		if err := n.cmd.Process.Signal(syscall.SIGINT); err != nil {
+			// it has already exited, collect it
+			<-n.done
+			return
+		}
+
+		select {
+		case <-n.done:
+		case <-time.After(nodeosStopTimeout):
+			n.err = fmt.Errorf("nodeos did not exit within %v, killed", nodeosStopTimeout)
+			n.cmd.Process.Kill()
+			<-n.done
+		}
+	})
+
+	n.logs.Flush()
+
+	return n.err
+}
+
+func (n *Nodeos) waitReady(timeout time.Duration) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	api := eos.New(n.Endpoint)
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	var lastErr error
+
+	for {
+
+		info, err := api.GetInfo(ctx)
+
+		if err == nil && info.HeadBlockNum > 1 {
+			return nil
+		}
+
+		if err != nil {
+			lastErr = err
+		}
+
+		select {
+		case err := <-n.done:
+			n.done <- err
+			return fmt.Errorf("nodeos exited: %v", err)
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("%v, last error: %v", ctx.Err(), lastErr)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForBlock waits until the node produces a block after its current head
+// block, the transactions pushed before the call are then in a block
+func waitForBlock(t *testing.T, env *Environment) {
+
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(env.ctx, nodeosReadyTimeout)
+	defer cancel()
+
+	start, err := env.api.GetInfo(ctx)
+
+	if err != nil {
+		t.Fatalf("unable to get the head block: %v", err)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			t.Fatalf("no block after %v: %v", start.HeadBlockNum, ctx.Err())
+		case <-ticker.C:
+		}
+
+		info, err := env.api.GetInfo(ctx)
+
+		if err == nil && info.HeadBlockNum > start.HeadBlockNum {
+			return
+		}
+	}
+}
+
+// nodeosArgs run a single producer with the chain, producer and history
+// apis, using the data, config and network addresses of this node
+func nodeosArgs(dataDir, httpAddress, p2pAddress string) []string {
+	return []string{
+		"-e", "-p", "eosio",
+		"--data-dir", filepath.Join(dataDir, "data"),
+		"--config-dir", filepath.Join(dataDir, "config"),
+		"--http-server-address", httpAddress,
+		"--p2p-listen-endpoint", p2pAddress,
+		"--plugin", "eosio::producer_plugin",
+		"--plugin", "eosio::producer_api_plugin",
+		"--plugin", "eosio::chain_api_plugin",
+		"--plugin", "eosio::http_plugin",
+		"--plugin", "eosio::history_plugin",
+		"--plugin", "eosio::history_api_plugin",
+		"--abi-serializer-max-time-ms", "30000",
+		"--max-transaction-time", "300",
+		"--filter-on", "*",
+		"--access-control-allow-origin", "*",
+		"--contracts-console",
+		"--http-validate-host", "false",
+		"--verbose-http-errors",
+	}
+}
+
+func nodeosBinary(binary string) (string, error) {
+
+	if binary == "" {
+		binary = os.Getenv(nodeosBinaryEnv)
+	}
+
+	if binary == "" {
+		binary = "nodeos"
+	}
+
+	return exec.LookPath(binary)
+}
+
+// freePorts asks the kernel for n unused ports. They are released before
+// nodeos binds them, which is good enough on a test machine.
+func freePorts(n int) ([]int, error) {
+
+	ports := make([]int, 0, n)
+
+	for len(ports) < n {
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer listener.Close()
+
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+	}
+
+	return ports, nil
+}
+
+// testLogWriter sends whole lines to the test log, so the output of nodeos is
+// shown with the test that started it
+type testLogWriter struct {
+	t      *testing.T
+	prefix string
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *testLogWriter) Write(p []byte) (int, error) {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+
+	for {
+
+		line, err := w.buf.ReadString('\n')
+
+		if err != nil {
+			// keep the partial line for the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+
+		w.t.Log(w.prefix + strings.TrimRight(line, "\r\n"))
+	}
+}
+
+// Flush logs what is left of an unterminated last line
+func (w *testLogWriter) Flush() {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.t.Log(w.prefix + w.buf.String())
+		w.buf.Reset()
+	}
+}
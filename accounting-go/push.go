@@ -0,0 +1,247 @@
+package accounting
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+)
+
+// RefBlock selects the block used as TAPOS reference
+type RefBlock int
+
+const (
+	// RefHeadBlock references the head block, transactions are accepted
+	// faster but may be dropped on a fork
+	RefHeadBlock RefBlock = iota
+	// RefLastIrreversibleBlock references the last irreversible block
+	RefLastIrreversibleBlock
+)
+
+// PushOptions configures how the client pushes transactions
+type PushOptions struct {
+	// Expiration of the transactions, counted from the moment they are signed
+	Expiration time.Duration
+	RefBlock   RefBlock
+	// MaxRetries is the number of times a transaction is pushed again after
+	// a transient error, expired transactions are signed again
+	MaxRetries int
+	// Backoff is the wait before the first retry, it doubles on every retry
+	// up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxCPUUsageMS and MaxNetUsageWords limit the resources of the
+	// transactions, 0 lets the chain apply its own limits
+	MaxCPUUsageMS    uint8
+	MaxNetUsageWords uint32
+}
+
+// DefaultPushOptions are used by the clients created without WithPushOptions
+func DefaultPushOptions() PushOptions {
+	return PushOptions{
+		Expiration: 30 * time.Second,
+		RefBlock:   RefHeadBlock,
+		MaxRetries: 3,
+		Backoff:    500 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	}
+}
+
+// WithPushOptions sets how the client pushes transactions
+func WithPushOptions(opts PushOptions) ClientOption {
+	return func(c *Client) {
+		c.pushOpts = opts
+	}
+}
+
+// ActionResult is the trace of one of the actions of a transaction
+type ActionResult struct {
+	Receiver eos.AccountName `json:"receiver"`
+	Action   eos.ActionName  `json:"action"`
+	Console  string          `json:"console,omitempty"`
+}
+
+// TxResult describes a pushed transaction
+type TxResult struct {
+	TrxID    string         `json:"trx_id"`
+	BlockNum uint32         `json:"block_num"`
+	Traces   []ActionResult `json:"traces,omitempty"`
+	// Attempts is the number of times the transaction was pushed
+	Attempts int `json:"attempts"`
+}
+
+// Console returns the console output of all the actions
+func (r *TxResult) Console() string {
+	var console strings.Builder
+	for _, trace := range r.Traces {
+		console.WriteString(trace.Console)
+	}
+	return console.String()
+}
+
+// isExpired, isDuplicate and isNetworkError detect the transient errors,
+// expired transactions are signed again while on network errors the same
+// transaction is pushed again
+func isExpired(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "expired_tx_exception") ||
+		strings.Contains(message, "Expired Transaction")
+}
+
+func isDuplicate(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "tx_duplicate") ||
+		strings.Contains(message, "Duplicate transaction")
+}
+
+func isNetworkError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	message := err.Error()
+	for _, fragment := range []string{
+		"connection refused",
+		"connection reset",
+		"broken pipe",
+		"i/o timeout",
+		"no such host",
+		"503 Service Unavailable",
+		"502 Bad Gateway",
+	} {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// push signs and pushes actions in a single transaction, retrying on
+// transient errors as configured in the push options of the client
+func (c *Client) push(ctx context.Context, actions []*eos.Action, opts PushOptions) (*TxResult, error) {
+
+	var packedTx *eos.PackedTransaction
+	var trxID string
+	backoff := opts.Backoff
+
+	for attempt := 1; ; attempt++ {
+
+		if packedTx == nil {
+			var err error
+			packedTx, trxID, err = c.sign(ctx, actions, opts)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.api.PushTransaction(ctx, packedTx)
+
+		if err == nil {
+			return newTxResult(resp, attempt), nil
+		}
+
+		switch {
+		case attempt > 1 && isDuplicate(err):
+			// a previous attempt reached the chain
+			return &TxResult{TrxID: trxID, Attempts: attempt}, nil
+		case isExpired(err):
+			packedTx = nil
+		case isNetworkError(err):
+		default:
+			return nil, ClassifyError(err)
+		}
+
+		if attempt > opts.MaxRetries {
+			return nil, fmt.Errorf("push transaction: giving up after %v attempts: %w", attempt, ClassifyError(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("push transaction: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
+
+// sign builds the transaction referencing the block selected in opts
+func (c *Client) sign(ctx context.Context, actions []*eos.Action, opts PushOptions) (*eos.PackedTransaction, string, error) {
+
+	info, err := c.api.GetInfo(ctx)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("get chain info: %v", err)
+	}
+
+	txOpts := &eos.TxOptions{
+		ChainID:          info.ChainID,
+		HeadBlockID:      info.HeadBlockID,
+		MaxCPUUsageMS:    opts.MaxCPUUsageMS,
+		MaxNetUsageWords: opts.MaxNetUsageWords,
+	}
+
+	if opts.RefBlock == RefLastIrreversibleBlock {
+		txOpts.HeadBlockID = info.LastIrreversibleBlockID
+	}
+
+	tx := eos.NewTransaction(actions, txOpts)
+
+	if opts.Expiration > 0 {
+		tx.Expiration = eos.JSONTime{Time: time.Now().UTC().Add(opts.Expiration)}
+	}
+
+	signedTx, packedTx, err := c.api.SignTransaction(ctx, tx, txOpts.ChainID, eos.CompressionNone)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("sign transaction: %v", err)
+	}
+
+	id, err := signedTx.ID(txOpts.ChainID)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("transaction id: %v", err)
+	}
+
+	return packedTx, hex.EncodeToString(id), nil
+}
+
+func newTxResult(resp *eos.PushTransactionFullResp, attempts int) *TxResult {
+
+	result := &TxResult{
+		TrxID:    resp.TransactionID,
+		BlockNum: resp.BlockNum,
+		Attempts: attempts,
+	}
+
+	if result.TrxID == "" {
+		result.TrxID = hex.EncodeToString(resp.Processed.ID)
+	}
+
+	for _, trace := range resp.Processed.ActionTraces {
+
+		actionResult := ActionResult{
+			Receiver: trace.Receiver,
+			Console:  string(trace.Console),
+		}
+
+		if trace.Action != nil {
+			actionResult.Action = trace.Action.Name
+		}
+
+		if result.BlockNum == 0 {
+			result.BlockNum = trace.BlockNum
+		}
+
+		result.Traces = append(result.Traces, actionResult)
+	}
+
+	return result
+}
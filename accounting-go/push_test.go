@@ -0,0 +1,122 @@
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+var (
+	pushBadGateway = pushReply{http.StatusBadGateway, "502 Bad Gateway"}
+	pushExpired    = pushAPIError(3040005, "expired_tx_exception", "Expired Transaction", "expired transaction")
+	pushDuplicate  = pushAPIError(3040008, "tx_duplicate", "Duplicate transaction", "duplicate transaction")
+)
+
+// pushClient returns a client of s retrying twice with a short backoff
+func pushClient(t *testing.T, s *pushServer, backoff time.Duration) *accounting.Client {
+
+	client, err := accounting.NewClient(serverAPI(t, s), eos.AN("accounting"), accounting.WithPushOptions(accounting.PushOptions{
+		Expiration: time.Minute,
+		MaxRetries: 2,
+		Backoff:    backoff,
+		MaxBackoff: backoff,
+	}))
+	assert.NilError(t, err)
+
+	return client
+}
+
+func TestPush(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("retries transient errors", func(t *testing.T) {
+
+		server := newPushServer(t, pushBadGateway, pushExpired, pushOK)
+		defer server.Close()
+
+		result, err := pushClient(t, server, time.Millisecond).CreateRoot(ctx, "notes")
+		assert.NilError(t, err)
+		assert.Equal(t, result.Attempts, 3)
+		assert.Equal(t, result.TrxID, strings.Repeat("ab", 32))
+		assert.Equal(t, result.BlockNum, uint32(12))
+		assert.Equal(t, result.Console(), "root created")
+
+		// the expired transaction is signed again
+		infos, pushes := server.counts()
+		assert.Equal(t, infos, 2)
+		assert.Equal(t, pushes, 3)
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+
+		server := newPushServer(t, pushBadGateway)
+		defer server.Close()
+
+		_, err := pushClient(t, server, time.Millisecond).CreateRoot(ctx, "notes")
+		assert.ErrorContains(t, err, "giving up after 3 attempts")
+
+		_, pushes := server.counts()
+		assert.Equal(t, pushes, 3)
+	})
+
+	t.Run("does not retry contract errors", func(t *testing.T) {
+
+		server := newPushServer(t, pushNotTrusted, pushOK)
+		defer server.Close()
+
+		_, err := pushClient(t, server, time.Millisecond).CreateRoot(ctx, "notes")
+		assert.Assert(t, errors.Is(err, accounting.ErrNotTrusted), err)
+
+		_, pushes := server.counts()
+		assert.Equal(t, pushes, 1)
+	})
+
+	t.Run("accepts the duplicate of a retried transaction", func(t *testing.T) {
+
+		server := newPushServer(t, pushBadGateway, pushDuplicate)
+		defer server.Close()
+
+		result, err := pushClient(t, server, time.Millisecond).CreateRoot(ctx, "notes")
+		assert.NilError(t, err)
+		assert.Equal(t, result.Attempts, 2)
+		assert.Assert(t, result.TrxID != "")
+
+		// a duplicate on the first attempt was not pushed by this client
+		server = newPushServer(t, pushDuplicate)
+		defer server.Close()
+
+		_, err = pushClient(t, server, time.Millisecond).CreateRoot(ctx, "notes")
+		assert.ErrorContains(t, err, "Duplicate transaction")
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+
+		server := newPushServer(t, pushBadGateway)
+		defer server.Close()
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// cancel once the reply is sent and the client is waiting to retry
+		server.onPush = func(n int) {
+			time.AfterFunc(50*time.Millisecond, cancel)
+		}
+
+		start := time.Now()
+
+		_, err := pushClient(t, server, time.Hour).CreateRoot(cancelCtx, "notes")
+		assert.Assert(t, errors.Is(err, context.Canceled), err)
+		assert.Assert(t, time.Since(start) < time.Minute)
+
+		_, pushes := server.counts()
+		assert.Equal(t, pushes, 1)
+	})
+}
@@ -0,0 +1,264 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// Snapshot file names in a snapshot directory, as written by
+// Client.WriteSnapshot
+const (
+	SnapshotDocumentsFile = "documents.json"
+	SnapshotEdgesFile     = "edges.json"
+	SnapshotCursorsFile   = "cursors.json"
+	SnapshotExratesFile   = "exrates.json"
+	SnapshotSettingsFile  = "settings.json"
+	SnapshotManifestFile  = "manifest.json"
+)
+
+// Snapshot is an in-memory copy of the documents and edges tables. It
+// implements Graph so the readers of this package can run on a dump of the
+// tables without a node.
+type Snapshot struct {
+	documents []docgraph.Document
+	edges     []docgraph.Edge
+
+	byHash   map[string]int
+	fromNode map[string][]int
+	toNode   map[string][]int
+	byName   map[eos.Name][]int
+}
+
+// NewSnapshot indexes documents and edges
+func NewSnapshot(documents []docgraph.Document, edges []docgraph.Edge) *Snapshot {
+
+	s := &Snapshot{
+		documents: documents,
+		edges:     edges,
+		byHash:    make(map[string]int),
+		fromNode:  make(map[string][]int),
+		toNode:    make(map[string][]int),
+		byName:    make(map[eos.Name][]int),
+	}
+
+	for i, document := range documents {
+		s.byHash[document.Hash.String()] = i
+	}
+
+	for i, edge := range edges {
+		s.fromNode[edge.FromNode.String()] = append(s.fromNode[edge.FromNode.String()], i)
+		s.toNode[edge.ToNode.String()] = append(s.toNode[edge.ToNode.String()], i)
+		s.byName[edge.EdgeName] = append(s.byName[edge.EdgeName], i)
+	}
+
+	return s
+}
+
+// readTableRows decodes the rows of a table dump into rows, it accepts the
+// output of cleos get table ({"rows": [...]}) and bare arrays
+func readTableRows(r io.Reader, rows interface{}) error {
+
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return err
+	}
+
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] == '{' {
+
+		var table struct {
+			Rows json.RawMessage `json:"rows"`
+		}
+
+		if err := json.Unmarshal(data, &table); err != nil {
+			return err
+		}
+
+		if table.Rows == nil {
+			return fmt.Errorf("missing rows")
+		}
+
+		data = table.Rows
+	}
+
+	return json.Unmarshal(data, rows)
+}
+
+// ReadSnapshot reads the dumps of the documents and edges tables
+func ReadSnapshot(documents, edges io.Reader) (*Snapshot, error) {
+
+	var documentRows []docgraph.Document
+
+	if err := readTableRows(documents, &documentRows); err != nil {
+		return nil, fmt.Errorf("read snapshot documents: %v", err)
+	}
+
+	var edgeRows []docgraph.Edge
+
+	if err := readTableRows(edges, &edgeRows); err != nil {
+		return nil, fmt.Errorf("read snapshot edges: %v", err)
+	}
+
+	return NewSnapshot(documentRows, edgeRows), nil
+}
+
+// LoadSnapshot reads the dumps of the documents and edges tables from files
+func LoadSnapshot(documentsPath, edgesPath string) (*Snapshot, error) {
+
+	documents, err := os.Open(documentsPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot: %v", err)
+	}
+
+	defer documents.Close()
+
+	edges, err := os.Open(edgesPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot: %v", err)
+	}
+
+	defer edges.Close()
+
+	return ReadSnapshot(documents, edges)
+}
+
+// LoadSnapshotDir reads documents.json and edges.json from dir
+func LoadSnapshotDir(dir string) (*Snapshot, error) {
+	return LoadSnapshot(filepath.Join(dir, SnapshotDocumentsFile), filepath.Join(dir, SnapshotEdgesFile))
+}
+
+// Documents returns the documents of the snapshot sorted by id
+func (s *Snapshot) Documents() []docgraph.Document {
+
+	documents := append([]docgraph.Document(nil), s.documents...)
+
+	sort.SliceStable(documents, func(i, j int) bool {
+		return documents[i].ID < documents[j].ID
+	})
+
+	return documents
+}
+
+// Edges returns the edges of the snapshot sorted by id
+func (s *Snapshot) Edges() []docgraph.Edge {
+
+	edges := append([]docgraph.Edge(nil), s.edges...)
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].ID < edges[j].ID
+	})
+
+	return edges
+}
+
+func (s *Snapshot) selectEdges(indexes []int, keep func(edge docgraph.Edge) bool) []docgraph.Edge {
+
+	edges := []docgraph.Edge{}
+
+	for _, i := range indexes {
+		if keep(s.edges[i]) {
+			edges = append(edges, s.edges[i])
+		}
+	}
+
+	return edges
+}
+
+// Document returns the document hash
+func (s *Snapshot) Document(ctx context.Context, hash eos.Checksum256) (docgraph.Document, error) {
+
+	i, ok := s.byHash[hash.String()]
+
+	if !ok {
+		return docgraph.Document{}, fmt.Errorf("document not found %v", hash)
+	}
+
+	return s.documents[i], nil
+}
+
+// EdgesFrom returns the edges named edgeName leaving from the document hash
+func (s *Snapshot) EdgesFrom(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	return s.selectEdges(s.fromNode[hash.String()], func(edge docgraph.Edge) bool {
+		return edge.EdgeName == eos.Name(edgeName)
+	}), nil
+}
+
+// EdgesTo returns the edges named edgeName arriving to the document hash
+func (s *Snapshot) EdgesTo(ctx context.Context, hash eos.Checksum256, edgeName string) ([]docgraph.Edge, error) {
+	return s.selectEdges(s.toNode[hash.String()], func(edge docgraph.Edge) bool {
+		return edge.EdgeName == eos.Name(edgeName)
+	}), nil
+}
+
+// AllEdgesFrom returns every edge leaving from the document hash
+func (s *Snapshot) AllEdgesFrom(hash eos.Checksum256) []docgraph.Edge {
+	return s.selectEdges(s.fromNode[hash.String()], func(docgraph.Edge) bool { return true })
+}
+
+// AllEdgesTo returns every edge arriving to the document hash
+func (s *Snapshot) AllEdgesTo(hash eos.Checksum256) []docgraph.Edge {
+	return s.selectEdges(s.toNode[hash.String()], func(docgraph.Edge) bool { return true })
+}
+
+// EdgesNamed returns every edge named edgeName
+func (s *Snapshot) EdgesNamed(edgeName string) []docgraph.Edge {
+	return s.selectEdges(s.byName[eos.Name(edgeName)], func(docgraph.Edge) bool { return true })
+}
+
+// DocumentsWithEdge returns the documents pointed by the edges named
+// edgeName leaving from document, like docgraph.GetDocumentsWithEdge
+func (s *Snapshot) DocumentsWithEdge(ctx context.Context, document docgraph.Document, edgeName string) ([]docgraph.Document, error) {
+
+	edges, _ := s.EdgesFrom(ctx, document.Hash, edgeName)
+	documents := make([]docgraph.Document, 0, len(edges))
+
+	for _, edge := range edges {
+
+		to, err := s.Document(ctx, edge.ToNode)
+
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, to)
+	}
+
+	return documents, nil
+}
+
+// GetAllEdgesForDocument returns the edges from and to document, keyed by
+// "from" and "to" as Client.GetAllEdgesForDocument
+func (s *Snapshot) GetAllEdgesForDocument(ctx context.Context, document docgraph.Document) (map[string][]docgraph.Edge, error) {
+	return map[string][]docgraph.Edge{
+		"from": s.AllEdgesFrom(document.Hash),
+		"to":   s.AllEdgesTo(document.Hash),
+	}, nil
+}
+
+// Ledgers returns the ledgers linked to the root document by ledger edges
+func (s *Snapshot) Ledgers() []docgraph.Document {
+
+	var ledgers []docgraph.Document
+
+	for _, edge := range s.EdgesNamed(ledgerEdge) {
+		if i, ok := s.byHash[edge.ToNode.String()]; ok {
+			ledgers = append(ledgers, s.documents[i])
+		}
+	}
+
+	return ledgers
+}
@@ -0,0 +1,364 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// BalanceChange is the change of the balances of an account between two
+// snapshots, Before or After is nil when the account has no balances in
+// that snapshot
+type BalanceChange struct {
+	Ledger  eos.Checksum256 `json:"ledger"`
+	Account Account         `json:"account"`
+	Before  *Balances       `json:"before"`
+	After   *Balances       `json:"after"`
+	// AccountDelta and GlobalDelta hold After - Before for the currencies
+	// that changed
+	AccountDelta map[string]eos.Asset `json:"account_delta,omitempty"`
+	GlobalDelta  map[string]eos.Asset `json:"global_delta,omitempty"`
+}
+
+// SnapshotDiff reports the changes between two snapshots of the contract
+// tables. Documents are compared by hash and edges by from node, to node and
+// name, so an updated document shows as removed and added.
+type SnapshotDiff struct {
+	DocumentsAdded   []docgraph.Document `json:"documents_added"`
+	DocumentsRemoved []docgraph.Document `json:"documents_removed"`
+	EdgesAdded       []docgraph.Edge     `json:"edges_added"`
+	EdgesRemoved     []docgraph.Edge     `json:"edges_removed"`
+	// Balances lists the accounts whose balances changed, sorted by ledger
+	// and code
+	Balances []BalanceChange `json:"balances"`
+	// Approved lists the transactions approved in the second snapshot that
+	// were missing or unapproved in the first one, sorted by date
+	Approved []Transaction `json:"approved"`
+}
+
+// DiffSnapshots compares the snapshot before with the snapshot after
+func DiffSnapshots(ctx context.Context, before, after *Snapshot) (*SnapshotDiff, error) {
+
+	diff := &SnapshotDiff{
+		DocumentsAdded:   []docgraph.Document{},
+		DocumentsRemoved: []docgraph.Document{},
+		EdgesAdded:       []docgraph.Edge{},
+		EdgesRemoved:     []docgraph.Edge{},
+		Balances:         []BalanceChange{},
+		Approved:         []Transaction{},
+	}
+
+	for _, document := range after.Documents() {
+		if _, ok := before.byHash[document.Hash.String()]; !ok {
+			diff.DocumentsAdded = append(diff.DocumentsAdded, document)
+		}
+	}
+
+	for _, document := range before.Documents() {
+		if _, ok := after.byHash[document.Hash.String()]; !ok {
+			diff.DocumentsRemoved = append(diff.DocumentsRemoved, document)
+		}
+	}
+
+	beforeEdges, afterEdges := edgeKeys(before), edgeKeys(after)
+
+	for _, edge := range after.Edges() {
+		if !beforeEdges[edgeKey(edge)] {
+			diff.EdgesAdded = append(diff.EdgesAdded, edge)
+		}
+	}
+
+	for _, edge := range before.Edges() {
+		if !afterEdges[edgeKey(edge)] {
+			diff.EdgesRemoved = append(diff.EdgesRemoved, edge)
+		}
+	}
+
+	if err := diff.diffBalances(ctx, before, after); err != nil {
+		return nil, fmt.Errorf("diff snapshots: %v", err)
+	}
+
+	if err := diff.diffApproved(ctx, before, after); err != nil {
+		return nil, fmt.Errorf("diff snapshots: %v", err)
+	}
+
+	return diff, nil
+}
+
+// DiffSnapshot compares before with the tables of the contract on the node
+func (c *Client) DiffSnapshot(ctx context.Context, before *Snapshot) (*SnapshotDiff, error) {
+
+	after, err := c.ReadSnapshot(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffSnapshots(ctx, before, after)
+}
+
+// Empty returns true when the snapshots hold the same documents and edges
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.DocumentsAdded) == 0 && len(d.DocumentsRemoved) == 0 &&
+		len(d.EdgesAdded) == 0 && len(d.EdgesRemoved) == 0
+}
+
+func edgeKey(edge docgraph.Edge) string {
+	return edge.FromNode.String() + "/" + edge.ToNode.String() + "/" + string(edge.EdgeName)
+}
+
+func edgeKeys(s *Snapshot) map[string]bool {
+
+	keys := make(map[string]bool, len(s.edges))
+
+	for _, edge := range s.edges {
+		keys[edgeKey(edge)] = true
+	}
+
+	return keys
+}
+
+// snapshotBalances returns the accounts of every ledger of s with their
+// balances in After, keyed by account hash
+func snapshotBalances(ctx context.Context, s *Snapshot) (map[string]BalanceChange, error) {
+
+	accounts := make(map[string]BalanceChange)
+
+	for _, ledger := range s.Ledgers() {
+
+		tree, err := ReadLedgerTree(ctx, s, ledger.Hash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tree.Walk(func(node *LedgerNode) bool {
+			accounts[node.Account.Hash.String()] = BalanceChange{
+				Ledger:  tree.Ledger,
+				Account: node.Account,
+				After:   node.Balances,
+			}
+			return true
+		})
+	}
+
+	return accounts, nil
+}
+
+// balancesDelta returns after - before for the currencies that changed
+func balancesDelta(before, after map[string]eos.Asset) map[string]eos.Asset {
+
+	sums := make(assetSums)
+
+	for _, amount := range after {
+		sums.add(amount)
+	}
+
+	for _, amount := range before {
+		sums.add(negateAsset(amount))
+	}
+
+	var delta map[string]eos.Asset
+
+	for currency, amount := range sums {
+		if amount.Amount != 0 {
+			if delta == nil {
+				delta = make(map[string]eos.Asset)
+			}
+			delta[currency] = amount
+		}
+	}
+
+	return delta
+}
+
+func (d *SnapshotDiff) diffBalances(ctx context.Context, before, after *Snapshot) error {
+
+	beforeAccounts, err := snapshotBalances(ctx, before)
+
+	if err != nil {
+		return err
+	}
+
+	afterAccounts, err := snapshotBalances(ctx, after)
+
+	if err != nil {
+		return err
+	}
+
+	for hash, change := range beforeAccounts {
+		if _, ok := afterAccounts[hash]; !ok {
+			afterAccounts[hash] = BalanceChange{Ledger: change.Ledger, Account: change.Account}
+		}
+	}
+
+	for hash, change := range afterAccounts {
+
+		change.Before = beforeAccounts[hash].After
+
+		var beforeAccount, beforeGlobal, afterAccount, afterGlobal map[string]eos.Asset
+
+		if change.Before != nil {
+			beforeAccount, beforeGlobal = change.Before.Account, change.Before.Global
+		}
+
+		if change.After != nil {
+			afterAccount, afterGlobal = change.After.Account, change.After.Global
+		}
+
+		change.AccountDelta = balancesDelta(beforeAccount, afterAccount)
+		change.GlobalDelta = balancesDelta(beforeGlobal, afterGlobal)
+
+		if change.AccountDelta != nil || change.GlobalDelta != nil {
+			d.Balances = append(d.Balances, change)
+		}
+	}
+
+	sort.Slice(d.Balances, func(i, j int) bool {
+		if d.Balances[i].Ledger.String() != d.Balances[j].Ledger.String() {
+			return d.Balances[i].Ledger.String() < d.Balances[j].Ledger.String()
+		}
+		return d.Balances[i].Account.Code < d.Balances[j].Account.Code
+	})
+
+	return nil
+}
+
+// approvedTransactions returns the transactions linked by approved edges
+// from the transactions buckets of the ledgers, keyed by id or by hash for
+// the transactions without id. Updating a transaction replaces its document
+// so the hash can't be used when the id is known. The approved edges also
+// go back from the transactions to their bucket, those are not followed.
+func approvedTransactions(ctx context.Context, s *Snapshot) (map[string]Transaction, error) {
+
+	transactions := make(map[string]Transaction)
+
+	for _, bucket := range s.EdgesNamed(trxBucketEdge) {
+
+		edges, err := s.EdgesFrom(ctx, bucket.ToNode, approvedEdge)
+
+		if err != nil {
+			return nil, fmt.Errorf("approved transactions of %v: %v", bucket.ToNode, err)
+		}
+
+		for _, edge := range edges {
+
+			document, err := s.Document(ctx, edge.ToNode)
+
+			if err != nil {
+				return nil, fmt.Errorf("load transaction %v: %v", edge.ToNode, err)
+			}
+
+			trx, err := TransactionFromDocument(document)
+
+			if err != nil {
+				return nil, err
+			}
+
+			key := trx.Hash.String()
+			if trx.ID != 0 {
+				key = fmt.Sprintf("%v", trx.ID)
+			}
+
+			transactions[key] = trx
+		}
+	}
+
+	return transactions, nil
+}
+
+func (d *SnapshotDiff) diffApproved(ctx context.Context, before, after *Snapshot) error {
+
+	beforeApproved, err := approvedTransactions(ctx, before)
+
+	if err != nil {
+		return err
+	}
+
+	afterApproved, err := approvedTransactions(ctx, after)
+
+	if err != nil {
+		return err
+	}
+
+	for key, trx := range afterApproved {
+		if _, ok := beforeApproved[key]; !ok {
+			d.Approved = append(d.Approved, trx)
+		}
+	}
+
+	sort.Slice(d.Approved, func(i, j int) bool {
+		if d.Approved[i].Date != d.Approved[j].Date {
+			return d.Approved[i].Date < d.Approved[j].Date
+		}
+		return d.Approved[i].ID < d.Approved[j].ID
+	})
+
+	return nil
+}
+
+// WriteText writes the diff with a line per change
+func (d *SnapshotDiff) WriteText(w io.Writer) error {
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "documents: +%v -%v\n", len(d.DocumentsAdded), len(d.DocumentsRemoved))
+
+	for _, document := range d.DocumentsAdded {
+		fmt.Fprintf(&out, "+ %v %v\n", document.ID, document.Hash)
+	}
+
+	for _, document := range d.DocumentsRemoved {
+		fmt.Fprintf(&out, "- %v %v\n", document.ID, document.Hash)
+	}
+
+	fmt.Fprintf(&out, "edges: +%v -%v\n", len(d.EdgesAdded), len(d.EdgesRemoved))
+
+	for _, edge := range d.EdgesAdded {
+		fmt.Fprintf(&out, "+ %v %v -> %v\n", edge.EdgeName, edge.FromNode, edge.ToNode)
+	}
+
+	for _, edge := range d.EdgesRemoved {
+		fmt.Fprintf(&out, "- %v %v -> %v\n", edge.EdgeName, edge.FromNode, edge.ToNode)
+	}
+
+	fmt.Fprintf(&out, "balances: %v\n", len(d.Balances))
+
+	for _, change := range d.Balances {
+		fmt.Fprintf(&out, "%v %v account: [%v] global: [%v]\n",
+			change.Account.Code, change.Account.Name,
+			sortedBalances(change.AccountDelta), sortedBalances(change.GlobalDelta))
+	}
+
+	fmt.Fprintf(&out, "approved: %v\n", len(d.Approved))
+
+	for _, trx := range d.Approved {
+		fmt.Fprintf(&out, "%v %v %v\n", trx.ID, TimeOf(trx.Date).Format(time.RFC3339), trx.Memo)
+	}
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write snapshot diff: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the diff as indented JSON
+func (d *SnapshotDiff) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(d); err != nil {
+		return fmt.Errorf("write snapshot diff: %v", err)
+	}
+
+	return nil
+}
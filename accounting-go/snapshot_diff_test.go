@@ -0,0 +1,123 @@
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+// snapshot copies the documents and edges of g
+func (g *memoryGraph) snapshot() *accounting.Snapshot {
+
+	documents := make([]docgraph.Document, 0, len(g.documents))
+	for _, document := range g.documents {
+		documents = append(documents, document)
+	}
+
+	return accounting.NewSnapshot(documents, append([]docgraph.Edge(nil), g.edges...))
+}
+
+func (g *memoryGraph) setBalances(t *testing.T, account eos.Checksum256, key, amount string) {
+
+	for i, edge := range g.edges {
+		if edge.FromNode.String() == account.String() && edge.EdgeName == "balances" {
+			delete(g.documents, edge.ToNode.String())
+			g.edges = append(g.edges[:i], g.edges[i+1:]...)
+			break
+		}
+	}
+
+	g.link(account, g.add(t, "balances/"+key, fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "balances" ] },
+		{ "label": "account_USD", "value": [ "asset", "%v" ] },
+		{ "label": "global_USD", "value": [ "asset", "%v" ] }
+	]]
+}`, amount, amount)), "balances")
+}
+
+func TestDiffSnapshots(t *testing.T) {
+
+	ctx := context.Background()
+
+	g := newMemoryGraph()
+	root := g.add(t, "root", `{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "root_node", "value": [ "name", "accounting" ] }
+	]]
+}`)
+	ledger := g.addLedger(t, "Main")
+	g.link(root, ledger, "ledger")
+	cash := g.addAccount(t, ledger, "1100", "Cash", accounting.Debit, true)
+	sales := g.addAccount(t, ledger, "4100", "Sales", accounting.Credit, true)
+	g.setBalances(t, cash, "1100/1", "100.00 USD")
+	g.setBalances(t, sales, "4100/1", "-100.00 USD")
+
+	// the transactions hang from the bucket of the ledger, linked both ways
+	bucket := g.add(t, "bucket", fmt.Sprintf(`{
+	"content_groups": [[
+		{ "label": "content_group_label", "value": [ "string", "details" ] },
+		{ "label": "trx_ledger", "value": [ "checksum256", "%v" ] }
+	]]
+}`, ledger))
+	g.link(ledger, bucket, "trxbucket")
+
+	unapproved := g.addTransaction(t, ledger, 6, "2021-03-01T00:00:00.000", "March sales")
+	g.addComponent(t, unapproved, cash, "march cash", "20.00 USD", accounting.Debit, false)
+	g.addComponent(t, unapproved, sales, "march sales", "20.00 USD", accounting.Credit, false)
+	g.link(bucket, unapproved, "unapproved")
+	g.link(unapproved, bucket, "unapproved")
+
+	before := g.snapshot()
+
+	same, err := accounting.DiffSnapshots(ctx, before, g.snapshot())
+	assert.NilError(t, err)
+	assert.Assert(t, same.Empty())
+	assert.Equal(t, len(same.Balances), 0)
+	assert.Equal(t, len(same.Approved), 0)
+
+	trx := g.addTransaction(t, ledger, 7, "2021-04-01T00:00:00.000", "April sales")
+	g.addComponent(t, trx, cash, "cash", "50.00 USD", accounting.Debit, true)
+	g.addComponent(t, trx, sales, "sales", "50.00 USD", accounting.Credit, true)
+	g.link(bucket, trx, "approved")
+	g.link(trx, bucket, "approved")
+	g.setBalances(t, cash, "1100/2", "150.00 USD")
+	g.setBalances(t, sales, "4100/2", "-150.00 USD")
+
+	diff, err := accounting.DiffSnapshots(ctx, before, g.snapshot())
+	assert.NilError(t, err)
+
+	assert.Assert(t, !diff.Empty())
+	assert.Equal(t, len(diff.DocumentsAdded), 5)
+	assert.Equal(t, len(diff.DocumentsRemoved), 2)
+	assert.Equal(t, len(diff.EdgesAdded), 12)
+	assert.Equal(t, len(diff.EdgesRemoved), 2)
+
+	assert.Equal(t, len(diff.Balances), 2)
+	assert.Equal(t, diff.Balances[0].Account.Code, "1100")
+	assert.Equal(t, diff.Balances[0].AccountDelta["USD"].String(), "50.00 USD")
+	assert.Equal(t, diff.Balances[0].Before.Account["USD"].String(), "100.00 USD")
+	assert.Equal(t, diff.Balances[1].Account.Code, "4100")
+	assert.Equal(t, diff.Balances[1].GlobalDelta["USD"].String(), "-50.00 USD")
+
+	assert.Equal(t, len(diff.Approved), 1)
+	assert.Equal(t, diff.Approved[0].ID, int64(7))
+
+	var out bytes.Buffer
+	assert.NilError(t, diff.WriteText(&out))
+	assert.Assert(t, strings.Contains(out.String(), "documents: +5 -2\n"))
+	assert.Assert(t, strings.Contains(out.String(), "1100 Cash account: [50.00 USD] global: [50.00 USD]\n"))
+	assert.Assert(t, strings.Contains(out.String(), "approved: 1\n7 2021-04-01T00:00:00Z April sales\n"))
+
+	out.Reset()
+	assert.NilError(t, diff.WriteJSON(&out))
+	assert.Assert(t, strings.Contains(out.String(), `"account_delta"`))
+}
@@ -0,0 +1,82 @@
+package accounting_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+	"gotest.tools/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("cleos dumps", func(t *testing.T) {
+
+		snapshot, err := accounting.LoadSnapshot("../documents", "../edges")
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(snapshot.Documents()), 14)
+		assert.Equal(t, len(snapshot.Edges()), 397)
+		assert.Equal(t, len(snapshot.EdgesNamed("acctcmp")), 14)
+
+		root, err := snapshot.Document(ctx, snapshot.Documents()[0].Hash)
+		assert.NilError(t, err)
+		assert.Equal(t, root.ID, uint64(0))
+
+		event := snapshot.EdgesNamed("event")[0]
+		from, err := snapshot.EdgesFrom(ctx, event.FromNode, "event")
+		assert.NilError(t, err)
+		assert.Assert(t, len(from) > 1)
+
+		to, err := snapshot.EdgesTo(ctx, event.ToNode, "event")
+		assert.NilError(t, err)
+		assert.Equal(t, len(to), 1)
+
+		_, err = snapshot.Document(ctx, event.ToNode)
+		assert.ErrorContains(t, err, "document not found")
+	})
+
+	t.Run("Bare arrays", func(t *testing.T) {
+
+		documents := `[{ "id": 3, "hash": "4c807227a2c9d7ebe5b22050f6d3f0d4318fcb57904e19e18746ae0309024481", "content_groups": [] }]`
+		edges := `[]`
+
+		snapshot, err := accounting.ReadSnapshot(strings.NewReader(documents), strings.NewReader(edges))
+		assert.NilError(t, err)
+		assert.Equal(t, len(snapshot.Documents()), 1)
+
+		_, err = accounting.ReadSnapshot(strings.NewReader(`{ "more": false }`), strings.NewReader(edges))
+		assert.ErrorContains(t, err, "missing rows")
+	})
+
+	t.Run("Reports", func(t *testing.T) {
+
+		g := newMemoryGraph()
+		ledger := g.addLedger(t, "Main")
+		assets := g.addAccount(t, ledger, "1000", "Assets", accounting.Debit, false)
+		g.addAccount(t, assets, "1100", "Cash", accounting.Debit, true)
+
+		var documents []docgraph.Document
+		for _, document := range g.documents {
+			documents = append(documents, document)
+		}
+
+		snapshot := accounting.NewSnapshot(documents, g.edges)
+
+		chart, err := accounting.ExportChart(ctx, snapshot, ledger)
+		assert.NilError(t, err)
+		assert.Equal(t, len(chart), 2)
+		assert.Equal(t, chart[1].Path, "Assets / Cash")
+
+		edges, err := snapshot.GetAllEdgesForDocument(ctx, docgraph.Document{Hash: assets})
+		assert.NilError(t, err)
+		assert.Equal(t, len(edges["from"]), 3)
+		assert.Equal(t, len(edges["to"]), 2)
+		assert.DeepEqual(t, edges["to"][0].FromNode, eos.Checksum256(ledger))
+	})
+}
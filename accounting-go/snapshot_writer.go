@@ -0,0 +1,390 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	eos "github.com/eoscanada/eos-go"
+)
+
+// SnapshotFile records the number of rows and the sha256 of a snapshot file
+type SnapshotFile struct {
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// SnapshotManifest describes a snapshot directory written by
+// Client.WriteSnapshot
+type SnapshotManifest struct {
+	Contract eos.AccountName `json:"contract"`
+	ChainID  eos.Checksum256 `json:"chain_id"`
+	// HeadBlockNum and HeadBlockID are read before the tables, EndBlockNum
+	// and EndBlockID after them
+	HeadBlockNum uint32          `json:"head_block_num"`
+	HeadBlockID  eos.Checksum256 `json:"head_block_id"`
+	EndBlockNum  uint32          `json:"end_block_num"`
+	EndBlockID   eos.Checksum256 `json:"end_block_id"`
+	// TailUnchanged is set when the last rows of the documents, edges and
+	// cursors tables were the same before and after reading them, so no
+	// row was appended meanwhile. It doesn't detect rows erased or modified
+	// in the middle of the tables nor changes of the exrates tables.
+	TailUnchanged bool `json:"tail_unchanged"`
+	// Files is keyed by file name
+	Files map[string]SnapshotFile `json:"files"`
+}
+
+// snapshotTables are the tables of the contract scope copied to a snapshot
+var snapshotTables = []struct {
+	table string
+	file  string
+}{
+	{"documents", SnapshotDocumentsFile},
+	{"edges", SnapshotEdgesFile},
+	{"cursors", SnapshotCursorsFile},
+}
+
+func (c *Client) chainInfo(ctx context.Context) (*eos.InfoResp, error) {
+
+	var info eos.InfoResp
+
+	if err := c.chainCall(ctx, "get_info", struct{}{}, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// WriteSnapshot copies the documents, edges, cursors and exrates tables and
+// the settings document of the contract to dir, with a manifest recording
+// the head block and the rows and sha256 of every file. The files are
+// written compact in table order so copies of the same tables are equal.
+func (c *Client) WriteSnapshot(ctx context.Context, dir string) (*SnapshotManifest, error) {
+
+	start, err := c.chainInfo(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("write snapshot: %v", err)
+	}
+
+	manifest := &SnapshotManifest{
+		Contract:     c.contract,
+		ChainID:      start.ChainID,
+		HeadBlockNum: start.HeadBlockNum,
+		HeadBlockID:  start.HeadBlockID,
+		Files:        make(map[string]SnapshotFile),
+	}
+
+	tables := make(map[string][]json.RawMessage)
+
+	for _, t := range snapshotTables {
+
+		rows := []json.RawMessage{}
+
+		err := c.ScanTable(ctx, TableQuery{Table: t.table, PageSize: 1000}, func(row json.RawMessage) error {
+			rows = append(rows, row)
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("write snapshot: %v", err)
+		}
+
+		tables[t.table] = rows
+	}
+
+	scopes, err := c.TableScopes(ctx, "exrates")
+
+	if err != nil {
+		return nil, fmt.Errorf("write snapshot: %v", err)
+	}
+
+	exrates := make(map[string][]json.RawMessage)
+	exratesRows := 0
+
+	for _, scope := range scopes {
+
+		rows := []json.RawMessage{}
+
+		err := c.ScanTable(ctx, TableQuery{Table: "exrates", Scope: scope, PageSize: 1000}, func(row json.RawMessage) error {
+			rows = append(rows, row)
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("write snapshot: %v", err)
+		}
+
+		exrates[scope] = rows
+		exratesRows += len(rows)
+	}
+
+	settings, err := snapshotSettings(tables["documents"], tables["edges"])
+
+	if err != nil {
+		return nil, fmt.Errorf("write snapshot: %v", err)
+	}
+
+	end, err := c.chainInfo(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("write snapshot: %v", err)
+	}
+
+	manifest.EndBlockNum = end.HeadBlockNum
+	manifest.EndBlockID = end.HeadBlockID
+
+	if manifest.TailUnchanged, err = c.lastRowsUnchanged(ctx, tables); err != nil {
+		return nil, fmt.Errorf("write snapshot: %v", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("write snapshot: %v", err)
+	}
+
+	for _, t := range snapshotTables {
+		if err := writeSnapshotFile(dir, t.file, len(tables[t.table]), tables[t.table], manifest.Files); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeSnapshotFile(dir, SnapshotExratesFile, exratesRows, exrates, manifest.Files); err != nil {
+		return nil, err
+	}
+
+	settingsRows := 0
+	if settings != nil {
+		settingsRows = 1
+	}
+
+	if err := writeSnapshotFile(dir, SnapshotSettingsFile, settingsRows, settings, manifest.Files); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+
+	if err != nil {
+		return nil, fmt.Errorf("write snapshot manifest: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, SnapshotManifestFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("write snapshot manifest: %v", err)
+	}
+
+	return manifest, nil
+}
+
+// lastRowsUnchanged compares the last rows of the tables read by
+// WriteSnapshot with the last rows of the tables on the node
+func (c *Client) lastRowsUnchanged(ctx context.Context, tables map[string][]json.RawMessage) (bool, error) {
+
+	for _, t := range snapshotTables {
+
+		page, err := c.getTablePage(ctx, eos.GetTableRowsRequest{
+			Code:    string(c.contract),
+			Scope:   string(c.contract),
+			Table:   t.table,
+			Limit:   1,
+			Reverse: true,
+			JSON:    true,
+		})
+
+		if err != nil {
+			return false, fmt.Errorf("last row of %v: %v", t.table, err)
+		}
+
+		rows := tables[t.table]
+
+		if len(page.Rows) == 0 || len(rows) == 0 {
+			if len(page.Rows) != len(rows) {
+				return false, nil
+			}
+			continue
+		}
+
+		var read, last bytes.Buffer
+
+		if err := json.Compact(&read, rows[len(rows)-1]); err != nil {
+			return false, err
+		}
+
+		if err := json.Compact(&last, page.Rows[0]); err != nil {
+			return false, err
+		}
+
+		if !bytes.Equal(read.Bytes(), last.Bytes()) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// snapshotSettings returns the row of the document linked by the latest
+// settings edge, nil if there is none
+func snapshotSettings(documents, edges []json.RawMessage) (json.RawMessage, error) {
+
+	found := false
+	var settingsID uint64
+	var settingsHash string
+
+	for _, row := range edges {
+
+		var edge struct {
+			ID       uint64 `json:"id"`
+			ToNode   string `json:"to_node"`
+			EdgeName string `json:"edge_name"`
+		}
+
+		if err := json.Unmarshal(row, &edge); err != nil {
+			return nil, fmt.Errorf("decode edge: %v", err)
+		}
+
+		if edge.EdgeName == settingsEdge && (!found || edge.ID > settingsID) {
+			found, settingsID, settingsHash = true, edge.ID, edge.ToNode
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	for _, row := range documents {
+
+		var document struct {
+			Hash string `json:"hash"`
+		}
+
+		if err := json.Unmarshal(row, &document); err != nil {
+			return nil, fmt.Errorf("decode document: %v", err)
+		}
+
+		if document.Hash == settingsHash {
+			return row, nil
+		}
+	}
+
+	return nil, fmt.Errorf("settings document not found %v", settingsHash)
+}
+
+func writeSnapshotFile(dir, name string, rows int, value interface{}, files map[string]SnapshotFile) error {
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return fmt.Errorf("write snapshot %v: %v", name, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return fmt.Errorf("write snapshot %v: %v", name, err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	files[name] = SnapshotFile{
+		Rows:   rows,
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+
+	return nil
+}
+
+// ReadSnapshotManifest reads the manifest of the snapshot in dir
+func ReadSnapshotManifest(dir string) (*SnapshotManifest, error) {
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, SnapshotManifestFile))
+
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot manifest: %v", err)
+	}
+
+	var manifest SnapshotManifest
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("read snapshot manifest: %v", err)
+	}
+
+	return &manifest, nil
+}
+
+// VerifySnapshotDir checks the files of the snapshot in dir against the
+// sha256 and the number of rows recorded in its manifest
+func VerifySnapshotDir(dir string) (*SnapshotManifest, error) {
+
+	manifest, err := ReadSnapshotManifest(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for name, file := range manifest.Files {
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+
+		if err != nil {
+			return nil, fmt.Errorf("verify snapshot: %v", err)
+		}
+
+		sum := sha256.Sum256(data)
+
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("verify snapshot: %v sha256 %x, manifest has %v", name, sum, file.SHA256)
+		}
+
+		rows, err := snapshotFileRows(name, data)
+
+		if err != nil {
+			return nil, fmt.Errorf("verify snapshot: %v: %v", name, err)
+		}
+
+		if rows != file.Rows {
+			return nil, fmt.Errorf("verify snapshot: %v has %v rows, manifest has %v", name, rows, file.Rows)
+		}
+	}
+
+	return manifest, nil
+}
+
+// snapshotFileRows counts the rows of a snapshot file, exrates.json holds
+// the rows keyed by scope and settings.json a single document
+func snapshotFileRows(name string, data []byte) (int, error) {
+
+	switch name {
+	case SnapshotExratesFile:
+
+		var scopes map[string][]json.RawMessage
+
+		if err := json.Unmarshal(data, &scopes); err != nil {
+			return 0, err
+		}
+
+		rows := 0
+		for _, scope := range scopes {
+			rows += len(scope)
+		}
+
+		return rows, nil
+
+	case SnapshotSettingsFile:
+
+		if string(bytes.TrimSpace(data)) == "null" {
+			return 0, nil
+		}
+
+		return 1, nil
+	}
+
+	var rows []json.RawMessage
+
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return 0, err
+	}
+
+	return len(rows), nil
+}
@@ -0,0 +1,230 @@
+package accounting_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+// chainServer serves get_info, get_table_by_scope and get_table_rows from
+// tables keyed by table and scope, the bounds are indexes in the rows
+type chainServer struct {
+	t      *testing.T
+	head   uint32
+	tables map[string]map[string][]json.RawMessage
+	// write is called after every page with the table read, to change the
+	// tables while they are copied
+	write func(table string)
+}
+
+func (s *chainServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var request map[string]interface{}
+	assert.NilError(s.t, json.NewDecoder(r.Body).Decode(&request))
+
+	var response interface{}
+
+	switch r.URL.Path {
+	case "/v1/chain/get_info":
+
+		s.head++
+		response = map[string]interface{}{
+			"chain_id":       "8a34ec7df1b8cd06ff4a8abbaa7cc50300823350cadc59ab296cb00d104d2b8f",
+			"head_block_num": s.head,
+			"head_block_id":  "0000000a6a3e5ab5c6b0bd4fd0ee0d2a1c58de9bbe2e70b3a63fbc12a5d4d3b0",
+		}
+
+	case "/v1/chain/get_table_by_scope":
+
+		rows := []map[string]interface{}{}
+		for scope, scoped := range s.tables[request["table"].(string)] {
+			rows = append(rows, map[string]interface{}{"scope": scope, "count": len(scoped)})
+		}
+		response = map[string]interface{}{"rows": rows, "more": ""}
+
+	case "/v1/chain/get_table_rows":
+
+		rows := s.tables[request["table"].(string)][request["scope"].(string)]
+		limit := int(request["limit"].(float64))
+		page := map[string]interface{}{}
+
+		if request["reverse"] == true {
+			if len(rows) > 0 {
+				rows = rows[len(rows)-1:]
+			}
+		} else {
+			lower := 0
+			if bound, ok := request["lower_bound"].(string); ok {
+				lower, _ = strconv.Atoi(bound)
+			}
+			rows = rows[lower:]
+			if len(rows) > limit {
+				rows = rows[:limit]
+				page["more"] = true
+				page["next_key"] = strconv.Itoa(lower + limit)
+			}
+		}
+
+		page["rows"] = rows
+		response = page
+
+		if s.write != nil {
+			s.write(request["table"].(string))
+		}
+
+	default:
+		s.t.Fatalf("unexpected request %v", r.URL.Path)
+	}
+
+	assert.NilError(s.t, json.NewEncoder(w).Encode(response))
+}
+
+func rawRows(t *testing.T, count int, row func(i int) interface{}) []json.RawMessage {
+
+	rows := make([]json.RawMessage, count)
+
+	for i := range rows {
+		data, err := json.Marshal(row(i))
+		assert.NilError(t, err)
+		rows[i] = data
+	}
+
+	return rows
+}
+
+func TestWriteSnapshot(t *testing.T) {
+
+	ctx := context.Background()
+
+	newChain := func(t *testing.T) *chainServer {
+
+		settingsHash := "a9da298d114cc185a552f445766f05477d497325e0358f83e88c9fe131bddba1"
+
+		documents := rawRows(t, 1500, func(i int) interface{} {
+			hash := strconv.Itoa(i)
+			if i == 7 {
+				hash = settingsHash
+			}
+			return map[string]interface{}{"id": i, "hash": hash, "content_groups": []interface{}{}}
+		})
+
+		edges := rawRows(t, 3, func(i int) interface{} {
+			return map[string]interface{}{"id": i, "from_node": "0", "to_node": strconv.Itoa(i + 1), "edge_name": "ledger"}
+		})
+		edges = append(edges, json.RawMessage(`{"id":3,"from_node":"0","to_node":"`+settingsHash+`","edge_name":"settings"}`))
+
+		return &chainServer{
+			t: t,
+			tables: map[string]map[string][]json.RawMessage{
+				"documents": {"accounting": documents},
+				"edges":     {"accounting": edges},
+				"cursors":   {"accounting": rawRows(t, 2, func(i int) interface{} { return map[string]interface{}{"key": i} })},
+				"exrates": {
+					"USD":  rawRows(t, 3, func(i int) interface{} { return map[string]interface{}{"id": i, "to": "TLOS"} }),
+					"HUSD": rawRows(t, 1, func(i int) interface{} { return map[string]interface{}{"id": i, "to": "USD"} }),
+				},
+			},
+		}
+	}
+
+	t.Run("writes every table", func(t *testing.T) {
+
+		chain := newChain(t)
+		server := httptest.NewServer(chain)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		dir, err := ioutil.TempDir("", "snapshot")
+		assert.NilError(t, err)
+		defer os.RemoveAll(dir)
+
+		manifest, err := client.WriteSnapshot(ctx, dir)
+		assert.NilError(t, err)
+
+		assert.Equal(t, manifest.Contract, eos.AN("accounting"))
+		assert.Equal(t, manifest.HeadBlockNum, uint32(1))
+		assert.Equal(t, manifest.EndBlockNum, uint32(2))
+		assert.Assert(t, manifest.TailUnchanged)
+
+		rows := make(map[string]int)
+		for name, file := range manifest.Files {
+			rows[name] = file.Rows
+		}
+		assert.DeepEqual(t, rows, map[string]int{
+			accounting.SnapshotDocumentsFile: 1500,
+			accounting.SnapshotEdgesFile:     4,
+			accounting.SnapshotCursorsFile:   2,
+			accounting.SnapshotExratesFile:   4,
+			accounting.SnapshotSettingsFile:  1,
+		})
+
+		verified, err := accounting.VerifySnapshotDir(dir)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, verified.Files, manifest.Files)
+
+		settings, err := ioutil.ReadFile(filepath.Join(dir, accounting.SnapshotSettingsFile))
+		assert.NilError(t, err)
+		assert.Equal(t, string(settings), string(chain.tables["documents"]["accounting"][7]))
+
+		// A second copy of the same tables is byte for byte equal
+		again, err := ioutil.TempDir("", "snapshot")
+		assert.NilError(t, err)
+		defer os.RemoveAll(again)
+
+		_, err = client.WriteSnapshot(ctx, again)
+		assert.NilError(t, err)
+
+		_, err = accounting.VerifySnapshotDir(again)
+		assert.NilError(t, err)
+
+		for _, name := range []string{accounting.SnapshotDocumentsFile, accounting.SnapshotExratesFile} {
+			first, err := ioutil.ReadFile(filepath.Join(dir, name))
+			assert.NilError(t, err)
+			second, err := ioutil.ReadFile(filepath.Join(again, name))
+			assert.NilError(t, err)
+			assert.Equal(t, string(first), string(second))
+		}
+
+		// Tampered files fail the verification
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, accounting.SnapshotEdgesFile), []byte("[]"), 0644))
+		_, err = accounting.VerifySnapshotDir(dir)
+		assert.ErrorContains(t, err, "edges.json sha256")
+	})
+
+	t.Run("detects writes during the copy", func(t *testing.T) {
+
+		chain := newChain(t)
+		chain.write = func(table string) {
+			edges := chain.tables["edges"]["accounting"]
+			if table == "cursors" && len(edges) == 4 {
+				chain.tables["edges"]["accounting"] = append(edges, json.RawMessage(`{"id":4,"edge_name":"ledger"}`))
+			}
+		}
+
+		server := httptest.NewServer(chain)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		dir, err := ioutil.TempDir("", "snapshot")
+		assert.NilError(t, err)
+		defer os.RemoveAll(dir)
+
+		manifest, err := client.WriteSnapshot(ctx, dir)
+		assert.NilError(t, err)
+		assert.Assert(t, !manifest.TailUnchanged)
+	})
+}
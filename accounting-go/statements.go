@@ -0,0 +1,397 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	eos "github.com/eoscanada/eos-go"
+)
+
+// StatementLine is an account of a financial statement, its amounts are in
+// the natural sign of its type: debit balances are positive for assets,
+// expenses and losses, credit balances are positive for the other types
+type StatementLine struct {
+	Code  string      `json:"code"`
+	Name  string      `json:"name"`
+	Type  AccountType `json:"account_type"`
+	Depth int         `json:"depth"`
+	// Amounts rolls up the account balances of the leafs below the account
+	// that share its type, keyed by currency
+	Amounts  map[string]eos.Asset `json:"amounts"`
+	Children []StatementLine      `json:"children,omitempty"`
+}
+
+// StatementSection groups the accounts of a type
+type StatementSection struct {
+	Type   AccountType          `json:"account_type"`
+	Lines  []StatementLine      `json:"lines"`
+	Totals map[string]eos.Asset `json:"totals"`
+}
+
+// IncomeStatement reports the revenue, gains, expenses and losses of a ledger
+type IncomeStatement struct {
+	Ledger    eos.Checksum256      `json:"ledger"`
+	Revenue   StatementSection     `json:"revenue"`
+	Gains     StatementSection     `json:"gains"`
+	Expenses  StatementSection     `json:"expenses"`
+	Losses    StatementSection     `json:"losses"`
+	NetIncome map[string]eos.Asset `json:"net_income"`
+	// Unclassified lists the codes of the accounts without account type,
+	// created before the contract stored it
+	Unclassified []string `json:"unclassified,omitempty"`
+}
+
+// BalanceSheet reports the assets, liabilities and equity of a ledger. The
+// net income is not closed into equity by the contract so it is reported
+// apart and counted with the equity when checking the balance.
+type BalanceSheet struct {
+	Ledger      eos.Checksum256      `json:"ledger"`
+	Assets      StatementSection     `json:"assets"`
+	Liabilities StatementSection     `json:"liabilities"`
+	Equity      StatementSection     `json:"equity"`
+	NetIncome   map[string]eos.Asset `json:"net_income"`
+	// Differences holds Assets - (Liabilities + Equity + NetIncome) for the
+	// currencies where it isn't zero
+	Differences  map[string]eos.Asset `json:"differences,omitempty"`
+	Unclassified []string             `json:"unclassified,omitempty"`
+}
+
+// isDebitNature returns true for the types whose balances are debits
+func isDebitNature(accountType AccountType) bool {
+	switch accountType {
+	case AccountTypeAsset, AccountTypeExpense, AccountTypeLoss:
+		return true
+	}
+	return false
+}
+
+// statementBuilder splits a ledger tree in sections by account type, an
+// account whose type differs from its parent's starts a new line at the top
+// of its section
+type statementBuilder struct {
+	sections     map[AccountType]*StatementSection
+	unclassified []string
+}
+
+func newStatementBuilder(tree *LedgerTree) *statementBuilder {
+
+	b := &statementBuilder{
+		sections: make(map[AccountType]*StatementSection),
+	}
+
+	for _, node := range tree.Accounts {
+		b.root(node)
+	}
+
+	sort.Strings(b.unclassified)
+
+	return b
+}
+
+func (b *statementBuilder) root(node LedgerNode) {
+
+	if node.Account.Type < AccountTypeAsset || node.Account.Type > AccountTypeLoss {
+		b.unclassified = append(b.unclassified, node.Account.Code)
+		for _, child := range node.Children {
+			b.root(child)
+		}
+		return
+	}
+
+	line := b.line(node, 0)
+	section := b.section(node.Account.Type)
+
+	section.Lines = append(section.Lines, line)
+	for _, amount := range line.Amounts {
+		assetSums(section.Totals).add(amount)
+	}
+}
+
+func (b *statementBuilder) line(node LedgerNode, depth int) StatementLine {
+
+	line := StatementLine{
+		Code:    node.Account.Code,
+		Name:    node.Account.Name,
+		Type:    node.Account.Type,
+		Depth:   depth,
+		Amounts: make(map[string]eos.Asset),
+	}
+
+	if len(node.Children) == 0 {
+		if node.Balances != nil {
+			for _, balance := range node.Balances.Account {
+				if !isDebitNature(node.Account.Type) {
+					balance = negateAsset(balance)
+				}
+				assetSums(line.Amounts).add(balance)
+			}
+		}
+		return line
+	}
+
+	for _, child := range node.Children {
+
+		if child.Account.Type != node.Account.Type {
+			b.root(child)
+			continue
+		}
+
+		childLine := b.line(child, depth+1)
+
+		for _, amount := range childLine.Amounts {
+			assetSums(line.Amounts).add(amount)
+		}
+
+		line.Children = append(line.Children, childLine)
+	}
+
+	return line
+}
+
+func (b *statementBuilder) section(accountType AccountType) *StatementSection {
+
+	section, ok := b.sections[accountType]
+
+	if !ok {
+		section = &StatementSection{
+			Type:   accountType,
+			Totals: make(map[string]eos.Asset),
+		}
+		b.sections[accountType] = section
+	}
+
+	return section
+}
+
+// netIncome returns Revenue + Gains - Expenses - Losses by currency
+func (b *statementBuilder) netIncome() map[string]eos.Asset {
+
+	netIncome := make(assetSums)
+
+	for _, accountType := range []AccountType{AccountTypeRevenue, AccountTypeGain} {
+		for _, amount := range b.section(accountType).Totals {
+			netIncome.add(amount)
+		}
+	}
+
+	for _, accountType := range []AccountType{AccountTypeExpense, AccountTypeLoss} {
+		for _, amount := range b.section(accountType).Totals {
+			netIncome.add(negateAsset(amount))
+		}
+	}
+
+	return netIncome
+}
+
+// NewIncomeStatement builds the income statement from the balances of tree
+func NewIncomeStatement(tree *LedgerTree) *IncomeStatement {
+
+	b := newStatementBuilder(tree)
+
+	return &IncomeStatement{
+		Ledger:       tree.Ledger,
+		Revenue:      *b.section(AccountTypeRevenue),
+		Gains:        *b.section(AccountTypeGain),
+		Expenses:     *b.section(AccountTypeExpense),
+		Losses:       *b.section(AccountTypeLoss),
+		NetIncome:    b.netIncome(),
+		Unclassified: b.unclassified,
+	}
+}
+
+// NewBalanceSheet builds the balance sheet from the balances of tree
+func NewBalanceSheet(tree *LedgerTree) *BalanceSheet {
+
+	b := newStatementBuilder(tree)
+	netIncome := b.netIncome()
+
+	sheet := &BalanceSheet{
+		Ledger:       tree.Ledger,
+		Assets:       *b.section(AccountTypeAsset),
+		Liabilities:  *b.section(AccountTypeLiability),
+		Equity:       *b.section(AccountTypeEquity),
+		NetIncome:    netIncome,
+		Unclassified: b.unclassified,
+	}
+
+	differences := make(assetSums)
+
+	for _, amount := range sheet.Assets.Totals {
+		differences.add(amount)
+	}
+
+	for _, amounts := range []map[string]eos.Asset{sheet.Liabilities.Totals, sheet.Equity.Totals, netIncome} {
+		for _, amount := range amounts {
+			differences.add(negateAsset(amount))
+		}
+	}
+
+	for currency, difference := range differences {
+		if difference.Amount != 0 {
+			if sheet.Differences == nil {
+				sheet.Differences = make(map[string]eos.Asset)
+			}
+			sheet.Differences[currency] = difference
+		}
+	}
+
+	return sheet
+}
+
+// Balanced returns true when Assets = Liabilities + Equity + NetIncome for
+// every currency
+func (s *BalanceSheet) Balanced() bool {
+	return len(s.Differences) == 0
+}
+
+// GetIncomeStatement reads the income statement of ledger
+func (c *Client) GetIncomeStatement(ctx context.Context, ledger eos.Checksum256) (*IncomeStatement, error) {
+
+	tree, err := c.GetLedgerTree(ctx, ledger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewIncomeStatement(tree), nil
+}
+
+// GetBalanceSheet reads the balance sheet of ledger
+func (c *Client) GetBalanceSheet(ctx context.Context, ledger eos.Checksum256) (*BalanceSheet, error) {
+
+	tree, err := c.GetLedgerTree(ctx, ledger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBalanceSheet(tree), nil
+}
+
+// statementTable renders the sections of a statement with a column per
+// currency
+type statementTable struct {
+	currencies []string
+	table      *simpletable.Table
+}
+
+func newStatementTable(amounts ...map[string]eos.Asset) *statementTable {
+
+	seen := make(map[string]bool)
+	t := &statementTable{table: simpletable.New()}
+
+	for _, byCurrency := range amounts {
+		for currency := range byCurrency {
+			if !seen[currency] {
+				seen[currency] = true
+				t.currencies = append(t.currencies, currency)
+			}
+		}
+	}
+
+	sort.Strings(t.currencies)
+
+	header := []*simpletable.Cell{{Align: simpletable.AlignCenter, Text: "Account"}}
+	for _, currency := range t.currencies {
+		header = append(header, &simpletable.Cell{Align: simpletable.AlignCenter, Text: currency})
+	}
+	t.table.Header = &simpletable.Header{Cells: header}
+
+	return t
+}
+
+func (t *statementTable) row(label string, amounts map[string]eos.Asset) {
+
+	cells := []*simpletable.Cell{{Align: simpletable.AlignLeft, Text: label}}
+
+	for _, currency := range t.currencies {
+		text := ""
+		if amount, ok := amounts[currency]; ok {
+			text = amount.String()
+		}
+		cells = append(cells, &simpletable.Cell{Align: simpletable.AlignRight, Text: text})
+	}
+
+	t.table.Body.Cells = append(t.table.Body.Cells, cells)
+}
+
+func (t *statementTable) section(title string, section StatementSection) {
+
+	t.row(title, nil)
+
+	var writeLines func(lines []StatementLine)
+	writeLines = func(lines []StatementLine) {
+		for _, line := range lines {
+			t.row(strings.Repeat("  ", line.Depth+1)+line.Code+" "+line.Name, line.Amounts)
+			writeLines(line.Children)
+		}
+	}
+
+	writeLines(section.Lines)
+	t.row("Total "+strings.ToLower(title), section.Totals)
+}
+
+func (t *statementTable) write(w io.Writer, what string) error {
+	if _, err := fmt.Fprintln(w, t.table.String()); err != nil {
+		return fmt.Errorf("write %v: %v", what, err)
+	}
+	return nil
+}
+
+func writeStatementJSON(w io.Writer, what string, statement interface{}) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(statement); err != nil {
+		return fmt.Errorf("write %v: %v", what, err)
+	}
+
+	return nil
+}
+
+// WriteText writes the income statement as a table
+func (s *IncomeStatement) WriteText(w io.Writer) error {
+
+	t := newStatementTable(s.Revenue.Totals, s.Gains.Totals, s.Expenses.Totals, s.Losses.Totals)
+
+	t.section("Revenue", s.Revenue)
+	t.section("Gains", s.Gains)
+	t.section("Expenses", s.Expenses)
+	t.section("Losses", s.Losses)
+	t.row("Net income", s.NetIncome)
+
+	return t.write(w, "income statement")
+}
+
+// WriteJSON writes the income statement as indented JSON
+func (s *IncomeStatement) WriteJSON(w io.Writer) error {
+	return writeStatementJSON(w, "income statement", s)
+}
+
+// WriteText writes the balance sheet as a table
+func (s *BalanceSheet) WriteText(w io.Writer) error {
+
+	t := newStatementTable(s.Assets.Totals, s.Liabilities.Totals, s.Equity.Totals, s.NetIncome)
+
+	t.section("Assets", s.Assets)
+	t.section("Liabilities", s.Liabilities)
+	t.section("Equity", s.Equity)
+	t.row("Net income", s.NetIncome)
+
+	if !s.Balanced() {
+		t.row("Difference", s.Differences)
+	}
+
+	return t.write(w, "balance sheet")
+}
+
+// WriteJSON writes the balance sheet as indented JSON
+func (s *BalanceSheet) WriteJSON(w io.Writer) error {
+	return writeStatementJSON(w, "balance sheet", s)
+}
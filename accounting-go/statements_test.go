@@ -0,0 +1,85 @@
+package accounting_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func typedNode(code, name string, accountType accounting.AccountType, balance string, children ...accounting.LedgerNode) accounting.LedgerNode {
+	node := accounting.LedgerNode{
+		Account:  accounting.Account{Code: code, Name: name, Type: accountType, IsLeaf: len(children) == 0},
+		Children: children,
+	}
+	if balance != "" {
+		amount, _ := eos.NewAssetFromString(balance)
+		node.Balances = &accounting.Balances{Account: map[string]eos.Asset{amount.Symbol.Symbol: amount}}
+	}
+	return node
+}
+
+func statementsTree() *accounting.LedgerTree {
+	return &accounting.LedgerTree{
+		Accounts: []accounting.LedgerNode{
+			typedNode("1000", "Assets", accounting.AccountTypeAsset, "",
+				typedNode("1100", "Cash", accounting.AccountTypeAsset, "700.00 USD"),
+				typedNode("1200", "Bank", accounting.AccountTypeAsset, "300.00 USD"),
+				typedNode("1300", "Prepaid rent", accounting.AccountTypeExpense, "200.00 USD"),
+			),
+			typedNode("2000", "Loans", accounting.AccountTypeLiability, "-300.00 USD"),
+			typedNode("3000", "Capital", accounting.AccountTypeEquity, "-500.00 USD"),
+			typedNode("4000", "Sales", accounting.AccountTypeRevenue, "-400.00 USD"),
+			typedNode("9000", "Legacy", accounting.AccountTypeUnknown, ""),
+		},
+	}
+}
+
+func TestIncomeStatement(t *testing.T) {
+
+	statement := accounting.NewIncomeStatement(statementsTree())
+
+	assert.Equal(t, statement.Revenue.Totals["USD"].String(), "400.00 USD")
+	assert.Equal(t, len(statement.Expenses.Lines), 1)
+	assert.Equal(t, statement.Expenses.Lines[0].Code, "1300")
+	assert.Equal(t, statement.Expenses.Totals["USD"].String(), "200.00 USD")
+	assert.Equal(t, len(statement.Gains.Lines), 0)
+	assert.Equal(t, statement.NetIncome["USD"].String(), "200.00 USD")
+	assert.DeepEqual(t, statement.Unclassified, []string{"9000"})
+
+	var out bytes.Buffer
+	assert.NilError(t, statement.WriteText(&out))
+	assert.Assert(t, strings.Contains(out.String(), "Net income"))
+	assert.Assert(t, strings.Contains(out.String(), "4000 Sales"))
+}
+
+func TestBalanceSheet(t *testing.T) {
+
+	tree := statementsTree()
+	sheet := accounting.NewBalanceSheet(tree)
+
+	assert.Equal(t, len(sheet.Assets.Lines), 1)
+	assert.Equal(t, len(sheet.Assets.Lines[0].Children), 2)
+	assert.Equal(t, sheet.Assets.Lines[0].Amounts["USD"].String(), "1000.00 USD")
+	assert.Equal(t, sheet.Liabilities.Totals["USD"].String(), "300.00 USD")
+	assert.Equal(t, sheet.Equity.Totals["USD"].String(), "500.00 USD")
+	assert.Equal(t, sheet.NetIncome["USD"].String(), "200.00 USD")
+	assert.Assert(t, sheet.Balanced())
+
+	tree.Accounts[1] = typedNode("2000", "Loans", accounting.AccountTypeLiability, "-250.00 USD")
+	sheet = accounting.NewBalanceSheet(tree)
+
+	assert.Assert(t, !sheet.Balanced())
+	assert.Equal(t, sheet.Differences["USD"].String(), "50.00 USD")
+
+	var out bytes.Buffer
+	assert.NilError(t, sheet.WriteText(&out))
+	assert.Assert(t, strings.Contains(out.String(), "Difference"))
+
+	out.Reset()
+	assert.NilError(t, sheet.WriteJSON(&out))
+	assert.Assert(t, strings.Contains(out.String(), `"differences"`))
+}
@@ -0,0 +1,380 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// DefaultPageSize is the number of rows requested per page when
+// TableQuery.PageSize is zero
+const DefaultPageSize = 100
+
+// TableQuery selects the rows of a contract table. The rows between
+// LowerBound and UpperBound (both inclusive, empty means unbounded) of the
+// index are read page by page following the next_key returned by the node.
+type TableQuery struct {
+	Table string
+	// Scope defaults to the contract account
+	Scope string
+	// Index is the index position, "1" or empty is the primary key, and
+	// KeyType the type of its key, e.g. "i64", "i128" or "sha256"
+	Index      string
+	KeyType    string
+	LowerBound string
+	UpperBound string
+	PageSize   uint32
+	Reverse    bool
+}
+
+// tablePage is the response of get_table_rows, more is a bool on old nodes
+// and the next key as a string on some others
+type tablePage struct {
+	Rows    []json.RawMessage `json:"rows"`
+	More    json.RawMessage   `json:"more"`
+	NextKey string            `json:"next_key"`
+}
+
+// next returns whether there are more rows and the key to continue from
+func (p *tablePage) next() (bool, string, error) {
+
+	nextKey := p.NextKey
+
+	if len(p.More) == 0 || string(p.More) == "null" {
+		return false, "", nil
+	}
+
+	var more bool
+
+	if err := json.Unmarshal(p.More, &more); err != nil {
+
+		if err := json.Unmarshal(p.More, &nextKey); err != nil {
+			return false, "", fmt.Errorf("decode more %s: %v", p.More, err)
+		}
+
+		more = nextKey != ""
+	}
+
+	if more && nextKey == "" {
+		return false, "", fmt.Errorf("more rows without next_key")
+	}
+
+	return more, nextKey, nil
+}
+
+// chainCall posts request to the chain API endpoint and decodes the
+// response into response
+func (c *Client) chainCall(ctx context.Context, endpoint string, request, response interface{}) error {
+
+	body, err := json.Marshal(request)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(c.api.BaseURL, "/")+"/v1/chain/"+endpoint, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	for key, values := range c.api.Header {
+		req.Header[key] = values
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.api.HttpClient
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v: status %v: %s", endpoint, resp.Status, bytes.TrimSpace(data))
+	}
+
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("%v: decode response: %v", endpoint, err)
+	}
+
+	return nil
+}
+
+// getTablePage requests a page of rows, eos.API.GetTableRows drops the
+// next_key of the response so the request is sent directly
+func (c *Client) getTablePage(ctx context.Context, request eos.GetTableRowsRequest) (*tablePage, error) {
+
+	var page tablePage
+
+	if err := c.chainCall(ctx, "get_table_rows", request, &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// ScanTable calls visit with every row selected by query, in index order or
+// reverse index order. It stops at the first error returned by visit or when
+// ctx is done.
+func (c *Client) ScanTable(ctx context.Context, query TableQuery, visit func(row json.RawMessage) error) error {
+
+	request := eos.GetTableRowsRequest{
+		Code:       string(c.contract),
+		Scope:      query.Scope,
+		Table:      query.Table,
+		Index:      query.Index,
+		KeyType:    query.KeyType,
+		LowerBound: query.LowerBound,
+		UpperBound: query.UpperBound,
+		Limit:      query.PageSize,
+		Reverse:    query.Reverse,
+		JSON:       true,
+	}
+
+	if request.Scope == "" {
+		request.Scope = string(c.contract)
+	}
+
+	if request.Limit == 0 {
+		request.Limit = DefaultPageSize
+	}
+
+	for {
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("scan %v: %v", query.Table, err)
+		}
+
+		page, err := c.getTablePage(ctx, request)
+
+		if err != nil {
+			return fmt.Errorf("scan %v: %v", query.Table, err)
+		}
+
+		for _, row := range page.Rows {
+			if err := visit(row); err != nil {
+				return err
+			}
+		}
+
+		more, nextKey, err := page.next()
+
+		if err != nil {
+			return fmt.Errorf("scan %v: %v", query.Table, err)
+		}
+
+		if !more {
+			return nil
+		}
+
+		// The next key is included in the next page so it is not skipped
+		// when a page ends in the middle of rows sharing a secondary key
+		if query.Reverse {
+			request.UpperBound = nextKey
+		} else {
+			request.LowerBound = nextKey
+		}
+	}
+}
+
+// StreamTable sends the rows selected by query to the returned channel,
+// which is closed after the last row. The error channel receives the error
+// that stopped the scan, if any, and is closed after the rows channel.
+func (c *Client) StreamTable(ctx context.Context, query TableQuery) (<-chan json.RawMessage, <-chan error) {
+
+	rows := make(chan json.RawMessage)
+	errs := make(chan error, 1)
+
+	go func() {
+
+		defer close(errs)
+
+		err := c.ScanTable(ctx, query, func(row json.RawMessage) error {
+			select {
+			case rows <- row:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("scan %v: %v", query.Table, ctx.Err())
+			}
+		})
+
+		close(rows)
+
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return rows, errs
+}
+
+// TableScopes returns the scopes of table that hold rows, e.g. the source
+// currencies of exrates
+func (c *Client) TableScopes(ctx context.Context, table string) ([]string, error) {
+
+	request := struct {
+		Code       string `json:"code"`
+		Table      string `json:"table"`
+		LowerBound string `json:"lower_bound,omitempty"`
+		Limit      uint32 `json:"limit"`
+	}{
+		Code:  string(c.contract),
+		Table: table,
+		Limit: DefaultPageSize,
+	}
+
+	var scopes []string
+
+	for {
+
+		var response struct {
+			Rows []struct {
+				Scope string `json:"scope"`
+				Count uint32 `json:"count"`
+			} `json:"rows"`
+			More string `json:"more"`
+		}
+
+		if err := c.chainCall(ctx, "get_table_by_scope", request, &response); err != nil {
+			return nil, fmt.Errorf("scopes of %v: %v", table, err)
+		}
+
+		for _, row := range response.Rows {
+			if row.Count > 0 {
+				scopes = append(scopes, row.Scope)
+			}
+		}
+
+		if response.More == "" {
+			return scopes, nil
+		}
+
+		request.LowerBound = response.More
+	}
+}
+
+// ScanDocuments calls visit with every document of the contract
+func (c *Client) ScanDocuments(ctx context.Context, visit func(document docgraph.Document) error) error {
+	return c.ScanTable(ctx, TableQuery{Table: "documents"}, func(row json.RawMessage) error {
+
+		var document docgraph.Document
+
+		if err := json.Unmarshal(row, &document); err != nil {
+			return fmt.Errorf("decode document: %v", err)
+		}
+
+		return visit(document)
+	})
+}
+
+// ScanEdges calls visit with every edge of the contract
+func (c *Client) ScanEdges(ctx context.Context, visit func(edge docgraph.Edge) error) error {
+	return c.ScanTable(ctx, TableQuery{Table: "edges"}, func(row json.RawMessage) error {
+
+		var edge docgraph.Edge
+
+		if err := json.Unmarshal(row, &edge); err != nil {
+			return fmt.Errorf("decode edge: %v", err)
+		}
+
+		return visit(edge)
+	})
+}
+
+// ScanCursors calls visit with every row of the cursors table
+func (c *Client) ScanCursors(ctx context.Context, visit func(cursor CursorRow) error) error {
+	return c.ScanTable(ctx, TableQuery{Table: "cursors"}, func(row json.RawMessage) error {
+
+		var cursor CursorRow
+
+		if err := json.Unmarshal(row, &cursor); err != nil {
+			return fmt.Errorf("decode cursor: %v", err)
+		}
+
+		return visit(cursor)
+	})
+}
+
+// ScanExchangeRates calls visit with the exchange rates from currency from
+// to currency to sorted by date. The secondary index of exrates is
+// (to << 64) + date so the rates of to are bounded by its symbol code.
+func (c *Client) ScanExchangeRates(ctx context.Context, from, to string, visit func(rate ExRateRow) error) error {
+
+	toSymbolCode, err := eos.StringToSymbolCode(to)
+
+	if err != nil {
+		return fmt.Errorf("scan exrates: invalid currency %v: %v", to, err)
+	}
+
+	query := TableQuery{
+		Table:      "exrates",
+		Scope:      from,
+		Index:      "2",
+		KeyType:    "i128",
+		LowerBound: eos.Uint128{Lo: 0, Hi: uint64(toSymbolCode)}.String(),
+		UpperBound: eos.Uint128{Lo: math.MaxUint64, Hi: uint64(toSymbolCode)}.String(),
+		PageSize:   1000,
+	}
+
+	return c.ScanTable(ctx, query, func(row json.RawMessage) error {
+
+		var rate ExRateRow
+
+		if err := json.Unmarshal(row, &rate); err != nil {
+			return fmt.Errorf("decode exrate: %v", err)
+		}
+
+		return visit(rate)
+	})
+}
+
+// ReadSnapshot reads the documents and edges tables into a Snapshot
+func (c *Client) ReadSnapshot(ctx context.Context) (*Snapshot, error) {
+
+	var documents []docgraph.Document
+
+	err := c.ScanDocuments(ctx, func(document docgraph.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []docgraph.Edge
+
+	err = c.ScanEdges(ctx, func(edge docgraph.Edge) error {
+		edges = append(edges, edge)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSnapshot(documents, edges), nil
+}
@@ -0,0 +1,202 @@
+package accounting_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+// tableServer serves get_table_rows for a table of rows keyed by id, the
+// next_key is reported in the more field when moreAsKey is set as some
+// nodes do
+func tableServer(t *testing.T, ids int, moreAsKey bool, requests *[]eos.GetTableRowsRequest) *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		assert.Equal(t, r.URL.Path, "/v1/chain/get_table_rows")
+
+		var request eos.GetTableRowsRequest
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&request))
+		*requests = append(*requests, request)
+
+		lower := 0
+		if request.LowerBound != "" {
+			lower, _ = strconv.Atoi(request.LowerBound)
+		}
+
+		upper := ids - 1
+		if request.UpperBound != "" {
+			upper, _ = strconv.Atoi(request.UpperBound)
+		}
+
+		response := map[string]interface{}{}
+		rows := []interface{}{}
+
+		for i := 0; i <= upper-lower; i++ {
+
+			id := lower + i
+			if request.Reverse {
+				id = upper - i
+			}
+
+			if len(rows) == int(request.Limit) {
+				if moreAsKey {
+					response["more"] = strconv.Itoa(id)
+				} else {
+					response["more"] = true
+					response["next_key"] = strconv.Itoa(id)
+				}
+				break
+			}
+
+			rows = append(rows, map[string]interface{}{
+				"key":         id,
+				"source":      request.Table,
+				"last_cursor": fmt.Sprintf("cursor-%v", id),
+			})
+		}
+
+		response["rows"] = rows
+		assert.NilError(t, json.NewEncoder(w).Encode(response))
+	}))
+}
+
+func TestScanTable(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("follows next_key", func(t *testing.T) {
+
+		var requests []eos.GetTableRowsRequest
+		server := tableServer(t, 25, false, &requests)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		var keys []uint64
+		err = client.ScanCursors(ctx, func(cursor accounting.CursorRow) error {
+			keys = append(keys, cursor.Key)
+			return nil
+		})
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(keys), 25)
+		for i, key := range keys {
+			assert.Equal(t, key, uint64(i))
+		}
+
+		assert.Equal(t, len(requests), 1)
+		assert.Equal(t, requests[0].Scope, "accounting")
+		assert.Equal(t, requests[0].Limit, uint32(accounting.DefaultPageSize))
+	})
+
+	t.Run("pages with more as key", func(t *testing.T) {
+
+		var requests []eos.GetTableRowsRequest
+		server := tableServer(t, 25, true, &requests)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		var keys []string
+		err = client.ScanTable(ctx, accounting.TableQuery{
+			Table:      "cursors",
+			LowerBound: "5",
+			PageSize:   10,
+		}, func(row json.RawMessage) error {
+			var cursor accounting.CursorRow
+			assert.NilError(t, json.Unmarshal(row, &cursor))
+			keys = append(keys, cursor.LastCursor)
+			return nil
+		})
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(keys), 20)
+		assert.Equal(t, keys[0], "cursor-5")
+		assert.Equal(t, keys[19], "cursor-24")
+
+		assert.Equal(t, len(requests), 2)
+		assert.Equal(t, requests[1].LowerBound, "15")
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+
+		var requests []eos.GetTableRowsRequest
+		server := tableServer(t, 25, false, &requests)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		var keys []uint64
+		err = client.ScanTable(ctx, accounting.TableQuery{
+			Table:    "cursors",
+			PageSize: 7,
+			Reverse:  true,
+		}, func(row json.RawMessage) error {
+			var cursor accounting.CursorRow
+			assert.NilError(t, json.Unmarshal(row, &cursor))
+			keys = append(keys, cursor.Key)
+			return nil
+		})
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(keys), 25)
+		assert.Equal(t, keys[0], uint64(24))
+		assert.Equal(t, keys[24], uint64(0))
+		assert.Equal(t, len(requests), 4)
+		assert.Equal(t, requests[1].UpperBound, "17")
+	})
+
+	t.Run("stream cancellation", func(t *testing.T) {
+
+		var requests []eos.GetTableRowsRequest
+		server := tableServer(t, 25, false, &requests)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		rows, errs := client.StreamTable(ctx, accounting.TableQuery{Table: "cursors", PageSize: 5})
+
+		received := 0
+		for range rows {
+			received++
+			if received == 3 {
+				cancel()
+			}
+		}
+
+		err = <-errs
+		assert.ErrorContains(t, err, "context canceled")
+		assert.Assert(t, received < 25)
+	})
+
+	t.Run("visit error", func(t *testing.T) {
+
+		var requests []eos.GetTableRowsRequest
+		server := tableServer(t, 25, false, &requests)
+		defer server.Close()
+
+		client, err := accounting.NewClient(eos.New(server.URL), eos.AN("accounting"))
+		assert.NilError(t, err)
+
+		err = client.ScanTable(ctx, accounting.TableQuery{Table: "cursors", PageSize: 5}, func(json.RawMessage) error {
+			return fmt.Errorf("stop")
+		})
+		assert.Error(t, err, "stop")
+		assert.Equal(t, len(requests), 1)
+	})
+}
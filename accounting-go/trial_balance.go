@@ -0,0 +1,273 @@
+package accounting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	eos "github.com/eoscanada/eos-go"
+)
+
+// TrialBalanceLine is the balance of a leaf account in one currency,
+// positive balances go to the debit column and negative ones to the credit
+// column
+type TrialBalanceLine struct {
+	Code   string    `json:"code"`
+	Name   string    `json:"name"`
+	Path   string    `json:"path"`
+	Debit  eos.Asset `json:"debit"`
+	Credit eos.Asset `json:"credit"`
+}
+
+// TrialBalanceCurrency is the trial balance of a currency
+type TrialBalanceCurrency struct {
+	Symbol      string             `json:"symbol"`
+	Lines       []TrialBalanceLine `json:"lines"`
+	TotalDebit  eos.Asset          `json:"total_debit"`
+	TotalCredit eos.Asset          `json:"total_credit"`
+	// Balanced is set when the debits equal the credits
+	Balanced bool `json:"balanced"`
+}
+
+// TrialBalance lists the balances of the leaf accounts of a ledger by
+// currency, the currencies are sorted by symbol and the lines follow the
+// order of the ledger tree
+type TrialBalance struct {
+	Ledger     eos.Checksum256        `json:"ledger"`
+	Currencies []TrialBalanceCurrency `json:"currencies"`
+}
+
+// GetTrialBalance reads the trial balance of ledger
+func (c *Client) GetTrialBalance(ctx context.Context, ledger eos.Checksum256) (*TrialBalance, error) {
+
+	tree, err := c.GetLedgerTree(ctx, ledger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTrialBalance(tree), nil
+}
+
+// NewTrialBalance builds the trial balance from the account_<SYM> balances
+// of the leaf accounts of tree
+func NewTrialBalance(tree *LedgerTree) *TrialBalance {
+
+	bySymbol := make(map[string]*TrialBalanceCurrency)
+
+	var walk func(nodes []LedgerNode, path []string)
+
+	walk = func(nodes []LedgerNode, path []string) {
+
+		for _, node := range nodes {
+
+			nodePath := append(append([]string(nil), path...), node.Account.Name)
+
+			if len(node.Children) > 0 {
+				walk(node.Children, nodePath)
+				continue
+			}
+
+			if node.Balances == nil {
+				continue
+			}
+
+			for symbol, balance := range node.Balances.Account {
+
+				currency, ok := bySymbol[symbol]
+
+				if !ok {
+					zero := eos.Asset{Symbol: balance.Symbol}
+					currency = &TrialBalanceCurrency{
+						Symbol:      symbol,
+						TotalDebit:  zero,
+						TotalCredit: zero,
+					}
+					bySymbol[symbol] = currency
+				}
+
+				line := TrialBalanceLine{
+					Code:   node.Account.Code,
+					Name:   node.Account.Name,
+					Path:   strings.Join(nodePath, chartPathSeparator),
+					Debit:  eos.Asset{Symbol: balance.Symbol},
+					Credit: eos.Asset{Symbol: balance.Symbol},
+				}
+
+				if balance.Amount >= 0 {
+					line.Debit = balance
+				} else {
+					line.Credit = negateAsset(balance)
+				}
+
+				currency.Lines = append(currency.Lines, line)
+				currency.TotalDebit = addAssetsAdjustingPrecision(currency.TotalDebit, line.Debit)
+				currency.TotalCredit = addAssetsAdjustingPrecision(currency.TotalCredit, line.Credit)
+			}
+		}
+	}
+
+	walk(tree.Accounts, nil)
+
+	trialBalance := &TrialBalance{Ledger: tree.Ledger}
+
+	for _, currency := range bySymbol {
+		difference := addAssetsAdjustingPrecision(currency.TotalDebit, negateAsset(currency.TotalCredit))
+		currency.Balanced = difference.Amount == 0
+		trialBalance.Currencies = append(trialBalance.Currencies, *currency)
+	}
+
+	sort.Slice(trialBalance.Currencies, func(i, j int) bool {
+		return trialBalance.Currencies[i].Symbol < trialBalance.Currencies[j].Symbol
+	})
+
+	return trialBalance
+}
+
+// Balanced returns true when every currency is balanced
+func (t *TrialBalance) Balanced() bool {
+	for _, currency := range t.Currencies {
+		if !currency.Balanced {
+			return false
+		}
+	}
+	return true
+}
+
+// Unbalanced returns the errors of the currencies whose debits and credits
+// differ
+func (t *TrialBalance) Unbalanced() []error {
+
+	var errs []error
+
+	for _, currency := range t.Currencies {
+		if !currency.Balanced {
+			errs = append(errs, &ErrUnbalanced{
+				Symbol: currency.Symbol,
+				Sum:    addAssetsAdjustingPrecision(currency.TotalDebit, negateAsset(currency.TotalCredit)),
+			})
+		}
+	}
+
+	return errs
+}
+
+// formatAmount leaves the zero amounts of the debit and credit columns empty
+func formatAmount(asset eos.Asset) string {
+	if asset.Amount == 0 {
+		return ""
+	}
+	return asset.String()
+}
+
+// WriteText writes a table per currency
+func (t *TrialBalance) WriteText(w io.Writer) error {
+
+	var out strings.Builder
+
+	for i, currency := range t.Currencies {
+
+		if i > 0 {
+			out.WriteString("\n")
+		}
+
+		table := simpletable.New()
+
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Code"},
+				{Align: simpletable.AlignCenter, Text: "Account"},
+				{Align: simpletable.AlignCenter, Text: "Debit " + currency.Symbol},
+				{Align: simpletable.AlignCenter, Text: "Credit " + currency.Symbol},
+			},
+		}
+
+		for _, line := range currency.Lines {
+			table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+				{Align: simpletable.AlignLeft, Text: line.Code},
+				{Align: simpletable.AlignLeft, Text: line.Path},
+				{Align: simpletable.AlignRight, Text: formatAmount(line.Debit)},
+				{Align: simpletable.AlignRight, Text: formatAmount(line.Credit)},
+			})
+		}
+
+		status := "Balanced"
+		if !currency.Balanced {
+			status = "UNBALANCED"
+		}
+
+		table.Footer = &simpletable.Footer{
+			Cells: []*simpletable.Cell{
+				{},
+				{Align: simpletable.AlignRight, Text: "Total (" + status + ")"},
+				{Align: simpletable.AlignRight, Text: currency.TotalDebit.String()},
+				{Align: simpletable.AlignRight, Text: currency.TotalCredit.String()},
+			},
+		}
+
+		out.WriteString(table.String())
+		out.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write trial balance: %v", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes a row per line with the currency symbol in the first
+// column, the totals of each currency follow its lines with an empty code
+func (t *TrialBalance) WriteCSV(w io.Writer) error {
+
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{{"currency", "code", "name", "path", "debit", "credit"}}
+
+	for _, currency := range t.Currencies {
+
+		for _, line := range currency.Lines {
+			rows = append(rows, []string{
+				currency.Symbol,
+				line.Code,
+				line.Name,
+				line.Path,
+				line.Debit.String(),
+				line.Credit.String(),
+			})
+		}
+
+		rows = append(rows, []string{
+			currency.Symbol,
+			"",
+			"Total",
+			"",
+			currency.TotalDebit.String(),
+			currency.TotalCredit.String(),
+		})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("write trial balance: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the trial balance as indented JSON
+func (t *TrialBalance) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(t); err != nil {
+		return fmt.Errorf("write trial balance: %v", err)
+	}
+
+	return nil
+}
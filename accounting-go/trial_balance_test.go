@@ -0,0 +1,103 @@
+package accounting_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func asset(t *testing.T, s string) eos.Asset {
+	a, err := eos.NewAssetFromString(s)
+	assert.NilError(t, err)
+	return a
+}
+
+func leafNode(code, name string, depth int, balances map[string]eos.Asset) accounting.LedgerNode {
+	return accounting.LedgerNode{
+		Account:  accounting.Account{Code: code, Name: name, IsLeaf: true},
+		Balances: &accounting.Balances{Account: balances, Global: balances},
+		Depth:    depth,
+	}
+}
+
+func TestTrialBalance(t *testing.T) {
+
+	tree := &accounting.LedgerTree{
+		Accounts: []accounting.LedgerNode{
+			{
+				Account: accounting.Account{Code: "4000", Name: "Income"},
+				Children: []accounting.LedgerNode{
+					leafNode("4100", "Sales", 1, map[string]eos.Asset{
+						"USD": asset(t, "-1000.00 USD"),
+						"BTC": asset(t, "-0.50000000 BTC"),
+					}),
+				},
+			},
+			{
+				Account: accounting.Account{Code: "5000", Name: "Expenses"},
+				Children: []accounting.LedgerNode{
+					leafNode("5100", "Development", 1, map[string]eos.Asset{
+						"USD": asset(t, "250.000 USD"),
+					}),
+					leafNode("5200", "Marketing", 1, map[string]eos.Asset{
+						"USD": asset(t, "750.00 USD"),
+						"BTC": asset(t, "0.40000000 BTC"),
+					}),
+				},
+			},
+		},
+	}
+
+	trialBalance := accounting.NewTrialBalance(tree)
+
+	assert.Equal(t, len(trialBalance.Currencies), 2)
+
+	btc := trialBalance.Currencies[0]
+	assert.Equal(t, btc.Symbol, "BTC")
+	assert.Assert(t, !btc.Balanced)
+
+	usd := trialBalance.Currencies[1]
+	assert.Equal(t, usd.Symbol, "USD")
+	assert.Assert(t, usd.Balanced)
+	assert.Equal(t, len(usd.Lines), 3)
+	assert.Equal(t, usd.Lines[0].Path, "Income / Sales")
+	assert.Equal(t, usd.Lines[0].Credit.String(), "1000.00 USD")
+	assert.Equal(t, usd.Lines[0].Debit.Amount, eos.Int64(0))
+	assert.Equal(t, usd.TotalDebit.String(), "1000.000 USD")
+	assert.Equal(t, usd.TotalCredit.String(), "1000.000 USD")
+
+	assert.Assert(t, !trialBalance.Balanced())
+	errs := trialBalance.Unbalanced()
+	assert.Equal(t, len(errs), 1)
+	var unbalanced *accounting.ErrUnbalanced
+	assert.Assert(t, errors.As(errs[0], &unbalanced))
+	assert.Equal(t, unbalanced.Sum.String(), "-0.10000000 BTC")
+
+	t.Run("Text", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NilError(t, trialBalance.WriteText(&out))
+		assert.Assert(t, strings.Contains(out.String(), "Expenses / Marketing"))
+		assert.Assert(t, strings.Contains(out.String(), "Total (UNBALANCED)"))
+		assert.Assert(t, strings.Contains(out.String(), "Total (Balanced)"))
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NilError(t, trialBalance.WriteCSV(&out))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, len(lines), 8)
+		assert.Equal(t, lines[0], "currency,code,name,path,debit,credit")
+		assert.Equal(t, lines[7], "USD,,Total,,1000.000 USD,1000.000 USD")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NilError(t, trialBalance.WriteJSON(&out))
+		assert.Assert(t, strings.Contains(out.String(), `"balanced": true`))
+	})
+}
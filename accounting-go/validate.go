@@ -0,0 +1,343 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	eos "github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/document-graph/docgraph"
+)
+
+// ViolationCode identifies the contract check a transaction fails
+type ViolationCode string
+
+const (
+	ViolationMissingField       ViolationCode = "missing_field"
+	ViolationNoComponents       ViolationCode = "no_components"
+	ViolationInvalidType        ViolationCode = "invalid_type"
+	ViolationNegativeAmount     ViolationCode = "negative_amount"
+	ViolationUnbalanced         ViolationCode = "unbalanced"
+	ViolationCurrencyNotAllowed ViolationCode = "currency_not_allowed"
+	ViolationUnknownAccount     ViolationCode = "unknown_account"
+	ViolationNotLeaf            ViolationCode = "not_leaf"
+	ViolationInvalidConversion  ViolationCode = "invalid_conversion"
+)
+
+// Violation is a check of the contract that a transaction would fail.
+// Component is the index of the offending component or -1 when the
+// violation is about the whole transaction.
+type Violation struct {
+	Code      ViolationCode `json:"code"`
+	Component int           `json:"component"`
+	Field     string        `json:"field,omitempty"`
+	Value     string        `json:"value,omitempty"`
+	Message   string        `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Component < 0 {
+		return fmt.Sprintf("%v: %v", v.Code, v.Message)
+	}
+	return fmt.Sprintf("%v: component %v: %v", v.Code, v.Component, v.Message)
+}
+
+// Violations is returned by the validation functions, it is an error so it
+// can be returned as is when the list is not empty
+type Violations []Violation
+
+func (v Violations) Error() string {
+	messages := make([]string, len(v))
+	for i, violation := range v {
+		messages[i] = violation.String()
+	}
+	return "invalid transaction: " + strings.Join(messages, "; ")
+}
+
+// Has tells if any of the violations has code
+func (v Violations) Has(code ViolationCode) bool {
+	for _, violation := range v {
+		if violation.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func missingField(label string) Violation {
+	return Violation{
+		Code:      ViolationMissingField,
+		Component: -1,
+		Field:     label,
+		Message:   fmt.Sprintf("missing %v in details group", label),
+	}
+}
+
+// ValidateContentGroups checks the trx_info of an upserttrx action has the
+// details the contract requires and then runs Validate over it
+func ValidateContentGroups(trxInfo []docgraph.ContentGroup, approve bool) Violations {
+
+	details, ok := findGroup(trxInfo, detailsGroup)
+
+	if !ok {
+		return Violations{{
+			Code:      ViolationMissingField,
+			Component: -1,
+			Field:     detailsGroup,
+			Message:   "missing 'details' group in transaction document",
+		}}
+	}
+
+	var violations Violations
+
+	for _, label := range []string{trxMemoLabel, trxNameLabel, trxDateLabel, trxLedgerLabel} {
+		if _, ok := findItem(details, label); !ok {
+			violations = append(violations, missingField(label))
+		}
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+
+	trx, err := TransactionFromContentGroups(trxInfo)
+
+	if err != nil {
+		return Violations{{
+			Code:      ViolationMissingField,
+			Component: -1,
+			Message:   err.Error(),
+		}}
+	}
+
+	return Validate(trx, approve)
+}
+
+// Validate runs the checks of the contract that don't depend on the chain
+// state. The balance check only applies to normal transactions being
+// approved, currency conversions are checked like crryconvtrx does instead.
+func Validate(trx Transaction, approve bool) Violations {
+
+	var violations Violations
+
+	if len(trx.Ledger) == 0 {
+		violations = append(violations, missingField(trxLedgerLabel))
+	}
+
+	if trx.Date == 0 {
+		violations = append(violations, missingField(trxDateLabel))
+	}
+
+	if len(trx.Components) == 0 {
+		violations = append(violations, Violation{
+			Code:      ViolationNoComponents,
+			Component: -1,
+			Message:   "Transaction must contain at least 1 component",
+		})
+	}
+
+	for i, component := range trx.Components {
+
+		if component.Type != Debit && component.Type != Credit {
+			violations = append(violations, Violation{
+				Code:      ViolationInvalidType,
+				Component: i,
+				Field:     componentTypeLabel,
+				Value:     component.Type,
+				Message:   fmt.Sprintf("Invalid component type:%v expected [%v or %v]", component.Type, Debit, Credit),
+			})
+		}
+
+		if component.Amount.Amount < 0 {
+			violations = append(violations, Violation{
+				Code:      ViolationNegativeAmount,
+				Component: i,
+				Field:     componentAmountLabel,
+				Value:     component.Amount.String(),
+				Message:   "Component amount must be a positive quantity.",
+			})
+		}
+	}
+
+	switch {
+	case trx.CurrencyConversion:
+		violations = append(violations, checkConversion(trx)...)
+	case approve:
+		violations = append(violations, checkBalanced(trx)...)
+	}
+
+	return violations
+}
+
+// checkBalanced mirrors Transaction::checkBalanced, DEBIT and CREDIT
+// components must add up to zero for every symbol code
+func checkBalanced(trx Transaction) Violations {
+
+	totals := make(assetSums)
+	var codes []string
+
+	for _, component := range trx.Components {
+		code := component.Amount.Symbol.Symbol
+		if _, ok := totals[code]; !ok {
+			codes = append(codes, code)
+		}
+		totals.add(signedAmount(component))
+	}
+
+	var violations Violations
+
+	for _, code := range codes {
+		if sum := totals[code]; sum.Amount != 0 {
+			violations = append(violations, Violation{
+				Code:      ViolationUnbalanced,
+				Component: -1,
+				Field:     code,
+				Value:     sum.String(),
+				Message:   fmt.Sprintf("Transaction is unbalanced. Asset %v sums up to %v", code, sum.String()),
+			})
+		}
+	}
+
+	return violations
+}
+
+// checkConversion mirrors the checks of crryconvtrx, a conversion has 2
+// components in different currencies
+func checkConversion(trx Transaction) Violations {
+
+	if len(trx.Components) != 2 {
+		// no components is already reported
+		if len(trx.Components) == 0 {
+			return nil
+		}
+		return Violations{{
+			Code:      ViolationInvalidConversion,
+			Component: -1,
+			Value:     fmt.Sprint(len(trx.Components)),
+			Message:   "a currency conversion must have 2 components",
+		}}
+	}
+
+	from := trx.Components[0].Amount.Symbol.Symbol
+
+	if from == trx.Components[1].Amount.Symbol.Symbol {
+		return Violations{{
+			Code:      ViolationInvalidConversion,
+			Component: 1,
+			Field:     componentAmountLabel,
+			Value:     from,
+			Message:   fmt.Sprintf("a currency conversion must use 2 different currencies, provided only %v", from),
+		}}
+	}
+
+	return nil
+}
+
+// Validate runs ValidateOnChain against the contract of the client
+func (c *Client) Validate(ctx context.Context, trx Transaction, approve bool) (Violations, error) {
+	return ValidateOnChain(ctx, c, trx, approve)
+}
+
+// ValidateOnChain runs Validate and the checks that depend on the chain
+// state: the currencies must be allowed and the accounts must exist and be
+// leafs
+func ValidateOnChain(ctx context.Context, chain Chain, trx Transaction, approve bool) (Violations, error) {
+
+	violations := Validate(trx, approve)
+
+	if len(trx.Components) == 0 {
+		return violations, nil
+	}
+
+	settings, err := chain.GetSettings(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("validate: get settings: %v", err)
+	}
+
+	leafs := make(map[string]*bool)
+
+	for i, component := range trx.Components {
+
+		code := component.Amount.Symbol.Symbol
+
+		if !settings.IsAllowedCurrency(code) {
+			violations = append(violations, Violation{
+				Code:      ViolationCurrencyNotAllowed,
+				Component: i,
+				Field:     componentAmountLabel,
+				Value:     code,
+				Message:   fmt.Sprintf("Currency %v is not allowed.", code),
+			})
+		}
+
+		if len(component.Account) == 0 {
+			continue
+		}
+
+		hash := component.Account.String()
+
+		isLeaf, checked := leafs[hash]
+
+		if !checked {
+			isLeaf, err = isLeafAccount(ctx, chain, component.Account)
+			if err != nil {
+				return nil, fmt.Errorf("validate: account %v: %v", hash, err)
+			}
+			leafs[hash] = isLeaf
+		}
+
+		switch {
+		case isLeaf == nil:
+			violations = append(violations, Violation{
+				Code:      ViolationUnknownAccount,
+				Component: i,
+				Field:     componentAccountLabel,
+				Value:     hash,
+				Message:   fmt.Sprintf("Account %v doesn't exist.", hash),
+			})
+		case !*isLeaf:
+			violations = append(violations, Violation{
+				Code:      ViolationNotLeaf,
+				Component: i,
+				Field:     componentAccountLabel,
+				Value:     hash,
+				Message:   fmt.Sprintf("Only leafs are allowed to have associated components. Account %v is not a leaf.", hash),
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// isLeafAccount returns nil when account doesn't have a variable document,
+// which is also the case of the documents that don't exist
+func isLeafAccount(ctx context.Context, g Graph, account eos.Checksum256) (*bool, error) {
+
+	edges, err := g.EdgesFrom(ctx, account, accountVariableEdge)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(edges) == 0 {
+		return nil, nil
+	}
+
+	variable, err := g.Document(ctx, edges[0].ToNode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	details, ok := findGroup(variable.ContentGroups, detailsGroup)
+
+	if !ok {
+		return nil, fmt.Errorf("missing details group in account variable %v", variable.Hash)
+	}
+
+	r := contentReader{group: details}
+	isLeaf := r.text(isLeafLabel, true) == "true"
+
+	return &isLeaf, r.err
+}
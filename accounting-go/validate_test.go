@@ -0,0 +1,216 @@
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eoscanada/eos-go"
+	"github.com/hypha-dao/accounting-go"
+	"gotest.tools/assert"
+)
+
+func TestValidate(t *testing.T) {
+
+	usd2, _ := eos.StringToSymbol("2,USD")
+	usd4, _ := eos.StringToSymbol("4,USD")
+	btc, _ := eos.StringToSymbol("8,BTC")
+
+	newTrx := func(components ...accounting.Component) accounting.Transaction {
+		return accounting.Transaction{
+			Ledger:     testHash(1),
+			Date:       accounting.TimePointOf(time.Date(2020, 12, 17, 21, 45, 11, 0, time.UTC)),
+			Memo:       "Test transaction",
+			Name:       "transaction name",
+			Components: components,
+		}
+	}
+
+	t.Run("Balanced with different precisions", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: 1050, Symbol: usd2}, Type: accounting.Debit},
+			accounting.Component{Account: testHash(3), Amount: eos.Asset{Amount: 105000, Symbol: usd4}, Type: accounting.Credit},
+		)
+
+		assert.Equal(t, len(accounting.Validate(trx, true)), 0)
+	})
+
+	t.Run("Unbalanced per symbol code", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: 1000, Symbol: usd2}, Type: accounting.Debit},
+			accounting.Component{Account: testHash(3), Amount: eos.Asset{Amount: 1000, Symbol: usd2}, Type: accounting.Credit},
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: 100, Symbol: btc}, Type: accounting.Debit},
+			accounting.Component{Account: testHash(3), Amount: eos.Asset{Amount: 50, Symbol: btc}, Type: accounting.Credit},
+		)
+
+		violations := accounting.Validate(trx, true)
+
+		assert.Equal(t, len(violations), 1)
+		assert.Equal(t, violations[0].Code, accounting.ViolationUnbalanced)
+		assert.Equal(t, violations[0].Component, -1)
+		assert.Equal(t, violations[0].Message, "Transaction is unbalanced. Asset BTC sums up to 0.00000050 BTC")
+
+		assert.Equal(t, len(accounting.Validate(trx, false)), 0)
+	})
+
+	t.Run("Currency conversions are not balanced", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: 1000, Symbol: usd2}, Type: accounting.Credit},
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: 50, Symbol: btc}, Type: accounting.Debit},
+		)
+		trx.CurrencyConversion = true
+
+		assert.Equal(t, len(accounting.Validate(trx, true)), 0)
+
+		decoded, err := accounting.TransactionFromContentGroups(trx.ContentGroups())
+		assert.NilError(t, err)
+		assert.Assert(t, decoded.CurrencyConversion)
+
+		trx.Components[1].Amount = eos.Asset{Amount: 1000, Symbol: usd4}
+
+		violations := accounting.Validate(trx, true)
+
+		assert.Equal(t, len(violations), 1)
+		assert.Equal(t, violations[0].Code, accounting.ViolationInvalidConversion)
+		assert.Equal(t, violations[0].Message, "a currency conversion must use 2 different currencies, provided only USD")
+
+		trx.Components = trx.Components[:1]
+
+		violations = accounting.Validate(trx, false)
+
+		assert.Equal(t, len(violations), 1)
+		assert.Equal(t, violations[0].Code, accounting.ViolationInvalidConversion)
+	})
+
+	t.Run("Reports component violations", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: -1000, Symbol: usd2}, Type: accounting.Debit},
+			accounting.Component{Account: testHash(3), Amount: eos.Asset{Amount: 1000, Symbol: usd2}, Type: "TRANSFER"},
+		)
+
+		violations := accounting.Validate(trx, false)
+
+		assert.Equal(t, len(violations), 2)
+		assert.Equal(t, violations[0].Code, accounting.ViolationNegativeAmount)
+		assert.Equal(t, violations[0].Component, 0)
+		assert.Equal(t, violations[1].Code, accounting.ViolationInvalidType)
+		assert.Equal(t, violations[1].Component, 1)
+	})
+
+	t.Run("Requires components and details", func(t *testing.T) {
+
+		violations := accounting.Validate(accounting.Transaction{}, true)
+
+		assert.Assert(t, violations.Has(accounting.ViolationNoComponents))
+		assert.Assert(t, violations.Has(accounting.ViolationMissingField))
+		assert.ErrorContains(t, violations, "trx_ledger")
+	})
+
+	t.Run("Requires memo in content groups", func(t *testing.T) {
+
+		trxInfo := newTrx(
+			accounting.Component{Account: testHash(2), Amount: eos.Asset{Amount: 1000, Symbol: usd2}, Type: accounting.Debit},
+			accounting.Component{Account: testHash(3), Amount: eos.Asset{Amount: 1000, Symbol: usd2}, Type: accounting.Credit},
+		).ContentGroups()
+
+		assert.Equal(t, len(accounting.ValidateContentGroups(trxInfo, true)), 0)
+
+		details := trxInfo[0][:0]
+		for _, item := range trxInfo[0] {
+			if item.Label != "trx_memo" {
+				details = append(details, item)
+			}
+		}
+		trxInfo[0] = details
+
+		violations := accounting.ValidateContentGroups(trxInfo, true)
+
+		assert.Equal(t, len(violations), 1)
+		assert.Equal(t, violations[0].Field, "trx_memo")
+	})
+}
+
+func TestValidateOnChain(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := newFakeLedger(t)
+
+	usd, _ := eos.StringToSymbol("2,USD")
+	eur, _ := eos.StringToSymbol("2,EUR")
+
+	newTrx := func(components ...accounting.Component) accounting.Transaction {
+		return accounting.Transaction{
+			Ledger:     l.ledger,
+			Date:       accounting.TimePointOf(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)),
+			Memo:       "Sale",
+			Name:       "Sale",
+			Components: components,
+		}
+	}
+
+	t.Run("Valid transaction", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: l.cash, Amount: eos.Asset{Amount: 1000, Symbol: usd}, Type: accounting.Debit},
+			accounting.Component{Account: l.sales, Amount: eos.Asset{Amount: 1000, Symbol: usd}, Type: accounting.Credit},
+		)
+
+		violations, err := accounting.ValidateOnChain(ctx, l.chain, trx, true)
+		assert.NilError(t, err)
+		assert.Equal(t, len(violations), 0)
+	})
+
+	t.Run("Chain violations", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: l.cash, Amount: eos.Asset{Amount: 1000, Symbol: eur}, Type: accounting.Debit},
+			accounting.Component{Account: testHash(9), Amount: eos.Asset{Amount: 1000, Symbol: eur}, Type: accounting.Credit},
+			accounting.Component{Account: l.revenue, Amount: eos.Asset{Amount: 500, Symbol: usd}, Type: accounting.Debit},
+			accounting.Component{Account: l.sales, Amount: eos.Asset{Amount: 500, Symbol: usd}, Type: accounting.Credit},
+		)
+
+		violations, err := accounting.ValidateOnChain(ctx, l.chain, trx, true)
+		assert.NilError(t, err)
+
+		var codes []accounting.ViolationCode
+		var components []int
+		for _, violation := range violations {
+			codes = append(codes, violation.Code)
+			components = append(components, violation.Component)
+		}
+
+		assert.DeepEqual(t, codes, []accounting.ViolationCode{
+			accounting.ViolationCurrencyNotAllowed,
+			accounting.ViolationCurrencyNotAllowed,
+			accounting.ViolationUnknownAccount,
+			accounting.ViolationNotLeaf,
+		})
+		assert.DeepEqual(t, components, []int{0, 1, 1, 2})
+		assert.Equal(t, violations[2].Value, testHash(9).String())
+		assert.Equal(t, violations[3].Value, l.revenue.String())
+		assert.Equal(t, violations[0].Message, "Currency EUR is not allowed.")
+	})
+
+	t.Run("Agrees with the contract", func(t *testing.T) {
+
+		trx := newTrx(
+			accounting.Component{Account: l.revenue, Amount: eos.Asset{Amount: 500, Symbol: usd}, Type: accounting.Debit},
+			accounting.Component{Account: l.sales, Amount: eos.Asset{Amount: 500, Symbol: usd}, Type: accounting.Credit},
+		)
+
+		violations, err := accounting.ValidateOnChain(ctx, l.chain, trx, true)
+		assert.NilError(t, err)
+		assert.Equal(t, len(violations), 1)
+
+		_, err = l.chain.Upserttrx(ctx, nil, trx.ContentGroups(), true)
+
+		var notLeaf *accounting.ErrNotLeaf
+		assert.Assert(t, errors.As(err, &notLeaf), "%v", err)
+	})
+}
@@ -76,7 +76,7 @@ accounting::createacc(const name & creator, ContentGroups & account_info)
   EOS_CHECK(details, "Details group was expected but not found in account info");
   
   checksum256 parentHash = contentWrap.getOrFail(dIdx, PARENT_ACCOUNT).second->getAs<checksum256>();
-  int64_t accountType = contentWrap.getOrFail(dIdx, ACCOUNT_TYPE).second->getAs<int64_t>(); // why do we need this?
+  int64_t accountType = contentWrap.getOrFail(dIdx, ACCOUNT_TYPE).second->getAs<int64_t>();
   std::string accountTagType = contentWrap.getOrFail(dIdx, ACCOUNT_TAG_TYPE).second->getAs<std::string>();
   std::string accountCode = contentWrap.getOrFail(dIdx, ACCOUNT_CODE).second->getAs<std::string>();
   std::string accountName = contentWrap.getOrFail(dIdx, ACCOUNT_NAME).second->getAs<std::string>();
@@ -87,6 +87,11 @@ accounting::createacc(const name & creator, ContentGroups & account_info)
     "Account name can not be empty."
   )
 
+  EOS_CHECK(
+    accountType >= ACCOUNT_GROUP::kAsset && accountType <= ACCOUNT_GROUP::kLoss,
+    util::to_str("Invalid account type: ", accountType)
+  )
+
   TableWrapper<document_table> docs(get_self(), get_self().value);
 
   EOS_CHECK(
@@ -126,7 +131,8 @@ accounting::createacc(const name & creator, ContentGroups & account_info)
     ContentGroup {
       Content{ CONTENT_GROUP_LABEL, DETAILS },
       Content{ ACCOUNT_TAG_TYPE, accountTagType },
-      Content{ ACCOUNT_CODE, accountCode }
+      Content{ ACCOUNT_CODE, accountCode },
+      Content{ ACCOUNT_TYPE, accountType }
     },
     getSystemGroup(accountName.c_str(), "account"),
   });